@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"playground/models"
+	"playground/services"
+)
+
+// bulkFormats maps the content types a bulk import accepts to their format
+var bulkFormats = map[string]services.BulkFormat{
+	"application/x-ndjson": services.BulkNDJSON,
+	"application/ndjson":   services.BulkNDJSON,
+	"application/jsonl":    services.BulkNDJSON,
+	"application/json":     services.BulkNDJSON,
+	"text/csv":             services.BulkCSV,
+}
+
+// BulkHandler handles HTTP requests for bulk export and import
+type BulkHandler struct {
+	bulkService *services.BulkService
+}
+
+// NewBulkHandler creates a new bulk handler with the given service
+func NewBulkHandler(bulkService *services.BulkService) *BulkHandler {
+	return &BulkHandler{
+		bulkService: bulkService,
+	}
+}
+
+// flushWriter sends every write to the client as it is made
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if f.flusher != nil {
+		f.flusher.Flush()
+	}
+	return n, err
+}
+
+// ExportRecipes streams the recipes listed for the caller as NDJSON with their ratings, optionally
+// only those with the tag parameter or by the author parameter
+func (h *BulkHandler) ExportRecipes(w http.ResponseWriter, r *http.Request) {
+	caller, _ := callerFromRequest(r)
+	filter := models.ExportFilter{
+		Tag:      r.URL.Query().Get("tag"),
+		AuthorID: r.URL.Query().Get("author"),
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="recipes.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	if err := h.bulkService.ExportRecipes(flushWriter{w, flusher}, caller, filter); err != nil {
+		log.Printf("Cannot send recipe export: %v", err)
+	}
+}
+
+// ImportRecipes creates recipes for the authenticated user from an NDJSON or CSV request body, chosen
+// by the format parameter or the Content-Type, and reports the result of every record; with
+// dryRun=true the records are only validated. An import that stops part way still reports the
+// records read before it stopped, along with the error.
+func (h *BulkHandler) ImportRecipes(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dryRun"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid dryRun parameter")
+			return
+		}
+		dryRun = parsed
+	}
+
+	format := services.BulkFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = services.BulkNDJSON
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if format, ok = bulkFormats[mediaType]; err != nil || !ok {
+				respondWithError(w, http.StatusUnsupportedMediaType, "Unsupported Content-Type: use application/x-ndjson or text/csv")
+				return
+			}
+		}
+	}
+
+	// Uploads declaring their size are turned away before any record is imported
+	if r.ContentLength > services.MaxBulkImportBytes {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "Import is too large: at most 100 MB")
+		return
+	}
+	body := http.MaxBytesReader(w, r.Body, services.MaxBulkImportBytes)
+	defer body.Close()
+
+	report, err := h.bulkService.ImportRecipes(body, format, caller, dryRun)
+	if err != nil {
+		respondWithBulkError(w, report, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// Helper function to map bulk service errors to HTTP responses, keeping the partial report
+func respondWithBulkError(w http.ResponseWriter, report models.BulkImportReport, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		report.Error = fmt.Sprintf("Import is too large: at most 100 MB; %v", err)
+		respondWithJSON(w, http.StatusRequestEntityTooLarge, report)
+	default:
+		report.Error = err.Error()
+		respondWithJSON(w, http.StatusBadRequest, report)
+	}
+}
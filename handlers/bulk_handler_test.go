@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"playground/middleware"
+	"playground/models"
+	"playground/repositories"
+	"playground/services"
+)
+
+// endlessLine reads as one NDJSON line that never ends
+type endlessLine struct{}
+
+func (endlessLine) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+// TestImportRecipesTooLarge tests that imports over the size limit are rejected with 413
+func TestImportRecipesTooLarge(t *testing.T) {
+	// Create the services with the repositories
+	recipeService := services.NewRecipeService(repositories.NewInMemoryRecipeRepository())
+	ratingService := services.NewRatingService(repositories.NewInMemoryRatingRepository(), recipeService)
+	handler := NewBulkHandler(services.NewBulkService(recipeService, ratingService))
+
+	importRequest := func(body io.Reader) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/api/import", body)
+		request.Header.Set("Content-Type", "application/x-ndjson")
+		return request.WithContext(context.WithValue(request.Context(), middleware.UserIDKey, "importer-1"))
+	}
+
+	// A body that declares its size is turned away before it is read
+	request := importRequest(strings.NewReader(`{"title": "Toast"}`))
+	request.ContentLength = services.MaxBulkImportBytes + 1
+	response := httptest.NewRecorder()
+	handler.ImportRecipes(response, request)
+	if response.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a declared size over the limit, but got %d %s", response.Code, response.Body.String())
+	}
+
+	// A streamed body is cut off at the limit
+	body := io.MultiReader(strings.NewReader(`{"title": "Toast"}`+"\n"), io.LimitReader(endlessLine{}, services.MaxBulkImportBytes+1))
+	response = httptest.NewRecorder()
+	handler.ImportRecipes(response, importRequest(body))
+	if response.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a streamed body over the limit, but got %d %s", response.Code, response.Body.String())
+	}
+
+	// The records read before the limit are still reported
+	var report models.BulkImportReport
+	if err := json.NewDecoder(response.Body).Decode(&report); err != nil {
+		t.Fatalf("Expected a report, but got: %v", err)
+	}
+	if report.Imported != 1 || len(report.Recipes) != 1 || report.Recipes[0].ID == "" {
+		t.Errorf("Expected the first recipe to be reported as imported, but got %+v", report)
+	}
+	if !strings.Contains(report.Error, "stopped at line 2") {
+		t.Errorf("Expected the error to say where the import stopped, but got %q", report.Error)
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"playground/models"
+	"playground/services"
+)
+
+// CollectionHandler handles HTTP requests for recipe collections
+type CollectionHandler struct {
+	collectionService *services.CollectionService
+}
+
+// NewCollectionHandler creates a new collection handler with the given service
+func NewCollectionHandler(collectionService *services.CollectionService) *CollectionHandler {
+	return &CollectionHandler{
+		collectionService: collectionService,
+	}
+}
+
+// GetCollections returns the authenticated user's collections
+func (h *CollectionHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	collections := h.collectionService.GetCollections(caller)
+	respondWithJSON(w, http.StatusOK, collections)
+}
+
+// GetCollectionsByOwner returns the collections of a user that the caller may see
+func (h *CollectionHandler) GetCollectionsByOwner(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ownerID := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	collections := h.collectionService.GetCollectionsByOwner(ownerID, caller)
+	respondWithJSON(w, http.StatusOK, collections)
+}
+
+// GetCollection returns a collection with its recipes
+func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	collection, err := h.collectionService.GetCollection(id, caller)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, collection)
+}
+
+// CreateCollection creates a new collection from JSON request body
+func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.CollectionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	collection, err := h.collectionService.CreateCollection(caller, input)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, collection)
+}
+
+// UpdateCollection updates a collection from JSON request body
+func (h *CollectionHandler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.CollectionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	collection, err := h.collectionService.UpdateCollection(id, caller, input)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, collection)
+}
+
+// DeleteCollection removes a collection
+func (h *CollectionHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.collectionService.DeleteCollection(id, caller); err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// AddRecipe adds the recipe in the JSON request body to a collection
+func (h *CollectionHandler) AddRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.CollectionRecipeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	collection, err := h.collectionService.AddRecipe(id, caller, input)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, collection)
+}
+
+// RemoveRecipe takes a recipe out of a collection
+func (h *CollectionHandler) RemoveRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	collection, err := h.collectionService.RemoveRecipe(vars["id"], caller, vars["recipeId"])
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, collection)
+}
+
+// ReorderRecipes puts the recipes of a collection in the order given in the JSON request body
+func (h *CollectionHandler) ReorderRecipes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.CollectionOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	collection, err := h.collectionService.ReorderRecipes(id, caller, input.RecipeIDs)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, collection)
+}
+
+// Helper function to map collection service errors to HTTP responses
+func respondWithCollectionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrCollectionNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrNotCollectionOwner):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidCollection):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrRecipeAlreadyInCollection):
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		respondWithRecipeError(w, err)
+	}
+}
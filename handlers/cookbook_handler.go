@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"playground/models"
+	"playground/services"
+)
+
+// CookbookHandler handles HTTP requests for cookbook e-books
+type CookbookHandler struct {
+	cookbookService *services.CookbookService
+}
+
+// NewCookbookHandler creates a new cookbook handler with the given service
+func NewCookbookHandler(cookbookService *services.CookbookService) *CookbookHandler {
+	return &CookbookHandler{
+		cookbookService: cookbookService,
+	}
+}
+
+// ExportEPUB sends the recipes chosen by exactly one of the ids (comma separated), tag or collection
+// parameters as an EPUB cookbook, optionally named by the title parameter
+func (h *CookbookHandler) ExportEPUB(w http.ResponseWriter, r *http.Request) {
+	caller, _ := callerFromRequest(r)
+	query := r.URL.Query()
+
+	selection := models.CookbookSelection{
+		Tag:          query.Get("tag"),
+		CollectionID: query.Get("collection"),
+		Title:        query.Get("title"),
+	}
+	for _, id := range strings.Split(query.Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			selection.IDs = append(selection.IDs, id)
+		}
+	}
+
+	cookbook, err := h.cookbookService.GetCookbook(caller, selection)
+	if err != nil {
+		respondWithCookbookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/epub+zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileSlug(cookbook.Title)+`.epub"`)
+	w.WriteHeader(http.StatusOK)
+	if err := h.cookbookService.WriteCookbookEPUB(w, caller, cookbook); err != nil {
+		log.Printf("Cannot send cookbook %q: %v", cookbook.Title, err)
+	}
+}
+
+// fileSlug turns a title into a lowercase ASCII file name such as "sunday-dinners"
+func fileSlug(title string) string {
+	slug := strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, title)
+	slug = strings.Join(strings.FieldsFunc(slug, func(r rune) bool { return r == '-' }), "-")
+	if slug == "" {
+		return "cookbook"
+	}
+	return slug
+}
+
+// Helper function to map cookbook service errors to HTTP responses
+func respondWithCookbookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCookbook):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithCollectionError(w, err)
+	}
+}
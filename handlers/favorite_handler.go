@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"playground/models"
+	"playground/services"
+)
+
+// FavoriteHandler handles HTTP requests for favorite recipes and private recipe notes
+type FavoriteHandler struct {
+	favoriteService *services.FavoriteService
+	noteService     *services.NoteService
+}
+
+// NewFavoriteHandler creates a new favorite handler with the given services
+func NewFavoriteHandler(favoriteService *services.FavoriteService, noteService *services.NoteService) *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteService: favoriteService,
+		noteService:     noteService,
+	}
+}
+
+// AddFavorite adds a recipe to the authenticated user's favorites
+func (h *FavoriteHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	favorite, err := h.favoriteService.AddFavorite(id, caller)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, favorite)
+}
+
+// RemoveFavorite takes a recipe out of the authenticated user's favorites
+func (h *FavoriteHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.favoriteService.RemoveFavorite(id, caller); err != nil {
+		respondWithError(w, http.StatusNotFound, "Favorite not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// GetFavorites returns the authenticated user's favorite recipes
+func (h *FavoriteHandler) GetFavorites(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	recipes := h.favoriteService.GetFavorites(caller)
+	respondWithJSON(w, http.StatusOK, recipes)
+}
+
+// GetNote returns the authenticated user's note on a recipe
+func (h *FavoriteHandler) GetNote(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	note, err := h.noteService.GetNote(id, caller)
+	if err != nil {
+		respondWithNoteError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, note)
+}
+
+// SaveNote creates or replaces the authenticated user's note on a recipe from JSON request body
+func (h *FavoriteHandler) SaveNote(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.RecipeNoteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	note, err := h.noteService.SaveNote(id, caller, input)
+	if err != nil {
+		respondWithNoteError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, note)
+}
+
+// DeleteNote removes the authenticated user's note on a recipe
+func (h *FavoriteHandler) DeleteNote(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.noteService.DeleteNote(id, caller); err != nil {
+		respondWithNoteError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// Helper function to map note service errors to HTTP responses
+func respondWithNoteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidNote):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrNoteNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	default:
+		respondWithRecipeError(w, err)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"playground/services"
+)
+
+// ForkHandler handles HTTP requests for recipe forks and their lineage
+type ForkHandler struct {
+	recipeService *services.RecipeService
+}
+
+// NewForkHandler creates a new fork handler with the given service
+func NewForkHandler(recipeService *services.RecipeService) *ForkHandler {
+	return &ForkHandler{
+		recipeService: recipeService,
+	}
+}
+
+// ForkRecipe copies a recipe into a new draft owned by the authenticated user
+func (h *ForkHandler) ForkRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	fork, err := h.recipeService.ForkRecipe(id, caller)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, fork)
+}
+
+// GetForks returns the direct forks of a recipe
+func (h *ForkHandler) GetForks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	forks, err := h.recipeService.GetForks(id, caller)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, forks)
+}
+
+// GetLineage returns the ancestors of a recipe, nearest first
+func (h *ForkHandler) GetLineage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	lineage, err := h.recipeService.GetLineage(id, caller)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, lineage)
+}
+
+// DiffUpstream returns the changes made to a fork's parent since it was forked
+func (h *ForkHandler) DiffUpstream(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	diff, err := h.recipeService.DiffUpstream(id, caller)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotAFork):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrUpstreamUnavailable):
+			respondWithError(w, http.StatusNotFound, err.Error())
+		default:
+			respondWithRecipeError(w, err)
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, diff)
+}
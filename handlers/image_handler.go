@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"playground/models"
+	"playground/services"
+)
+
+// multipartOverhead is the room left for the form fields around the image in an upload
+const multipartOverhead = 1 << 20
+
+// ImageHandler handles HTTP requests for recipe images
+type ImageHandler struct {
+	imageService *services.ImageService
+}
+
+// NewImageHandler creates a new image handler with the given service
+func NewImageHandler(imageService *services.ImageService) *ImageHandler {
+	return &ImageHandler{
+		imageService: imageService,
+	}
+}
+
+// UploadImage stores the image in the "image" field of a multipart form, with an optional "caption"
+func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxImageBytes+multipartOverhead)
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, services.ErrImageTooLarge.Error())
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Missing image file")
+		return
+	}
+	defer file.Close()
+
+	image, err := h.imageService.UploadImage(id, caller, file, r.FormValue("caption"))
+	if err != nil {
+		respondWithImageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, image)
+}
+
+// GetImage streams one size of a recipe image
+func (h *ImageHandler) GetImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	caller, _ := callerFromRequest(r)
+
+	reader, image, err := h.imageService.OpenImage(vars["id"], vars["imageId"], models.ImageSize(vars["size"]), caller)
+	if err != nil {
+		respondWithImageError(w, err)
+		return
+	}
+	defer reader.Close()
+
+	// Stored files are never changed in place, so they can be cached for as long as the image exists
+	w.Header().Set("Content-Type", image.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, reader); err != nil {
+		log.Printf("Cannot send image %s: %v", image.ID, err)
+	}
+}
+
+// ReorderImages puts the images of a recipe in the order given in the JSON request body
+func (h *ImageHandler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.ImageOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	recipe, err := h.imageService.ReorderImages(id, caller, input.ImageIDs)
+	if err != nil {
+		respondWithImageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, recipe)
+}
+
+// SetCoverImage makes an image the cover of its recipe
+func (h *ImageHandler) SetCoverImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	recipe, err := h.imageService.SetCoverImage(vars["id"], vars["imageId"], caller)
+	if err != nil {
+		respondWithImageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, recipe)
+}
+
+// DeleteImage removes an image and its files from its recipe
+func (h *ImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	recipe, err := h.imageService.DeleteImage(vars["id"], vars["imageId"], caller)
+	if err != nil {
+		respondWithImageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, recipe)
+}
+
+// Helper function to map image service errors to HTTP responses
+func respondWithImageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrImageTooLarge):
+		respondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, services.ErrUnsupportedImage):
+		respondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, services.ErrInvalidImageOrder):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrImageNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	default:
+		respondWithRecipeError(w, err)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"playground/services"
+)
+
+// ImportHandler handles HTTP requests for importing recipes
+type ImportHandler struct {
+	importService *services.ImportService
+}
+
+// NewImportHandler creates a new import handler with the given service
+func NewImportHandler(importService *services.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// importURLRequest asks for the page at URL to be fetched and imported
+type importURLRequest struct {
+	URL     string          `json:"url"`
+	Type    json.RawMessage `json:"@type"`
+	Context json.RawMessage `json:"@context"`
+	Graph   json.RawMessage `json:"@graph"`
+}
+
+// ImportRecipe creates a draft recipe from the request body: a schema.org JSON-LD document, an HTML
+// page with JSON-LD scripts, or {"url": "..."} naming a page to fetch
+func (h *ImportHandler) ImportRecipe(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, services.MaxImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, services.ErrImportTooLarge.Error())
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	// A JSON-LD node always carries @type, @context or @graph; a bare url asks for a fetch
+	var request importURLRequest
+	if json.Unmarshal(body, &request) == nil && request.URL != "" &&
+		request.Type == nil && request.Context == nil && request.Graph == nil {
+		recipe, err := h.importService.ImportURL(caller, request.URL)
+		if err != nil {
+			respondWithImportError(w, err)
+			return
+		}
+		respondWithJSON(w, http.StatusCreated, recipe)
+		return
+	}
+
+	recipe, err := h.importService.ImportDocument(caller, body, "")
+	if err != nil {
+		respondWithImportError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, recipe)
+}
+
+// Helper function to map import service errors to HTTP responses
+func respondWithImportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrImportTooLarge):
+		respondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, services.ErrNoRecipeFound), errors.Is(err, services.ErrInvalidImport),
+		errors.Is(err, services.ErrInvalidImportURL):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrFetchFailed):
+		// Keep what was dialed and why it failed out of the response
+		log.Printf("Cannot fetch page to import: %v", err)
+		respondWithError(w, http.StatusBadGateway, services.ErrFetchFailed.Error())
+	default:
+		respondWithRecipeError(w, err)
+	}
+}
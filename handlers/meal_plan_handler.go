@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"playground/models"
+	"playground/services"
+)
+
+// MealPlanHandler handles HTTP requests for meal plans
+type MealPlanHandler struct {
+	mealPlanService *services.MealPlanService
+}
+
+// NewMealPlanHandler creates a new meal plan handler with the given service
+func NewMealPlanHandler(mealPlanService *services.MealPlanService) *MealPlanHandler {
+	return &MealPlanHandler{
+		mealPlanService: mealPlanService,
+	}
+}
+
+// GetMealPlans returns the authenticated user's meal plans
+func (h *MealPlanHandler) GetMealPlans(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	plans := h.mealPlanService.GetMealPlans(caller)
+	respondWithJSON(w, http.StatusOK, plans)
+}
+
+// GetMealPlan returns a meal plan, optionally limited to the from and to query parameters
+func (h *MealPlanHandler) GetMealPlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := h.mealPlanService.GetMealPlan(id, caller, from, to)
+	if err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, plan)
+}
+
+// CreateMealPlan creates a new meal plan from JSON request body
+func (h *MealPlanHandler) CreateMealPlan(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.MealPlanInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	plan, err := h.mealPlanService.CreateMealPlan(caller, input)
+	if err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, plan)
+}
+
+// UpdateMealPlan renames a meal plan from JSON request body
+func (h *MealPlanHandler) UpdateMealPlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.MealPlanInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	plan, err := h.mealPlanService.UpdateMealPlan(id, caller, input)
+	if err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, plan)
+}
+
+// DeleteMealPlan removes a meal plan
+func (h *MealPlanHandler) DeleteMealPlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.mealPlanService.DeleteMealPlan(id, caller); err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// AddEntry plans a recipe for a meal from JSON request body
+func (h *MealPlanHandler) AddEntry(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.MealPlanEntryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	entry, err := h.mealPlanService.AddEntry(id, caller, input)
+	if err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, entry)
+}
+
+// UpdateEntry changes a planned meal from JSON request body
+func (h *MealPlanHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.MealPlanEntryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	entry, err := h.mealPlanService.UpdateEntry(vars["id"], vars["entryId"], caller, input)
+	if err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entry)
+}
+
+// DeleteEntry removes a planned meal
+func (h *MealPlanHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.mealPlanService.DeleteEntry(vars["id"], vars["entryId"], caller); err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// CopyWeek copies a week of meals to another week from JSON request body
+func (h *MealPlanHandler) CopyWeek(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.CopyWeekInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	plan, err := h.mealPlanService.CopyWeek(id, caller, input)
+	if err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, plan)
+}
+
+// RepeatWeek repeats a week of meals in the following weeks from JSON request body
+func (h *MealPlanHandler) RepeatWeek(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.RepeatWeekInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	plan, err := h.mealPlanService.RepeatWeek(id, caller, input)
+	if err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, plan)
+}
+
+// GetSummary returns the prep and cook time per day of a meal plan, optionally limited to
+// the from and to query parameters
+func (h *MealPlanHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := h.mealPlanService.GetSummary(id, caller, from, to)
+	if err != nil {
+		respondWithMealPlanError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// Helper function to read the optional from and to query parameters; it responds with
+// an error and returns false when either is not a YYYY-MM-DD date
+func dateRange(w http.ResponseWriter, r *http.Request) (models.Date, models.Date, bool) {
+	var dates [2]models.Date
+	for i, name := range []string{"from", "to"} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+
+		date, err := models.ParseDate(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid "+name+" parameter: use YYYY-MM-DD")
+			return models.Date{}, models.Date{}, false
+		}
+		dates[i] = date
+	}
+	return dates[0], dates[1], true
+}
+
+// Helper function to map meal plan service errors to HTTP responses
+func respondWithMealPlanError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrMealPlanNotFound), errors.Is(err, services.ErrMealPlanEntryNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidMealPlan), errors.Is(err, services.ErrInvalidMealPlanEntry),
+		errors.Is(err, services.ErrInvalidWeekCopy):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithRecipeError(w, err)
+	}
+}
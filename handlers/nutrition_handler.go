@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"playground/services"
+)
+
+// NutritionHandler handles HTTP requests for recipe nutrition
+type NutritionHandler struct {
+	nutritionService *services.NutritionService
+}
+
+// NewNutritionHandler creates a new nutrition handler with the given service
+func NewNutritionHandler(nutritionService *services.NutritionService) *NutritionHandler {
+	return &NutritionHandler{
+		nutritionService: nutritionService,
+	}
+}
+
+// GetRecipeNutrition returns the total and per-serving nutrition of a recipe
+func (h *NutritionHandler) GetRecipeNutrition(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	facts, err := h.nutritionService.GetRecipeNutrition(id, caller)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, facts)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"playground/models"
+	"playground/services"
+)
+
+// PantryHandler handles HTTP requests for pantry items
+type PantryHandler struct {
+	pantryService *services.PantryService
+}
+
+// NewPantryHandler creates a new pantry handler with the given service
+func NewPantryHandler(pantryService *services.PantryService) *PantryHandler {
+	return &PantryHandler{
+		pantryService: pantryService,
+	}
+}
+
+// GetPantry returns the authenticated user's pantry items
+func (h *PantryHandler) GetPantry(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	items := h.pantryService.GetPantry(caller)
+	respondWithJSON(w, http.StatusOK, items)
+}
+
+// GetPantryItem returns a pantry item by ID
+func (h *PantryHandler) GetPantryItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	item, err := h.pantryService.GetPantryItem(id, caller)
+	if err != nil {
+		respondWithPantryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, item)
+}
+
+// AddPantryItem adds an ingredient to the pantry from JSON request body
+func (h *PantryHandler) AddPantryItem(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.PantryItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	item, err := h.pantryService.AddPantryItem(caller, input)
+	if err != nil {
+		respondWithPantryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, item)
+}
+
+// UpdatePantryItem changes a pantry item from JSON request body
+func (h *PantryHandler) UpdatePantryItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.PantryItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	item, err := h.pantryService.UpdatePantryItem(id, caller, input)
+	if err != nil {
+		respondWithPantryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, item)
+}
+
+// DeletePantryItem removes a pantry item
+func (h *PantryHandler) DeletePantryItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.pantryService.DeletePantryItem(id, caller); err != nil {
+		respondWithPantryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// Helper function to map pantry service errors to HTTP responses
+func respondWithPantryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPantryItem):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	}
+}
@@ -13,28 +13,42 @@ import (
 // RatingHandler handles HTTP requests for recipe ratings
 type RatingHandler struct {
 	ratingService *services.RatingService
+	recipeService *services.RecipeService
 }
 
-// NewRatingHandler creates a new rating handler with the given service
-func NewRatingHandler(ratingService *services.RatingService) *RatingHandler {
+// NewRatingHandler creates a new rating handler with the given services
+func NewRatingHandler(ratingService *services.RatingService, recipeService *services.RecipeService) *RatingHandler {
 	return &RatingHandler{
 		ratingService: ratingService,
+		recipeService: recipeService,
 	}
 }
 
-// GetRatingsByRecipeID returns all ratings for a specific recipe
+// GetRatingsByRecipeID returns all ratings for a recipe the caller may see
 func (h *RatingHandler) GetRatingsByRecipeID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	recipeID := vars["recipeId"]
+	recipeID := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	if _, err := h.recipeService.GetVisibleRecipe(recipeID, caller); err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
 
 	ratings := h.ratingService.GetRatingsByRecipeID(recipeID)
 	respondWithJSON(w, http.StatusOK, ratings)
 }
 
-// GetAverageRatingForRecipe returns the average rating score for a recipe
+// GetAverageRatingForRecipe returns the average rating score for a recipe the caller may see
 func (h *RatingHandler) GetAverageRatingForRecipe(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	recipeID := vars["recipeId"]
+	recipeID := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	if _, err := h.recipeService.GetVisibleRecipe(recipeID, caller); err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
 
 	average := h.ratingService.GetAverageRatingForRecipe(recipeID)
 	respondWithJSON(w, http.StatusOK, map[string]float64{"average": average})
@@ -43,7 +57,7 @@ func (h *RatingHandler) GetAverageRatingForRecipe(w http.ResponseWriter, r *http
 // CreateRating adds a new rating for a recipe
 func (h *RatingHandler) CreateRating(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	recipeID := vars["recipeId"]
+	recipeID := vars["id"]
 
 	// Get user ID from context (set by auth middleware)
 	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
@@ -77,7 +91,7 @@ func (h *RatingHandler) CreateRating(w http.ResponseWriter, r *http.Request) {
 // UpdateRating modifies an existing rating
 func (h *RatingHandler) UpdateRating(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	id := vars["id"]
+	id := vars["ratingId"]
 
 	// Get user ID from context (set by auth middleware)
 	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
@@ -111,7 +125,7 @@ func (h *RatingHandler) UpdateRating(w http.ResponseWriter, r *http.Request) {
 // DeleteRating removes a rating
 func (h *RatingHandler) DeleteRating(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	id := vars["id"]
+	id := vars["ratingId"]
 
 	// Get user ID from context (set by auth middleware)
 	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
@@ -127,4 +141,25 @@ func (h *RatingHandler) DeleteRating(w http.ResponseWriter, r *http.Request) {
 	}
 
 	respondWithJSON(w, http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
+
+// RestoreRating takes a rating out of the trash
+func (h *RatingHandler) RestoreRating(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["ratingId"]
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	rating, err := h.ratingService.RestoreRating(id, userID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rating)
+}
@@ -2,47 +2,201 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
+	"playground/middleware"
 	"playground/models"
 	"playground/services"
 )
 
 // RecipeHandler handles HTTP requests for recipes
 type RecipeHandler struct {
-	service *services.RecipeService
+	service           *services.RecipeService
+	conversionService *services.ConversionService
+	favoriteService   *services.FavoriteService
+	noteService       *services.NoteService
 }
 
-// NewRecipeHandler creates a new recipe handler with the given service
-func NewRecipeHandler(service *services.RecipeService) *RecipeHandler {
+// NewRecipeHandler creates a new recipe handler with the given services
+func NewRecipeHandler(service *services.RecipeService, conversionService *services.ConversionService,
+	favoriteService *services.FavoriteService, noteService *services.NoteService) *RecipeHandler {
 	return &RecipeHandler{
-		service: service,
+		service:           service,
+		conversionService: conversionService,
+		favoriteService:   favoriteService,
+		noteService:       noteService,
 	}
 }
 
 // GetAllRecipes returns all recipes as JSON
 func (h *RecipeHandler) GetAllRecipes(w http.ResponseWriter, r *http.Request) {
-	recipes := h.service.GetAllRecipes()
+	caller, _ := callerFromRequest(r)
+
+	recipes := h.service.GetAllRecipes(caller)
 	respondWithJSON(w, http.StatusOK, recipes)
 }
 
-// GetRecipeByID returns a recipe by ID as JSON
+// GetRecipeByID returns a recipe by ID as JSON with its favorite count and, for the
+// authenticated user, whether they favorited it and their private note; the Accept header
+// can ask for schema.org JSON-LD, Markdown or a printable HTML page instead
 func (h *RecipeHandler) GetRecipeByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
+	caller, authenticated := callerFromRequest(r)
 
-	recipe, err := h.service.GetRecipeByID(id)
+	recipe, err := h.service.GetVisibleRecipe(id, caller)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Recipe not found")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, recipe)
+	recipe, ok := h.convertUnits(w, r, recipe)
+	if !ok {
+		return
+	}
+
+	personal := models.PersonalRecipe{
+		Recipe:        recipe,
+		FavoriteCount: h.favoriteService.CountFavorites(recipe.ID),
+	}
+	if authenticated {
+		personal.Favorite = h.favoriteService.IsFavorite(recipe.ID, caller)
+		if note, err := h.noteService.GetNote(recipe.ID, caller); err == nil {
+			personal.Note = &note
+		}
+	}
+
+	respondWithNegotiated(w, r, http.StatusOK, personal, recipeRenderers)
+}
+
+// ScaleRecipe returns a recipe scaled by the servings or factor query parameter in the representation
+// the Accept header prefers
+func (h *RecipeHandler) ScaleRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	servingsParam := r.URL.Query().Get("servings")
+	factorParam := r.URL.Query().Get("factor")
+
+	var recipe models.Recipe
+	var err error
+	switch {
+	case servingsParam != "":
+		servings, convErr := strconv.Atoi(servingsParam)
+		if convErr != nil || servings < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid servings parameter")
+			return
+		}
+		recipe, err = h.service.ScaleRecipeToServings(id, servings, caller)
+	case factorParam != "":
+		factor, parseErr := models.ParseQuantity(factorParam)
+		if parseErr != nil || factor.Float64() <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid factor parameter")
+			return
+		}
+		recipe, err = h.service.ScaleRecipe(id, factor, caller)
+	default:
+		respondWithError(w, http.StatusBadRequest, "Missing servings or factor parameter")
+		return
+	}
+
+	if errors.Is(err, services.ErrInvalidScale) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Recipe not found")
+		return
+	}
+
+	recipe, ok := h.convertUnits(w, r, recipe)
+	if !ok {
+		return
+	}
+
+	respondWithNegotiated(w, r, http.StatusOK, recipe, recipeRenderers)
+}
+
+// GetRecipeTiming returns the active and passive time of a recipe with a timer for every timed step
+func (h *RecipeHandler) GetRecipeTiming(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	timing, err := h.service.GetRecipeTiming(id, caller)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, timing)
+}
+
+// GetRecipeTree returns the sub-recipe tree of a recipe as JSON, optionally scaled by the
+// servings or factor query parameter
+func (h *RecipeHandler) GetRecipeTree(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	servingsParam := r.URL.Query().Get("servings")
+	factorParam := r.URL.Query().Get("factor")
+
+	var tree models.RecipeTree
+	var err error
+	switch {
+	case servingsParam != "":
+		servings, convErr := strconv.Atoi(servingsParam)
+		if convErr != nil || servings < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid servings parameter")
+			return
+		}
+		tree, err = h.service.GetRecipeTreeForServings(id, servings, caller)
+	case factorParam != "":
+		factor, parseErr := models.ParseQuantity(factorParam)
+		if parseErr != nil || factor.Float64() <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid factor parameter")
+			return
+		}
+		tree, err = h.service.GetRecipeTree(id, factor, caller)
+	default:
+		tree, err = h.service.GetRecipeTree(id, models.WholeQuantity(1), caller)
+	}
+
+	if errors.Is(err, services.ErrInvalidScale) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tree)
+}
+
+// GetRecipesByAuthor returns all recipes created by a specific user as JSON
+func (h *RecipeHandler) GetRecipesByAuthor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	authorID := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	recipes := h.service.GetRecipesByAuthor(authorID, caller)
+	respondWithJSON(w, http.StatusOK, recipes)
 }
 
 // CreateRecipe creates a new recipe from JSON request body
 func (h *RecipeHandler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	var input models.RecipeInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
@@ -50,7 +204,11 @@ func (h *RecipeHandler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	recipe := h.service.CreateRecipe(input)
+	recipe, err := h.service.CreateRecipe(caller.UserID, input)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
 	respondWithJSON(w, http.StatusCreated, recipe)
 }
 
@@ -59,6 +217,12 @@ func (h *RecipeHandler) UpdateRecipe(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	var input models.RecipeInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
@@ -66,9 +230,9 @@ func (h *RecipeHandler) UpdateRecipe(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	recipe, err := h.service.UpdateRecipe(id, input)
+	recipe, err := h.service.UpdateRecipe(id, caller, input)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Recipe not found")
+		respondWithRecipeError(w, err)
 		return
 	}
 
@@ -80,28 +244,120 @@ func (h *RecipeHandler) DeleteRecipe(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.service.DeleteRecipe(id); err != nil {
-		respondWithError(w, http.StatusNotFound, "Recipe not found")
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.service.DeleteRecipe(id, caller); err != nil {
+		respondWithRecipeError(w, err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusNoContent, nil)
 }
 
-// Helper function to respond with JSON
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	response, err := json.Marshal(payload)
+// StatusRequest represents the body of a recipe status change
+type StatusRequest struct {
+	Status models.RecipeStatus `json:"status"`
+}
+
+// UpdateRecipeStatus moves a recipe to the draft, published or archived status
+func (h *RecipeHandler) UpdateRecipeStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req StatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	recipe, err := h.service.TransitionRecipe(id, caller, req.Status)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, recipe)
+}
+
+// RestoreRecipe takes a deleted recipe out of the trash
+func (h *RecipeHandler) RestoreRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	recipe, err := h.service.RestoreRecipe(id, caller)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Internal server error")
+		respondWithRecipeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	w.Write(response)
+	respondWithJSON(w, http.StatusOK, recipe)
+}
+
+// convertUnits applies the optional units query parameter to a recipe, responding with
+// an error and returning false when the parameter is invalid
+func (h *RecipeHandler) convertUnits(w http.ResponseWriter, r *http.Request, recipe models.Recipe) (models.Recipe, bool) {
+	unitsParam := r.URL.Query().Get("units")
+	if unitsParam == "" {
+		return recipe, true
+	}
+
+	system, err := services.ParseMeasurementSystem(unitsParam)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid units parameter")
+		return models.Recipe{}, false
+	}
+
+	return h.conversionService.ConvertRecipe(recipe, system), true
+}
+
+// Helper function to respond with JSON
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	respondWith(w, code, jsonRenderer, payload)
 }
 
 // Helper function to respond with an error
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	respondWithJSON(w, code, map[string]string{"error": message})
-}
\ No newline at end of file
+}
+
+// Helper function to respond with the status matching a recipe service error
+func respondWithRecipeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotRecipeOwner):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidTransition), errors.Is(err, services.ErrNotPublishable),
+		errors.Is(err, services.ErrRecipeCycle), errors.Is(err, services.ErrSubRecipeNotFound),
+		errors.Is(err, services.ErrTooManySubRecipes):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusNotFound, "Recipe not found")
+	}
+}
+
+// Helper function to read the authenticated caller from the request context;
+// anonymous requests yield an empty caller and false
+func callerFromRequest(r *http.Request) (services.Caller, bool) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok {
+		return services.Caller{}, false
+	}
+	role, _ := r.Context().Value(middleware.UserRoleKey).(string)
+	return services.Caller{UserID: userID, Role: role}, true
+}
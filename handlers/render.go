@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"playground/models"
+	"playground/services"
+)
+
+// renderer writes response payloads in one media type
+type renderer struct {
+	mediaType   string
+	contentType string
+	write       func(w io.Writer, payload interface{}) error
+}
+
+// jsonRenderer writes payloads as JSON, the default for every endpoint
+var jsonRenderer = renderer{"application/json", "application/json", writeJSON}
+
+// recipeRenderers are the representations of a recipe a client can ask for with the Accept header;
+// the first one is used when the client accepts anything
+var recipeRenderers = []renderer{
+	jsonRenderer,
+	{"application/ld+json", "application/ld+json", renderRecipe(func(w io.Writer, recipe models.Recipe) error {
+		return writeJSON(w, services.RecipeJSONLD(recipe))
+	})},
+	{"text/markdown", "text/markdown; charset=utf-8", renderRecipe(services.WriteRecipeMarkdown)},
+	{"text/html", "text/html; charset=utf-8", renderRecipe(services.WriteRecipeHTML)},
+}
+
+// writeJSON writes payload as JSON
+func writeJSON(w io.Writer, payload interface{}) error {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(response)
+	return err
+}
+
+// renderRecipe adapts a recipe writer to the recipe payloads handlers respond with
+func renderRecipe(write func(w io.Writer, recipe models.Recipe) error) func(w io.Writer, payload interface{}) error {
+	return func(w io.Writer, payload interface{}) error {
+		switch p := payload.(type) {
+		case models.Recipe:
+			return write(w, p)
+		case models.PersonalRecipe:
+			return write(w, p.Recipe)
+		}
+		return fmt.Errorf("cannot render %T as a recipe", payload)
+	}
+}
+
+// Helper function to respond in the representation the request's Accept header prefers
+// among renderers, or with 406 Not Acceptable when it accepts none of them
+func respondWithNegotiated(w http.ResponseWriter, r *http.Request, code int, payload interface{}, renderers []renderer) {
+	w.Header().Add("Vary", "Accept")
+
+	format, ok := negotiate(r.Header.Values("Accept"), renderers)
+	if !ok {
+		types := make([]string, 0, len(renderers))
+		for _, renderer := range renderers {
+			types = append(types, renderer.mediaType)
+		}
+		respondWithError(w, http.StatusNotAcceptable, "Not acceptable: use one of "+strings.Join(types, ", "))
+		return
+	}
+
+	respondWith(w, code, format, payload)
+}
+
+// Helper function to respond with payload written by format; the payload is rendered in full
+// first so a failure can still be reported as a server error
+func respondWith(w http.ResponseWriter, code int, format renderer, payload interface{}) {
+	var body bytes.Buffer
+	if err := format.write(&body, payload); err != nil {
+		log.Printf("Cannot render %s response: %v", format.mediaType, err)
+		respondWithError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.WriteHeader(code)
+	w.Write(body.Bytes())
+}
+
+// negotiate picks the renderer with the highest quality in the Accept header values. Each media type
+// takes its quality from the most specific range that matches it; ties go to the earlier renderer,
+// and a request without a usable Accept header gets the first.
+func negotiate(accept []string, renderers []renderer) (renderer, bool) {
+	if len(accept) == 0 {
+		return renderers[0], true
+	}
+
+	type acceptRange struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []acceptRange
+	for _, header := range accept {
+		for _, part := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			ranges = append(ranges, acceptRange{mediaType, quality})
+		}
+	}
+	if len(ranges) == 0 {
+		return renderers[0], true
+	}
+
+	best, bestQuality := renderer{}, 0.0
+	for _, candidate := range renderers {
+		mainType := strings.SplitN(candidate.mediaType, "/", 2)[0]
+		specificity, quality := 0, 0.0
+		for _, accepted := range ranges {
+			level := 0
+			switch accepted.mediaType {
+			case candidate.mediaType:
+				level = 3
+			case mainType + "/*":
+				level = 2
+			case "*/*":
+				level = 1
+			}
+			if level > specificity {
+				specificity, quality = level, accepted.quality
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = candidate, quality
+		}
+	}
+	return best, bestQuality > 0
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"playground/services"
+)
+
+// RevisionHandler handles HTTP requests for recipe revision history
+type RevisionHandler struct {
+	recipeService *services.RecipeService
+}
+
+// NewRevisionHandler creates a new revision handler with the given service
+func NewRevisionHandler(recipeService *services.RecipeService) *RevisionHandler {
+	return &RevisionHandler{
+		recipeService: recipeService,
+	}
+}
+
+// GetRevisions returns every revision of a recipe
+func (h *RevisionHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	revisions, err := h.recipeService.GetRevisions(id, caller)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Recipe not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
+
+// GetRevision returns a single revision of a recipe
+func (h *RevisionHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	number, err := strconv.Atoi(vars["rev"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid revision number")
+		return
+	}
+
+	caller, _ := callerFromRequest(r)
+	revision, err := h.recipeService.GetRevision(id, number, caller)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, revision)
+}
+
+// DiffRevisions returns the field-level changes between the revisions in the from and to query parameters
+func (h *RevisionHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid from parameter")
+		return
+	}
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid to parameter")
+		return
+	}
+
+	caller, _ := callerFromRequest(r)
+	diff, err := h.recipeService.DiffRevisions(id, from, to, caller)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, diff)
+}
+
+// RevertRecipe saves the content of an earlier revision as a new revision
+func (h *RevisionHandler) RevertRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	number, err := strconv.Atoi(vars["rev"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid revision number")
+		return
+	}
+
+	recipe, err := h.recipeService.RevertRecipe(id, number, caller)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, recipe)
+}
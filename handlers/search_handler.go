@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
+	"playground/models"
 	"playground/services"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // SearchHandler handles HTTP requests for searching recipes
@@ -26,7 +30,8 @@ func (h *SearchHandler) SearchByIngredient(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	recipes := h.searchService.SearchByIngredient(ingredient)
+	caller, _ := callerFromRequest(r)
+	recipes := h.searchService.SearchByIngredient(ingredient, caller)
 	respondWithJSON(w, http.StatusOK, recipes)
 }
 
@@ -38,7 +43,8 @@ func (h *SearchHandler) SearchByTag(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	recipes := h.searchService.SearchByTag(tag)
+	caller, _ := callerFromRequest(r)
+	recipes := h.searchService.SearchByTag(tag, caller)
 	respondWithJSON(w, http.StatusOK, recipes)
 }
 
@@ -50,10 +56,99 @@ func (h *SearchHandler) SearchByTitle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	recipes := h.searchService.SearchByTitle(title)
+	caller, _ := callerFromRequest(r)
+	recipes := h.searchService.SearchByTitle(title, caller)
 	respondWithJSON(w, http.StatusOK, recipes)
 }
 
+// SearchByCalories returns recipes within the min and max calories per serving; either bound may be omitted
+func (h *SearchHandler) SearchByCalories(w http.ResponseWriter, r *http.Request) {
+	minParam := r.URL.Query().Get("min")
+	maxParam := r.URL.Query().Get("max")
+	if minParam == "" && maxParam == "" {
+		respondWithError(w, http.StatusBadRequest, "Missing min or max parameter")
+		return
+	}
+
+	min, max := 0.0, math.Inf(1)
+	if minParam != "" {
+		value, err := strconv.ParseFloat(minParam, 64)
+		if err != nil || value < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid min parameter")
+			return
+		}
+		min = value
+	}
+	if maxParam != "" {
+		value, err := strconv.ParseFloat(maxParam, 64)
+		if err != nil || value < min {
+			respondWithError(w, http.StatusBadRequest, "Invalid max parameter")
+			return
+		}
+		max = value
+	}
+
+	caller, _ := callerFromRequest(r)
+	recipes := h.searchService.SearchByCalories(min, max, caller)
+	respondWithJSON(w, http.StatusOK, recipes)
+}
+
+// SearchByDiet returns recipes with every label parameter (e.g. vegan) and none of the allergens
+// in the exclude parameters (e.g. peanuts); both may be repeated or comma-separated
+func (h *SearchHandler) SearchByDiet(w http.ResponseWriter, r *http.Request) {
+	labels := make([]models.DietaryLabel, 0)
+	for _, value := range queryList(r, "label") {
+		label := models.DietaryLabel(strings.ToLower(value))
+		if !services.Contains(models.AllDietaryLabels, label) {
+			respondWithError(w, http.StatusBadRequest, "Unknown dietary label: "+value)
+			return
+		}
+		labels = append(labels, label)
+	}
+
+	excluded := make([]models.Allergen, 0)
+	for _, value := range queryList(r, "exclude") {
+		allergen := models.Allergen(strings.ToLower(value))
+		if !services.Contains(models.AllAllergens, allergen) {
+			respondWithError(w, http.StatusBadRequest, "Unknown allergen: "+value)
+			return
+		}
+		excluded = append(excluded, allergen)
+	}
+
+	if len(labels) == 0 && len(excluded) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Missing label or exclude parameter")
+		return
+	}
+
+	caller, _ := callerFromRequest(r)
+	recipes := h.searchService.SearchByDiet(labels, excluded, caller)
+	respondWithJSON(w, http.StatusOK, recipes)
+}
+
+// SearchByPantry returns the recipes the authenticated user can cook with their pantry, best matches
+// first; maxMissing limits how many ingredients a result may lack
+func (h *SearchHandler) SearchByPantry(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	maxMissing := -1
+	if param := r.URL.Query().Get("maxMissing"); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid maxMissing parameter")
+			return
+		}
+		maxMissing = value
+	}
+
+	matches := h.searchService.SearchByPantry(caller, maxMissing, models.NewDate(time.Now()))
+	respondWithJSON(w, http.StatusOK, matches)
+}
+
 // GetPaginatedRecipes returns a paginated list of recipes
 func (h *SearchHandler) GetPaginatedRecipes(w http.ResponseWriter, r *http.Request) {
 	// Default values
@@ -82,6 +177,20 @@ func (h *SearchHandler) GetPaginatedRecipes(w http.ResponseWriter, r *http.Reque
 		pageSize = pageSizeVal
 	}
 
-	recipes := h.searchService.GetPaginatedRecipes(page, pageSize)
+	caller, _ := callerFromRequest(r)
+	recipes := h.searchService.GetPaginatedRecipes(page, pageSize, caller)
 	respondWithJSON(w, http.StatusOK, recipes)
 }
+
+// queryList returns the values of a query parameter that may be repeated or comma-separated
+func queryList(r *http.Request, name string) []string {
+	values := make([]string, 0)
+	for _, param := range r.URL.Query()[name] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"playground/models"
+	"playground/services"
+)
+
+// ShareHandler handles HTTP requests for recipe share links
+type ShareHandler struct {
+	shareService *services.ShareService
+}
+
+// NewShareHandler creates a new share handler with the given service
+func NewShareHandler(shareService *services.ShareService) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+	}
+}
+
+// CreateShareLink mints a share link for a recipe; the token is only returned in this response
+func (h *ShareHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.ShareLinkInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	var lifetime time.Duration
+	if input.ExpiresIn != "" {
+		var err error
+		lifetime, err = time.ParseDuration(input.ExpiresIn)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid expiresIn duration")
+			return
+		}
+	}
+
+	link, err := h.shareService.CreateShareLink(id, caller, lifetime)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidShareLifetime) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, link)
+}
+
+// GetShareLinks returns every share link of a recipe
+func (h *ShareHandler) GetShareLinks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	links, err := h.shareService.GetShareLinks(id, caller)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, links)
+}
+
+// RevokeShareLink stops a share link from granting access
+func (h *ShareHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	shareID := vars["shareId"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	link, err := h.shareService.RevokeShareLink(id, shareID, caller)
+	if err != nil {
+		if errors.Is(err, services.ErrNotRecipeOwner) {
+			respondWithRecipeError(w, err)
+			return
+		}
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, link)
+}
+
+// GetSharedRecipe returns the recipe a share token grants access to, without requiring an account,
+// in the representation the Accept header prefers
+func (h *ShareHandler) GetSharedRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	token := vars["token"]
+
+	recipe, err := h.shareService.ResolveShareLink(token)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithNegotiated(w, r, http.StatusOK, recipe, recipeRenderers)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"playground/models"
+	"playground/services"
+)
+
+// shoppingListFormats maps the export formats to their content type, file extension and writer
+var shoppingListFormats = map[string]struct {
+	contentType string
+	extension   string
+	write       func(w io.Writer, list models.ShoppingList) error
+}{
+	"text": {"text/plain; charset=utf-8", "txt", services.WriteShoppingListText},
+	"csv":  {"text/csv; charset=utf-8", "csv", services.WriteShoppingListCSV},
+}
+
+// ShoppingListHandler handles HTTP requests for shopping lists
+type ShoppingListHandler struct {
+	shoppingListService *services.ShoppingListService
+}
+
+// NewShoppingListHandler creates a new shopping list handler with the given service
+func NewShoppingListHandler(shoppingListService *services.ShoppingListService) *ShoppingListHandler {
+	return &ShoppingListHandler{
+		shoppingListService: shoppingListService,
+	}
+}
+
+// GetShoppingLists returns the authenticated user's shopping lists
+func (h *ShoppingListHandler) GetShoppingLists(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	lists := h.shoppingListService.GetShoppingLists(caller)
+	respondWithJSON(w, http.StatusOK, lists)
+}
+
+// GetShoppingList returns a shopping list by ID
+func (h *ShoppingListHandler) GetShoppingList(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	list, err := h.shoppingListService.GetShoppingList(id, caller)
+	if err != nil {
+		respondWithShoppingListError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// CreateShoppingList builds a shopping list from the recipes or meal plan in the JSON request body
+func (h *ShoppingListHandler) CreateShoppingList(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.ShoppingListInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	list, err := h.shoppingListService.CreateShoppingList(caller, input)
+	if err != nil {
+		respondWithShoppingListError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, list)
+}
+
+// CheckItem checks an item off a shopping list, or unchecks it, from JSON request body
+func (h *ShoppingListHandler) CheckItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.ShoppingListItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	item, err := h.shoppingListService.CheckItem(vars["id"], vars["itemId"], caller, input)
+	if err != nil {
+		respondWithShoppingListError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, item)
+}
+
+// DeleteShoppingList removes a shopping list
+func (h *ShoppingListHandler) DeleteShoppingList(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.shoppingListService.DeleteShoppingList(id, caller); err != nil {
+		respondWithShoppingListError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// ExportShoppingList downloads a shopping list as plain text, or as CSV with ?format=csv
+func (h *ShoppingListHandler) ExportShoppingList(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "text"
+	}
+	format, ok := shoppingListFormats[name]
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid format parameter: use text or csv")
+		return
+	}
+
+	list, err := h.shoppingListService.GetShoppingList(id, caller)
+	if err != nil {
+		respondWithShoppingListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="shopping-list.`+format.extension+`"`)
+	w.WriteHeader(http.StatusOK)
+	if err := format.write(w, list); err != nil {
+		log.Printf("Cannot send shopping list %s: %v", list.ID, err)
+	}
+}
+
+// Helper function to map shopping list service errors to HTTP responses
+func respondWithShoppingListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrShoppingListNotFound), errors.Is(err, services.ErrShoppingListItemNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidShoppingList):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithMealPlanError(w, err)
+	}
+}
@@ -23,11 +23,11 @@ func (h *SortHandler) SortRecipes(w http.ResponseWriter, r *http.Request) {
 	// Get sort criteria from query parameters
 	criteriaParam := r.URL.Query().Get("criteria")
 	orderParam := r.URL.Query().Get("order")
-	
+
 	// Default values
 	criteria := services.SortByTitle
 	ascending := true
-	
+
 	// Parse criteria parameter
 	switch strings.ToLower(criteriaParam) {
 	case "preptime":
@@ -40,16 +40,21 @@ func (h *SortHandler) SortRecipes(w http.ResponseWriter, r *http.Request) {
 		criteria = services.SortByTitle
 	case "servings":
 		criteria = services.SortByServings
+	case "calories":
+		criteria = services.SortByCalories
+	case "favorites":
+		criteria = services.SortByFavorites
 	}
-	
+
 	// Parse order parameter
 	if strings.ToLower(orderParam) == "desc" || strings.ToLower(orderParam) == "descending" {
 		ascending = false
 	}
-	
+
 	// Get sorted recipes
-	recipes := h.recipeService.SortRecipes(criteria, ascending)
-	
+	caller, _ := callerFromRequest(r)
+	recipes := h.recipeService.SortRecipes(criteria, ascending, caller)
+
 	// Return sorted recipes
 	respondWithJSON(w, http.StatusOK, recipes)
-}
\ No newline at end of file
+}
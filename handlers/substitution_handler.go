@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"playground/models"
+	"playground/services"
+)
+
+// SubstitutionHandler handles HTTP requests for ingredient substitutions
+type SubstitutionHandler struct {
+	substitutionService *services.SubstitutionService
+}
+
+// NewSubstitutionHandler creates a new substitution handler with the given service
+func NewSubstitutionHandler(substitutionService *services.SubstitutionService) *SubstitutionHandler {
+	return &SubstitutionHandler{
+		substitutionService: substitutionService,
+	}
+}
+
+// GetSubstitutions proposes swaps for the ingredients of a recipe; with a label parameter (e.g. vegan)
+// it also returns a variant of the recipe with swaps applied to meet that dietary label
+func (h *SubstitutionHandler) GetSubstitutions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	caller, _ := callerFromRequest(r)
+
+	var label models.DietaryLabel
+	if value := r.URL.Query().Get("label"); value != "" {
+		label = models.DietaryLabel(strings.ToLower(value))
+		if !services.Contains(models.AllDietaryLabels, label) {
+			respondWithError(w, http.StatusBadRequest, "Unknown dietary label: "+value)
+			return
+		}
+	}
+
+	report, err := h.substitutionService.GetSubstitutions(id, caller, label)
+	if err != nil {
+		respondWithRecipeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
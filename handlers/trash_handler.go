@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"playground/middleware"
+	"playground/services"
+)
+
+// TrashHandler handles HTTP requests for the trash bin
+type TrashHandler struct {
+	trashService *services.TrashService
+}
+
+// NewTrashHandler creates a new trash handler with the given service
+func NewTrashHandler(trashService *services.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// GetTrash returns the authenticated user's deleted recipes and ratings
+func (h *TrashHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context (set by auth middleware)
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	trash := h.trashService.GetTrash(userID)
+	respondWithJSON(w, http.StatusOK, trash)
+}
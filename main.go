@@ -1,11 +1,15 @@
 package main
 
 import (
+	"crypto/rand"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"playground/handlers"
 	"playground/middleware"
+	"playground/models"
 	"playground/repositories"
 	"playground/services"
 
@@ -17,19 +21,61 @@ func main() {
 	userRepo := repositories.NewInMemoryUserRepository()
 	recipeRepo := repositories.NewInMemoryRecipeRepository()
 	ratingRepo := repositories.NewInMemoryRatingRepository()
+	shareLinkRepo := repositories.NewInMemoryShareLinkRepository()
+	collectionRepo := repositories.NewInMemoryCollectionRepository()
+	favoriteRepo := repositories.NewInMemoryFavoriteRepository()
+	noteRepo := repositories.NewInMemoryNoteRepository()
+	mealPlanRepo := repositories.NewInMemoryMealPlanRepository()
+	shoppingListRepo := repositories.NewInMemoryShoppingListRepository()
+	pantryRepo := repositories.NewInMemoryPantryRepository()
+	imageStore := imageBlobStore()
 
 	// Create services
 	userService := services.NewUserService(userRepo)
 	recipeService := services.NewRecipeService(recipeRepo)
 	ratingService := services.NewRatingService(ratingRepo, recipeService)
-	searchService := services.NewSearchService(recipeService)
+	conversionService := services.NewConversionService()
+	nutritionService := services.NewNutritionService(nutrientTable(), recipeService, conversionService)
+	pantryService := services.NewPantryService(pantryRepo, conversionService)
+	substitutionService := services.NewSubstitutionService(substitutionRules(), recipeService, conversionService)
+	searchService := services.NewSearchService(recipeService, nutritionService, pantryService)
+	shareService := services.NewShareService(shareLinkRepo, recipeService, shareLinkSecret())
+	imageService := services.NewImageService(imageStore, recipeService)
+	collectionService := services.NewCollectionService(collectionRepo, recipeService)
+	favoriteService := services.NewFavoriteService(favoriteRepo, recipeService)
+	noteService := services.NewNoteService(noteRepo, recipeService)
+	mealPlanService := services.NewMealPlanService(mealPlanRepo, recipeService)
+	shoppingListService := services.NewShoppingListService(shoppingListRepo, recipeService, mealPlanService, conversionService)
+	bulkService := services.NewBulkService(recipeService, ratingService)
+	cookbookService := services.NewCookbookService(recipeService, collectionService, imageService)
+	importService := services.NewImportService(recipeService, services.NewPublicHTTPFetcher())
+	trashService := services.NewTrashService(recipeService, ratingService, trashRetention())
+
+	// Permanently remove trashed items once they outlive the retention window
+	stopPurger := trashService.StartPurger(time.Hour)
+	defer stopPurger()
 
 	// Create handlers
 	authHandler := handlers.NewAuthHandler(userService)
-	recipeHandler := handlers.NewRecipeHandler(recipeService)
-	ratingHandler := handlers.NewRatingHandler(ratingService)
+	recipeHandler := handlers.NewRecipeHandler(recipeService, conversionService, favoriteService, noteService)
+	ratingHandler := handlers.NewRatingHandler(ratingService, recipeService)
 	searchHandler := handlers.NewSearchHandler(searchService)
 	sortHandler := handlers.NewSortHandler(recipeService)
+	revisionHandler := handlers.NewRevisionHandler(recipeService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	shareHandler := handlers.NewShareHandler(shareService)
+	forkHandler := handlers.NewForkHandler(recipeService)
+	nutritionHandler := handlers.NewNutritionHandler(nutritionService)
+	imageHandler := handlers.NewImageHandler(imageService)
+	collectionHandler := handlers.NewCollectionHandler(collectionService)
+	favoriteHandler := handlers.NewFavoriteHandler(favoriteService, noteService)
+	mealPlanHandler := handlers.NewMealPlanHandler(mealPlanService)
+	shoppingListHandler := handlers.NewShoppingListHandler(shoppingListService)
+	pantryHandler := handlers.NewPantryHandler(pantryService)
+	substitutionHandler := handlers.NewSubstitutionHandler(substitutionService)
+	importHandler := handlers.NewImportHandler(importService)
+	bulkHandler := handlers.NewBulkHandler(bulkService)
+	cookbookHandler := handlers.NewCookbookHandler(cookbookService)
 
 	// Create router
 	router := mux.NewRouter()
@@ -38,8 +84,9 @@ func main() {
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.RecoveryMiddleware)
 
-	// API routes
+	// API routes; public routes still identify the caller when a token is sent
 	api := router.PathPrefix("/api").Subrouter()
+	api.Use(middleware.OptionalAuthMiddleware(userService))
 
 	// Auth routes
 	auth := api.PathPrefix("/auth").Subrouter()
@@ -51,18 +98,125 @@ func main() {
 	recipes.HandleFunc("", recipeHandler.GetAllRecipes).Methods("GET")
 	recipes.HandleFunc("/", recipeHandler.GetAllRecipes).Methods("GET")
 	recipes.HandleFunc("/{id}", recipeHandler.GetRecipeByID).Methods("GET")
-	recipes.HandleFunc("", recipeHandler.CreateRecipe).Methods("POST")
-	recipes.HandleFunc("/", recipeHandler.CreateRecipe).Methods("POST")
-	recipes.HandleFunc("/{id}", recipeHandler.UpdateRecipe).Methods("PUT")
-	recipes.HandleFunc("/{id}", recipeHandler.DeleteRecipe).Methods("DELETE")
+	recipes.HandleFunc("/{id}/scale", recipeHandler.ScaleRecipe).Methods("GET")
+	recipes.HandleFunc("/{id}/timing", recipeHandler.GetRecipeTiming).Methods("GET")
+	recipes.HandleFunc("/{id}/tree", recipeHandler.GetRecipeTree).Methods("GET")
+	recipes.HandleFunc("/{id}/nutrition", nutritionHandler.GetRecipeNutrition).Methods("GET")
+	recipes.HandleFunc("/{id}/substitutions", substitutionHandler.GetSubstitutions).Methods("GET")
+	recipes.HandleFunc("/{id}/forks", forkHandler.GetForks).Methods("GET")
+	recipes.HandleFunc("/{id}/lineage", forkHandler.GetLineage).Methods("GET")
+	recipes.HandleFunc("/{id}/upstream", forkHandler.DiffUpstream).Methods("GET")
 
 	// Protected recipe routes (require authentication)
 	protectedRecipes := api.PathPrefix("/recipes").Subrouter()
 	protectedRecipes.Use(middleware.AuthMiddleware(userService))
 	protectedRecipes.HandleFunc("", recipeHandler.CreateRecipe).Methods("POST")
 	protectedRecipes.HandleFunc("/", recipeHandler.CreateRecipe).Methods("POST")
+	protectedRecipes.HandleFunc("/import", importHandler.ImportRecipe).Methods("POST")
 	protectedRecipes.HandleFunc("/{id}", recipeHandler.UpdateRecipe).Methods("PUT")
 	protectedRecipes.HandleFunc("/{id}", recipeHandler.DeleteRecipe).Methods("DELETE")
+	protectedRecipes.HandleFunc("/{id}/status", recipeHandler.UpdateRecipeStatus).Methods("PUT")
+	protectedRecipes.HandleFunc("/{id}/restore", recipeHandler.RestoreRecipe).Methods("POST")
+	protectedRecipes.HandleFunc("/{id}/fork", forkHandler.ForkRecipe).Methods("POST")
+	protectedRecipes.HandleFunc("/{id}/favorite", favoriteHandler.AddFavorite).Methods("POST")
+	protectedRecipes.HandleFunc("/{id}/favorite", favoriteHandler.RemoveFavorite).Methods("DELETE")
+	protectedRecipes.HandleFunc("/{id}/note", favoriteHandler.GetNote).Methods("GET")
+	protectedRecipes.HandleFunc("/{id}/note", favoriteHandler.SaveNote).Methods("PUT")
+	protectedRecipes.HandleFunc("/{id}/note", favoriteHandler.DeleteNote).Methods("DELETE")
+
+	// Revision routes
+	revisions := api.PathPrefix("/recipes/{id}/revisions").Subrouter()
+	revisions.HandleFunc("", revisionHandler.GetRevisions).Methods("GET")
+	revisions.HandleFunc("/diff", revisionHandler.DiffRevisions).Methods("GET")
+	revisions.HandleFunc("/{rev:[0-9]+}", revisionHandler.GetRevision).Methods("GET")
+
+	// Protected revision routes (require authentication)
+	protectedRevisions := api.PathPrefix("/recipes/{id}/revisions").Subrouter()
+	protectedRevisions.Use(middleware.AuthMiddleware(userService))
+	protectedRevisions.HandleFunc("/{rev:[0-9]+}/revert", revisionHandler.RevertRecipe).Methods("POST")
+
+	// Protected share link routes (require authentication)
+	protectedShares := api.PathPrefix("/recipes/{id}/shares").Subrouter()
+	protectedShares.Use(middleware.AuthMiddleware(userService))
+	protectedShares.HandleFunc("", shareHandler.CreateShareLink).Methods("POST")
+	protectedShares.HandleFunc("", shareHandler.GetShareLinks).Methods("GET")
+	protectedShares.HandleFunc("/{shareId}", shareHandler.RevokeShareLink).Methods("DELETE")
+
+	// Image routes
+	images := api.PathPrefix("/recipes/{id}/images").Subrouter()
+	images.HandleFunc("/{imageId}/{size}", imageHandler.GetImage).Methods("GET")
+
+	// Protected image routes (require authentication)
+	protectedImages := api.PathPrefix("/recipes/{id}/images").Subrouter()
+	protectedImages.Use(middleware.AuthMiddleware(userService))
+	protectedImages.HandleFunc("", imageHandler.UploadImage).Methods("POST")
+	protectedImages.HandleFunc("/order", imageHandler.ReorderImages).Methods("PUT")
+	protectedImages.HandleFunc("/{imageId}/cover", imageHandler.SetCoverImage).Methods("PUT")
+	protectedImages.HandleFunc("/{imageId}", imageHandler.DeleteImage).Methods("DELETE")
+
+	// Shared recipe routes (the token grants access without an account)
+	shared := api.PathPrefix("/shared").Subrouter()
+	shared.HandleFunc("/{token}", shareHandler.GetSharedRecipe).Methods("GET")
+
+	// User routes
+	users := api.PathPrefix("/users").Subrouter()
+	users.HandleFunc("/{id}/recipes", recipeHandler.GetRecipesByAuthor).Methods("GET")
+	users.HandleFunc("/{id}/collections", collectionHandler.GetCollectionsByOwner).Methods("GET")
+
+	// Routes for the authenticated user's own data
+	me := api.PathPrefix("/me").Subrouter()
+	me.Use(middleware.AuthMiddleware(userService))
+	me.HandleFunc("/trash", trashHandler.GetTrash).Methods("GET")
+	me.HandleFunc("/favorites", favoriteHandler.GetFavorites).Methods("GET")
+
+	// Collection routes
+	collections := api.PathPrefix("/collections").Subrouter()
+	collections.HandleFunc("/{id}", collectionHandler.GetCollection).Methods("GET")
+
+	// Protected collection routes (require authentication)
+	protectedCollections := api.PathPrefix("/collections").Subrouter()
+	protectedCollections.Use(middleware.AuthMiddleware(userService))
+	protectedCollections.HandleFunc("", collectionHandler.GetCollections).Methods("GET")
+	protectedCollections.HandleFunc("", collectionHandler.CreateCollection).Methods("POST")
+	protectedCollections.HandleFunc("/{id}", collectionHandler.UpdateCollection).Methods("PUT")
+	protectedCollections.HandleFunc("/{id}", collectionHandler.DeleteCollection).Methods("DELETE")
+	protectedCollections.HandleFunc("/{id}/recipes", collectionHandler.AddRecipe).Methods("POST")
+	protectedCollections.HandleFunc("/{id}/recipes/order", collectionHandler.ReorderRecipes).Methods("PUT")
+	protectedCollections.HandleFunc("/{id}/recipes/{recipeId}", collectionHandler.RemoveRecipe).Methods("DELETE")
+
+	// Meal plan routes (require authentication; meal plans are private)
+	mealPlans := api.PathPrefix("/mealplans").Subrouter()
+	mealPlans.Use(middleware.AuthMiddleware(userService))
+	mealPlans.HandleFunc("", mealPlanHandler.GetMealPlans).Methods("GET")
+	mealPlans.HandleFunc("", mealPlanHandler.CreateMealPlan).Methods("POST")
+	mealPlans.HandleFunc("/{id}", mealPlanHandler.GetMealPlan).Methods("GET")
+	mealPlans.HandleFunc("/{id}", mealPlanHandler.UpdateMealPlan).Methods("PUT")
+	mealPlans.HandleFunc("/{id}", mealPlanHandler.DeleteMealPlan).Methods("DELETE")
+	mealPlans.HandleFunc("/{id}/summary", mealPlanHandler.GetSummary).Methods("GET")
+	mealPlans.HandleFunc("/{id}/entries", mealPlanHandler.AddEntry).Methods("POST")
+	mealPlans.HandleFunc("/{id}/entries/{entryId}", mealPlanHandler.UpdateEntry).Methods("PUT")
+	mealPlans.HandleFunc("/{id}/entries/{entryId}", mealPlanHandler.DeleteEntry).Methods("DELETE")
+	mealPlans.HandleFunc("/{id}/copy-week", mealPlanHandler.CopyWeek).Methods("POST")
+	mealPlans.HandleFunc("/{id}/repeat-week", mealPlanHandler.RepeatWeek).Methods("POST")
+
+	// Shopping list routes (require authentication; shopping lists are private)
+	shoppingLists := api.PathPrefix("/shopping-lists").Subrouter()
+	shoppingLists.Use(middleware.AuthMiddleware(userService))
+	shoppingLists.HandleFunc("", shoppingListHandler.GetShoppingLists).Methods("GET")
+	shoppingLists.HandleFunc("", shoppingListHandler.CreateShoppingList).Methods("POST")
+	shoppingLists.HandleFunc("/{id}", shoppingListHandler.GetShoppingList).Methods("GET")
+	shoppingLists.HandleFunc("/{id}", shoppingListHandler.DeleteShoppingList).Methods("DELETE")
+	shoppingLists.HandleFunc("/{id}/export", shoppingListHandler.ExportShoppingList).Methods("GET")
+	shoppingLists.HandleFunc("/{id}/items/{itemId}", shoppingListHandler.CheckItem).Methods("PUT")
+
+	// Pantry routes (require authentication; pantries are private)
+	pantry := api.PathPrefix("/pantry").Subrouter()
+	pantry.Use(middleware.AuthMiddleware(userService))
+	pantry.HandleFunc("", pantryHandler.GetPantry).Methods("GET")
+	pantry.HandleFunc("", pantryHandler.AddPantryItem).Methods("POST")
+	pantry.HandleFunc("/{id}", pantryHandler.GetPantryItem).Methods("GET")
+	pantry.HandleFunc("/{id}", pantryHandler.UpdatePantryItem).Methods("PUT")
+	pantry.HandleFunc("/{id}", pantryHandler.DeletePantryItem).Methods("DELETE")
 
 	// Rating routes
 	ratings := api.PathPrefix("/recipes/{id}/ratings").Subrouter()
@@ -75,18 +229,29 @@ func main() {
 	protectedRatings.HandleFunc("", ratingHandler.CreateRating).Methods("POST")
 	protectedRatings.HandleFunc("/{ratingId}", ratingHandler.UpdateRating).Methods("PUT")
 	protectedRatings.HandleFunc("/{ratingId}", ratingHandler.DeleteRating).Methods("DELETE")
+	protectedRatings.HandleFunc("/{ratingId}/restore", ratingHandler.RestoreRating).Methods("POST")
 
 	// Search routes
 	search := api.PathPrefix("/search").Subrouter()
 	search.HandleFunc("/ingredient", searchHandler.SearchByIngredient).Methods("GET")
 	search.HandleFunc("/tag", searchHandler.SearchByTag).Methods("GET")
 	search.HandleFunc("/title", searchHandler.SearchByTitle).Methods("GET")
+	search.HandleFunc("/diet", searchHandler.SearchByDiet).Methods("GET")
+	search.HandleFunc("/calories", searchHandler.SearchByCalories).Methods("GET")
+	search.HandleFunc("/pantry", searchHandler.SearchByPantry).Methods("GET")
 	search.HandleFunc("/paginated", searchHandler.GetPaginatedRecipes).Methods("GET")
 
 	// Sort routes
 	sort := api.PathPrefix("/sort").Subrouter()
 	sort.HandleFunc("/recipes", sortHandler.SortRecipes).Methods("GET")
 
+	// Bulk export, cookbook and import routes
+	api.HandleFunc("/export", bulkHandler.ExportRecipes).Methods("GET")
+	api.HandleFunc("/export/epub", cookbookHandler.ExportEPUB).Methods("GET")
+	bulkImport := api.PathPrefix("/import").Subrouter()
+	bulkImport.Use(middleware.AuthMiddleware(userService))
+	bulkImport.HandleFunc("", bulkHandler.ImportRecipes).Methods("POST")
+
 	// Admin routes (require admin role)
 	admin := api.PathPrefix("/admin").Subrouter()
 	admin.Use(middleware.AuthMiddleware(userService))
@@ -98,3 +263,98 @@ func main() {
 	log.Printf("Server starting on port %s", port)
 	log.Fatal(http.ListenAndServe(port, router))
 }
+
+// trashRetention reads how long deleted items are kept from TRASH_RETENTION (e.g. "720h"),
+// falling back to the default retention window
+func trashRetention() time.Duration {
+	value := os.Getenv("TRASH_RETENTION")
+	if value == "" {
+		return services.DefaultTrashRetention
+	}
+
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention <= 0 {
+		log.Printf("Invalid TRASH_RETENTION %q, using %s", value, services.DefaultTrashRetention)
+		return services.DefaultTrashRetention
+	}
+	return retention
+}
+
+// nutrientTable loads the nutrient table from the CSV file named by NUTRIENT_TABLE,
+// falling back to the bundled table
+func nutrientTable() []models.Food {
+	path := os.Getenv("NUTRIENT_TABLE")
+	if path == "" {
+		return services.BundledNutrientTable()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Cannot open NUTRIENT_TABLE: %v", err)
+	}
+	defer file.Close()
+
+	foods, err := services.LoadNutrientTable(file)
+	if err != nil {
+		log.Fatalf("Cannot load NUTRIENT_TABLE %q: %v", path, err)
+	}
+	log.Printf("Loaded %d foods from %s", len(foods), path)
+	return foods
+}
+
+// substitutionRules loads the substitution rules from the JSON file named by SUBSTITUTIONS,
+// falling back to the bundled table
+func substitutionRules() []models.SubstitutionRule {
+	path := os.Getenv("SUBSTITUTIONS")
+	if path == "" {
+		return services.BundledSubstitutions()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Cannot open SUBSTITUTIONS: %v", err)
+	}
+	defer file.Close()
+
+	rules, err := services.LoadSubstitutions(file)
+	if err != nil {
+		log.Fatalf("Cannot load SUBSTITUTIONS %q: %v", path, err)
+	}
+	log.Printf("Loaded %d substitution rules from %s", len(rules), path)
+	return rules
+}
+
+// minShareLinkSecretBytes is the shortest SHARE_LINK_SECRET accepted, and the length of a generated one
+const minShareLinkSecretBytes = 32
+
+// shareLinkSecret reads the key share links are signed with from SHARE_LINK_SECRET. Without one a
+// random key is made, so links handed out stop working when the server restarts.
+func shareLinkSecret() []byte {
+	if value := os.Getenv("SHARE_LINK_SECRET"); value != "" {
+		if len(value) < minShareLinkSecretBytes {
+			log.Fatalf("SHARE_LINK_SECRET must be at least %d bytes long", minShareLinkSecretBytes)
+		}
+		return []byte(value)
+	}
+
+	secret := make([]byte, minShareLinkSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Cannot generate a share link secret: %v", err)
+	}
+	log.Printf("SHARE_LINK_SECRET is not set; share links will stop working when the server restarts")
+	return secret
+}
+
+// imageBlobStore opens the directory named by IMAGE_DIR, or "uploads", to store recipe images in
+func imageBlobStore() repositories.BlobStore {
+	dir := os.Getenv("IMAGE_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+
+	store, err := repositories.NewFileSystemBlobStore(dir)
+	if err != nil {
+		log.Fatalf("Cannot open IMAGE_DIR %q: %v", dir, err)
+	}
+	return store
+}
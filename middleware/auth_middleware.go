@@ -13,7 +13,7 @@ type ContextKey string
 
 // Context keys
 const (
-	UserIDKey ContextKey = "userID"
+	UserIDKey   ContextKey = "userID"
 	UserRoleKey ContextKey = "userRole"
 )
 
@@ -54,6 +54,35 @@ func AuthMiddleware(userService *services.UserService) func(http.Handler) http.H
 	}
 }
 
+// OptionalAuthMiddleware creates a middleware that adds the user ID and role to the context
+// when a valid JWT token is present, and lets every other request through as anonymous.
+// Rejecting missing or bad tokens is left to AuthMiddleware on the protected routes.
+func OptionalAuthMiddleware(userService *services.UserService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A malformed, expired or otherwise invalid token counts as no token, so a client
+			// holding a stale one can still log in again and read public routes
+			authorization := r.Header.Get("Authorization")
+			if !strings.HasPrefix(authorization, "Bearer ") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := userService.ValidateToken(strings.TrimPrefix(authorization, "Bearer "))
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Add user ID and role to context
+			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // RoleMiddleware creates a middleware that checks if the user has the required role
 func RoleMiddleware(requiredRole string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -69,4 +98,4 @@ func RoleMiddleware(requiredRole string) func(http.Handler) http.Handler {
 			next.ServeHTTP(w, r)
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"playground/handlers"
+	"playground/middleware"
+	"playground/models"
+	"playground/repositories"
+	"playground/services"
+)
+
+// TestOptionalAuthMiddleware tests that public routes treat a stale token as no token
+func TestOptionalAuthMiddleware(t *testing.T) {
+	// Create the service with a repository
+	userService := services.NewUserService(repositories.NewInMemoryUserRepository())
+	if _, err := userService.CreateUser(models.UserInput{Username: "cook", Email: "cook@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api").Subrouter()
+	api.Use(middleware.OptionalAuthMiddleware(userService))
+	api.HandleFunc("/auth/login", handlers.NewAuthHandler(userService).Login).Methods("POST")
+	protected := api.PathPrefix("/me").Subrouter()
+	protected.Use(middleware.AuthMiddleware(userService))
+	protected.HandleFunc("", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// A token that expired yesterday, signed with a key the server no longer uses
+	stale, err := jwt.NewWithClaims(jwt.SigningMethodHS256, services.TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-24 * time.Hour))},
+		UserID:           "user-1",
+	}).SignedString([]byte("old-secret"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	for _, authorization := range []string{"Bearer " + stale, "Bearer not-a-token", "Basic Y29vazpzZWNyZXQ="} {
+		request := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"username": "cook", "password": "secret"}`))
+		request.Header.Set("Authorization", authorization)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"token"`) {
+			t.Errorf("Expected login with %q to succeed, but got %d %s", authorization, response.Code, response.Body.String())
+		}
+	}
+
+	// Protected routes still reject the stale token
+	request := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+	request.Header.Set("Authorization", "Bearer "+stale)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	if response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a protected route, but got %d", response.Code)
+	}
+}
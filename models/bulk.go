@@ -0,0 +1,64 @@
+package models
+
+import "encoding/json"
+
+// RecipeExport is one line of a bulk export: a recipe with its ratings
+type RecipeExport struct {
+	Recipe
+	Ratings []Rating `json:"ratings"`
+}
+
+// BulkRecord is one recipe read by a bulk import. Exported lines can be imported as they are:
+// fields such as the ID, author, diet and ratings are ignored.
+type BulkRecord struct {
+	RecipeInput
+	// Status defaults to draft; published and archived records must be publishable
+	Status RecipeStatus `json:"status,omitempty"`
+}
+
+// UnmarshalJSON reads the status next to the recipe input, whose own UnmarshalJSON would
+// otherwise be promoted and skip it
+func (r *BulkRecord) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &r.RecipeInput); err != nil {
+		return err
+	}
+	var status struct {
+		Status RecipeStatus `json:"status"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return err
+	}
+	r.Status = status.Status
+	return nil
+}
+
+// BulkImportResult is a record a bulk import created, or would create on a dry run
+type BulkImportResult struct {
+	Line  int    `json:"line"`
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title"`
+}
+
+// BulkImportError is a record a bulk import rejected, with the line it starts on
+type BulkImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// BulkImportReport summarizes a bulk import; on a dry run nothing is saved. When the import stops
+// before the end of the file, Error says why and the report covers the records read until then.
+type BulkImportReport struct {
+	DryRun   bool               `json:"dryRun"`
+	Total    int                `json:"total"`
+	Imported int                `json:"imported"`
+	Failed   int                `json:"failed"`
+	Recipes  []BulkImportResult `json:"recipes"`
+	Errors   []BulkImportError  `json:"errors"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// ExportFilter narrows a bulk export to recipes with a tag or by an author; empty fields match all
+type ExportFilter struct {
+	Tag      string
+	AuthorID string
+}
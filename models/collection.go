@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// CollectionVisibility controls who can open a collection
+type CollectionVisibility string
+
+// Collection visibility levels
+const (
+	CollectionPrivate CollectionVisibility = "private"
+	CollectionShared  CollectionVisibility = "shared"
+)
+
+// IsValid reports whether the visibility is one of the known levels
+func (v CollectionVisibility) IsValid() bool {
+	return v == CollectionPrivate || v == CollectionShared
+}
+
+// UnmarshalJSON rejects unknown visibility levels
+func (v *CollectionVisibility) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	visibility := CollectionVisibility(value)
+	if visibility != "" && !visibility.IsValid() {
+		return fmt.Errorf("unknown visibility %q", value)
+	}
+
+	*v = visibility
+	return nil
+}
+
+// Collection is a named, ordered group of recipes such as "Weeknight" or a cookbook
+type Collection struct {
+	ID          string               `json:"id"`
+	OwnerID     string               `json:"ownerId"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Visibility  CollectionVisibility `json:"visibility"`
+	// RecipeIDs are in the order the owner arranged them
+	RecipeIDs []string  `json:"recipeIds"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CollectionInput represents the data needed to create or update a collection
+type CollectionInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Visibility defaults to private on create and is left unchanged on update when empty
+	Visibility CollectionVisibility `json:"visibility,omitempty"`
+}
+
+// CollectionRecipeInput adds a recipe to a collection, at the end unless a position is given
+type CollectionRecipeInput struct {
+	RecipeID string `json:"recipeId"`
+	Position *int   `json:"position,omitempty"`
+}
+
+// CollectionOrderInput lists recipe IDs of a collection in their new order
+type CollectionOrderInput struct {
+	RecipeIDs []string `json:"recipeIds"`
+}
+
+// CollectionDetail is a collection with the recipes in it that the caller may see
+type CollectionDetail struct {
+	Collection
+	Recipes []Recipe `json:"recipes"`
+}
+
+// NewCollection creates a new empty Collection with the given input, generated ID and owner
+func NewCollection(id string, ownerID string, input CollectionInput) Collection {
+	now := time.Now()
+
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = CollectionPrivate
+	}
+
+	return Collection{
+		ID:          id,
+		OwnerID:     ownerID,
+		Name:        input.Name,
+		Description: input.Description,
+		Visibility:  visibility,
+		RecipeIDs:   make([]string, 0),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// UpdateCollection creates a new Collection with updated fields but preserves the original ID,
+// owner, recipes, and creation time
+func UpdateCollection(original Collection, input CollectionInput) Collection {
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = original.Visibility
+	}
+
+	return Collection{
+		ID:          original.ID,
+		OwnerID:     original.OwnerID,
+		Name:        input.Name,
+		Description: input.Description,
+		Visibility:  visibility,
+		RecipeIDs:   original.RecipeIDs,
+		CreatedAt:   original.CreatedAt,
+		UpdatedAt:   time.Now(),
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+// CookbookSelection chooses the recipes of a cookbook by exactly one of IDs, tag or collection
+type CookbookSelection struct {
+	IDs          []string
+	Tag          string
+	CollectionID string
+	// Title names the book; it defaults to the collection's name or the tag
+	Title string
+}
+
+// Cookbook is a titled set of recipes, in reading order
+type Cookbook struct {
+	Title   string   `json:"title"`
+	Recipes []Recipe `json:"recipes"`
+}
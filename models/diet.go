@@ -0,0 +1,80 @@
+package models
+
+// Allergen is one of the 14 major food allergens
+type Allergen string
+
+// Major allergens
+const (
+	AllergenCelery      Allergen = "celery"
+	AllergenGluten      Allergen = "gluten"
+	AllergenCrustaceans Allergen = "crustaceans"
+	AllergenEggs        Allergen = "eggs"
+	AllergenFish        Allergen = "fish"
+	AllergenLupin       Allergen = "lupin"
+	AllergenMilk        Allergen = "milk"
+	AllergenMolluscs    Allergen = "molluscs"
+	AllergenMustard     Allergen = "mustard"
+	AllergenTreeNuts    Allergen = "tree nuts"
+	AllergenPeanuts     Allergen = "peanuts"
+	AllergenSesame      Allergen = "sesame"
+	AllergenSoy         Allergen = "soy"
+	AllergenSulphites   Allergen = "sulphites"
+)
+
+// AllAllergens lists every major allergen in display order
+var AllAllergens = []Allergen{
+	AllergenCelery, AllergenGluten, AllergenCrustaceans, AllergenEggs, AllergenFish,
+	AllergenLupin, AllergenMilk, AllergenMolluscs, AllergenMustard, AllergenTreeNuts,
+	AllergenPeanuts, AllergenSesame, AllergenSoy, AllergenSulphites,
+}
+
+// DietaryLabel is a diet a recipe is suitable for
+type DietaryLabel string
+
+// Dietary labels
+const (
+	LabelVegan      DietaryLabel = "vegan"
+	LabelVegetarian DietaryLabel = "vegetarian"
+	LabelGlutenFree DietaryLabel = "gluten-free"
+	LabelDairyFree  DietaryLabel = "dairy-free"
+	LabelNutFree    DietaryLabel = "nut-free"
+)
+
+// AllDietaryLabels lists every dietary label in display order
+var AllDietaryLabels = []DietaryLabel{
+	LabelVegan, LabelVegetarian, LabelGlutenFree, LabelDairyFree, LabelNutFree,
+}
+
+// TagConflict is a hand-entered tag that contradicts the labels derived from the ingredients
+type TagConflict struct {
+	Tag    string `json:"tag"`
+	Reason string `json:"reason"`
+}
+
+// DietInfo holds the allergens and dietary labels derived from a recipe's ingredients.
+// It is computed whenever the recipe is saved and cannot be set by clients.
+type DietInfo struct {
+	Allergens []Allergen     `json:"allergens"`
+	Labels    []DietaryLabel `json:"labels"`
+	Conflicts []TagConflict  `json:"conflicts,omitempty"`
+}
+
+// HasLabel reports whether the recipe was found suitable for a diet
+func (d DietInfo) HasLabel(label DietaryLabel) bool {
+	for _, l := range d.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAllergen reports whether any ingredient contains an allergen
+func (d DietInfo) HasAllergen(allergen Allergen) bool {
+	for _, a := range d.Allergens {
+		if a == allergen {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// Favorite marks a recipe a user wants to find again
+type Favorite struct {
+	UserID    string    `json:"userId"`
+	RecipeID  string    `json:"recipeId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RecipeNote is a private note a user keeps on a recipe, such as "used half the sugar"
+type RecipeNote struct {
+	RecipeID  string    `json:"recipeId"`
+	UserID    string    `json:"userId"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RecipeNoteInput represents the data needed to save a recipe note
+type RecipeNoteInput struct {
+	Text string `json:"text"`
+}
+
+// PersonalRecipe is a recipe with the state the authenticated user keeps on it
+type PersonalRecipe struct {
+	Recipe
+	FavoriteCount int         `json:"favoriteCount"`
+	Favorite      bool        `json:"favorite,omitempty"`
+	Note          *RecipeNote `json:"note,omitempty"`
+}
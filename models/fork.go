@@ -0,0 +1,26 @@
+package models
+
+// ForkReference points at the recipe and revision a fork was copied from
+type ForkReference struct {
+	RecipeID string `json:"recipeId"`
+	Revision int    `json:"revision"`
+}
+
+// LineageEntry is one ancestor in a recipe's fork lineage. Recipe is nil when the
+// ancestor is in the trash or hidden from the caller.
+type LineageEntry struct {
+	RecipeID string  `json:"recipeId"`
+	Revision int     `json:"revision"`
+	Recipe   *Recipe `json:"recipe,omitempty"`
+}
+
+// NewFork creates a new draft Recipe owned by authorID with the content of parent
+// and a reference to the parent's current revision
+func NewFork(id string, authorID string, parent Recipe) Recipe {
+	fork := NewRecipe(id, authorID, parent.Input())
+	fork.ForkedFrom = &ForkReference{
+		RecipeID: parent.ID,
+		Revision: parent.Revision,
+	}
+	return fork
+}
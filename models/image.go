@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// ImageSize names one of the renditions stored for every uploaded image
+type ImageSize string
+
+// Stored image renditions
+const (
+	ImageOriginal  ImageSize = "original"
+	ImageMedium    ImageSize = "medium"
+	ImageThumbnail ImageSize = "thumbnail"
+)
+
+// ImageSizes lists every stored rendition, largest first
+var ImageSizes = []ImageSize{ImageOriginal, ImageMedium, ImageThumbnail}
+
+// ImageVariant is a single stored rendition of an image
+type ImageVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Bytes  int    `json:"bytes"`
+	URL    string `json:"url"`
+	// Key locates the rendition in the blob store
+	Key string `json:"-"`
+}
+
+// RecipeImage is a photo of a recipe, stored re-encoded without metadata in several sizes
+type RecipeImage struct {
+	ID          string                     `json:"id"`
+	ContentType string                     `json:"contentType"`
+	Caption     string                     `json:"caption,omitempty"`
+	Variants    map[ImageSize]ImageVariant `json:"variants"`
+	UploadedBy  string                     `json:"uploadedBy"`
+	CreatedAt   time.Time                  `json:"createdAt"`
+}
+
+// ImageOrderInput lists every image ID of a recipe in the new display order
+type ImageOrderInput struct {
+	ImageIDs []string `json:"imageIds"`
+}
+
+// CoverImage returns the designated cover image of the recipe
+func (r Recipe) CoverImage() (RecipeImage, bool) {
+	for _, image := range r.Images {
+		if image.ID == r.CoverImageID {
+			return image, true
+		}
+	}
+	return RecipeImage{}, false
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Ingredient represents a single structured ingredient line of a recipe
+type Ingredient struct {
+	Quantity *Quantity `json:"quantity,omitempty"`
+	Unit     string    `json:"unit,omitempty"`
+	Name     string    `json:"name"`
+	Note     string    `json:"note,omitempty"`
+	Optional bool      `json:"optional,omitempty"`
+	// RecipeID makes the ingredient a sub-recipe; Quantity counts batches of it, or servings
+	// when Unit is "serving"
+	RecipeID string `json:"recipeId,omitempty"`
+}
+
+// ingredientFields mirrors Ingredient without its JSON methods
+type ingredientFields Ingredient
+
+// optionalMarkers are the phrases that flag an ingredient line as optional
+var optionalMarkers = []string{"(optional)", ", optional", "optional:"}
+
+// ParseIngredient turns a free-text line such as "1 1/2 cups flour, sifted" into an Ingredient
+func ParseIngredient(line string) Ingredient {
+	var ingredient Ingredient
+	text := strings.TrimSpace(line)
+
+	// Strip optional markers wherever they appear
+	lower := strings.ToLower(text)
+	for _, marker := range optionalMarkers {
+		if i := strings.Index(lower, marker); i >= 0 {
+			ingredient.Optional = true
+			text = strings.TrimSpace(text[:i] + text[i+len(marker):])
+			lower = strings.ToLower(text)
+		}
+	}
+
+	fields := strings.Fields(text)
+
+	// Leading quantity: a number optionally followed by a fraction such as "1 1/2";
+	// ranges like "2-3" keep the lower bound
+	consumed := 0
+	var quantity Quantity
+	for consumed < len(fields) && consumed < 2 {
+		token := fields[consumed]
+		if low, _, isRange := strings.Cut(token, "-"); isRange && low != "" {
+			token = low
+		}
+		q, err := ParseQuantity(token)
+		if err != nil || (consumed == 1 && q.Float64() >= 1) {
+			break
+		}
+		quantity = quantity.Add(q)
+		consumed++
+	}
+	if consumed > 0 {
+		ingredient.Quantity = &quantity
+	}
+	fields = fields[consumed:]
+
+	// Package sizes such as "(14 oz)" become part of the note
+	var notes []string
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "(") {
+		end := 0
+		for end < len(fields) && !strings.HasSuffix(fields[end], ")") {
+			end++
+		}
+		if end < len(fields) {
+			notes = append(notes, strings.Trim(strings.Join(fields[:end+1], " "), "()"))
+			fields = fields[end+1:]
+		}
+	}
+
+	// Unit: try two-word units like "fl oz" before single words, and never consume the last word
+	if len(fields) > 2 {
+		if unit, ok := NormalizeUnit(fields[0] + " " + fields[1]); ok {
+			ingredient.Unit = unit
+			fields = fields[2:]
+		}
+	}
+	if ingredient.Unit == "" && len(fields) > 1 {
+		if unit, ok := NormalizeUnit(fields[0]); ok {
+			ingredient.Unit = unit
+			fields = fields[1:]
+		}
+	}
+	if len(fields) > 1 && strings.EqualFold(fields[0], "of") {
+		fields = fields[1:]
+	}
+
+	// Name and preparation note are separated by the first comma
+	name, note, _ := strings.Cut(strings.Join(fields, " "), ",")
+	ingredient.Name = strings.TrimSpace(name)
+	if note = strings.TrimSpace(note); note != "" {
+		notes = append(notes, note)
+	}
+	ingredient.Note = strings.Join(notes, ", ")
+
+	return ingredient
+}
+
+// ParseIngredients parses a list of free-text ingredient lines
+func ParseIngredients(lines []string) []Ingredient {
+	result := make([]Ingredient, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, ParseIngredient(line))
+	}
+	return result
+}
+
+// IsSubRecipe reports whether the ingredient refers to another recipe
+func (i Ingredient) IsSubRecipe() bool {
+	return i.RecipeID != ""
+}
+
+// String renders the ingredient back into a readable line
+func (i Ingredient) String() string {
+	parts := make([]string, 0, 3)
+	if i.Quantity != nil {
+		parts = append(parts, i.Quantity.String())
+	}
+	if i.Unit != "" {
+		plural := i.Quantity != nil && i.Quantity.Float64() > 1
+		parts = append(parts, UnitLabel(i.Unit, plural))
+	}
+	parts = append(parts, i.Name)
+
+	line := strings.Join(parts, " ")
+	if i.Note != "" {
+		line += ", " + i.Note
+	}
+	if i.Optional {
+		line += " (optional)"
+	}
+	return line
+}
+
+// UnmarshalJSON accepts either a free-text line, which is parsed, or a structured object
+func (i *Ingredient) UnmarshalJSON(data []byte) error {
+	var line string
+	if err := json.Unmarshal(data, &line); err == nil {
+		*i = ParseIngredient(line)
+		return nil
+	}
+
+	var fields ingredientFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if unit, ok := NormalizeUnit(fields.Unit); ok {
+		fields.Unit = unit
+	}
+	*i = Ingredient(fields)
+	return nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestParseIngredient tests parsing free-text ingredient lines
+func TestParseIngredient(t *testing.T) {
+	tests := []struct {
+		line     string
+		quantity string
+		unit     string
+		name     string
+		note     string
+		optional bool
+	}{
+		{"1 1/2 cups flour, sifted", "1 1/2", UnitCup, "flour", "sifted", false},
+		{"2 tbsp olive oil", "2", UnitTablespoon, "olive oil", "", false},
+		{"½ tsp salt", "1/2", UnitTeaspoon, "salt", "", false},
+		{"1½ lbs ground beef", "1 1/2", UnitPound, "ground beef", "", false},
+		{"3 large eggs", "3", "", "large eggs", "", false},
+		{"250 g butter, softened", "250", UnitGram, "butter", "softened", false},
+		{"0.5 l milk", "1/2", UnitLiter, "milk", "", false},
+		{"2 fl oz cream", "2", UnitFluidOunce, "cream", "", false},
+		{"1 (14 oz) can tomatoes, drained", "1", "can", "tomatoes", "14 oz, drained", false},
+		{"2-3 cloves garlic, minced", "2", "clove", "garlic", "minced", false},
+		{"1 cup of sugar", "1", UnitCup, "sugar", "", false},
+		{"fresh parsley (optional)", "", "", "fresh parsley", "", true},
+		{"salt to taste", "", "", "salt to taste", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.line, func(t *testing.T) {
+			ingredient := ParseIngredient(tc.line)
+
+			quantity := ""
+			if ingredient.Quantity != nil {
+				quantity = ingredient.Quantity.String()
+			}
+			if quantity != tc.quantity {
+				t.Errorf("Expected quantity '%s', but got '%s'", tc.quantity, quantity)
+			}
+			if ingredient.Unit != tc.unit {
+				t.Errorf("Expected unit '%s', but got '%s'", tc.unit, ingredient.Unit)
+			}
+			if ingredient.Name != tc.name {
+				t.Errorf("Expected name '%s', but got '%s'", tc.name, ingredient.Name)
+			}
+			if ingredient.Note != tc.note {
+				t.Errorf("Expected note '%s', but got '%s'", tc.note, ingredient.Note)
+			}
+			if ingredient.Optional != tc.optional {
+				t.Errorf("Expected optional %v, but got %v", tc.optional, ingredient.Optional)
+			}
+		})
+	}
+}
+
+// TestRecipeInputIngredientsJSON tests that string and structured ingredients both decode
+func TestRecipeInputIngredientsJSON(t *testing.T) {
+	payload := `{
+		"title": "Pancakes",
+		"ingredients": [
+			"1 1/2 cups flour, sifted",
+			{"quantity": 2, "unit": "tablespoons", "name": "sugar"},
+			{"quantity": "3/4", "unit": "cup", "name": "milk", "optional": true}
+		]
+	}`
+
+	var input RecipeInput
+	if err := json.Unmarshal([]byte(payload), &input); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if len(input.Ingredients) != 3 {
+		t.Fatalf("Expected 3 ingredients, but got %d", len(input.Ingredients))
+	}
+
+	expected := []string{
+		"1 1/2 cups flour, sifted",
+		"2 tbsp sugar",
+		"3/4 cup milk (optional)",
+	}
+	for i, line := range expected {
+		if got := input.Ingredients[i].String(); got != line {
+			t.Errorf("Expected ingredient %d to be '%s', but got '%s'", i, line, got)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dateLayout is how dates are written in JSON and query parameters
+const dateLayout = "2006-01-02"
+
+// Date is a calendar day without a time of day or time zone
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the calendar day of t in its own location
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses a date written as YYYY-MM-DD
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", value)
+	}
+	return Date{t}, nil
+}
+
+// AddDays returns the date the given number of days later, or earlier when days is negative
+func (d Date) AddDays(days int) Date {
+	return Date{d.Time.AddDate(0, 0, days)}
+}
+
+// DaysSince returns the number of days from other to d
+func (d Date) DaysSince(other Date) int {
+	return int(d.Time.Sub(other.Time).Hours() / 24)
+}
+
+// String returns the date as YYYY-MM-DD
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+// MarshalJSON writes the date as YYYY-MM-DD
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads a date written as YYYY-MM-DD
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	date, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// MealSlot is the meal of the day an entry is planned for
+type MealSlot string
+
+// Meal slots in the order they are eaten
+const (
+	SlotBreakfast MealSlot = "breakfast"
+	SlotLunch     MealSlot = "lunch"
+	SlotDinner    MealSlot = "dinner"
+	SlotSnack     MealSlot = "snack"
+)
+
+// MealSlots lists every meal slot in the order they are eaten
+var MealSlots = []MealSlot{SlotBreakfast, SlotLunch, SlotDinner, SlotSnack}
+
+// IsValid reports whether the slot is one of the known meal slots
+func (s MealSlot) IsValid() bool {
+	return s.order() >= 0
+}
+
+// order returns the position of the slot in the day, or -1 for unknown slots
+func (s MealSlot) order() int {
+	for i, slot := range MealSlots {
+		if slot == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// MealPlan is a user's calendar of planned meals
+type MealPlan struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"ownerId"`
+	Name    string `json:"name"`
+	// Entries are ordered by date, then meal slot
+	Entries   []MealPlanEntry `json:"entries"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// MealPlanInput represents the data needed to create or rename a meal plan
+type MealPlanInput struct {
+	Name string `json:"name"`
+}
+
+// MealPlanEntry is a recipe planned for a meal on a day
+type MealPlanEntry struct {
+	ID       string   `json:"id"`
+	Date     Date     `json:"date"`
+	Slot     MealSlot `json:"slot"`
+	RecipeID string   `json:"recipeId"`
+	// Servings overrides the recipe's own servings; zero means the recipe as written
+	Servings int `json:"servings,omitempty"`
+}
+
+// MealPlanEntryInput represents the data needed to add or change a meal plan entry
+type MealPlanEntryInput struct {
+	Date     Date     `json:"date"`
+	Slot     MealSlot `json:"slot"`
+	RecipeID string   `json:"recipeId"`
+	Servings int      `json:"servings,omitempty"`
+}
+
+// CopyWeekInput copies the seven days starting at From to the seven days starting at To
+type CopyWeekInput struct {
+	From Date `json:"from"`
+	To   Date `json:"to"`
+	// Replace clears the target week first instead of adding to it
+	Replace bool `json:"replace,omitempty"`
+}
+
+// RepeatWeekInput repeats the seven days starting at Week in each of the following Times weeks
+type RepeatWeekInput struct {
+	Week    Date `json:"week"`
+	Times   int  `json:"times"`
+	Replace bool `json:"replace,omitempty"`
+}
+
+// DaySummary adds up the time needed for the meals planned on a day, in minutes
+type DaySummary struct {
+	Date      Date `json:"date"`
+	Meals     int  `json:"meals"`
+	PrepTime  int  `json:"prepTime"`
+	CookTime  int  `json:"cookTime"`
+	TotalTime int  `json:"totalTime"`
+	// Unavailable counts meals whose recipe was deleted or can no longer be seen
+	Unavailable int `json:"unavailable,omitempty"`
+}
+
+// NewMealPlan creates a new empty MealPlan with the given input, generated ID and owner
+func NewMealPlan(id string, ownerID string, input MealPlanInput) MealPlan {
+	now := time.Now()
+	return MealPlan{
+		ID:        id,
+		OwnerID:   ownerID,
+		Name:      input.Name,
+		Entries:   make([]MealPlanEntry, 0),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// UpdateMealPlan creates a new MealPlan with updated fields but preserves the original ID,
+// owner, entries, and creation time
+func UpdateMealPlan(original MealPlan, input MealPlanInput) MealPlan {
+	return MealPlan{
+		ID:        original.ID,
+		OwnerID:   original.OwnerID,
+		Name:      input.Name,
+		Entries:   original.Entries,
+		CreatedAt: original.CreatedAt,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Before reports whether the entry comes before other in a plan: earlier days first,
+// then meals in the order they are eaten
+func (e MealPlanEntry) Before(other MealPlanEntry) bool {
+	if !e.Date.Equal(other.Date.Time) {
+		return e.Date.Before(other.Date.Time)
+	}
+	return e.Slot.order() < other.Slot.order()
+}
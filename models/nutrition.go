@@ -0,0 +1,82 @@
+package models
+
+import (
+	"math"
+)
+
+// Nutrients holds the nutrition values tracked for foods and recipes. Energy is in kilocalories,
+// sodium in milligrams and everything else in grams.
+type Nutrients struct {
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Fat           float64 `json:"fat"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fiber         float64 `json:"fiber"`
+	Sodium        float64 `json:"sodium"`
+}
+
+// Add returns the sum of two sets of nutrients
+func (n Nutrients) Add(other Nutrients) Nutrients {
+	return Nutrients{
+		Calories:      n.Calories + other.Calories,
+		Protein:       n.Protein + other.Protein,
+		Fat:           n.Fat + other.Fat,
+		Carbohydrates: n.Carbohydrates + other.Carbohydrates,
+		Fiber:         n.Fiber + other.Fiber,
+		Sodium:        n.Sodium + other.Sodium,
+	}
+}
+
+// Scale returns the nutrients multiplied by factor
+func (n Nutrients) Scale(factor float64) Nutrients {
+	return Nutrients{
+		Calories:      n.Calories * factor,
+		Protein:       n.Protein * factor,
+		Fat:           n.Fat * factor,
+		Carbohydrates: n.Carbohydrates * factor,
+		Fiber:         n.Fiber * factor,
+		Sodium:        n.Sodium * factor,
+	}
+}
+
+// Round returns the nutrients rounded to one decimal place for display
+func (n Nutrients) Round() Nutrients {
+	round := func(value float64) float64 {
+		return math.Round(value*10) / 10
+	}
+	return Nutrients{
+		Calories:      round(n.Calories),
+		Protein:       round(n.Protein),
+		Fat:           round(n.Fat),
+		Carbohydrates: round(n.Carbohydrates),
+		Fiber:         round(n.Fiber),
+		Sodium:        round(n.Sodium),
+	}
+}
+
+// Food is an entry in the nutrient table with its nutrients per 100 grams
+type Food struct {
+	Name    string    `json:"name"`
+	Per100g Nutrients `json:"per100g"`
+	// PieceGrams is the weight of one piece, e.g. one egg, used for ingredients counted without a unit
+	PieceGrams float64 `json:"pieceGrams,omitempty"`
+	// Density in grams per milliliter, used for ingredients measured by volume
+	Density float64 `json:"density,omitempty"`
+}
+
+// UnmatchedIngredient is an ingredient left out of a nutrition calculation and the reason why
+type UnmatchedIngredient struct {
+	Ingredient string `json:"ingredient"`
+	Reason     string `json:"reason"`
+}
+
+// NutritionFacts is the computed nutrition of a recipe. Coverage is the fraction of ingredients
+// that were matched against the nutrient table; unmatched ingredients are not counted in the totals.
+type NutritionFacts struct {
+	RecipeID   string                `json:"recipeId"`
+	Servings   int                   `json:"servings"`
+	Total      Nutrients             `json:"total"`
+	PerServing *Nutrients            `json:"perServing,omitempty"`
+	Coverage   float64               `json:"coverage"`
+	Unmatched  []UnmatchedIngredient `json:"unmatched"`
+}
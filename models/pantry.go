@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// PantryItem is an ingredient a user has at home
+type PantryItem struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"ownerId"`
+	Name    string `json:"name"`
+	// Quantity is nil when the amount is not tracked, e.g. for a jar of salt that never runs out
+	Quantity *Quantity `json:"quantity,omitempty"`
+	Unit     string    `json:"unit,omitempty"`
+	// ExpiresOn is nil for items that keep
+	ExpiresOn *Date     `json:"expiresOn,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// PantryItemInput represents the data needed to add or change a pantry item
+type PantryItemInput struct {
+	Name      string    `json:"name"`
+	Quantity  *Quantity `json:"quantity,omitempty"`
+	Unit      string    `json:"unit,omitempty"`
+	ExpiresOn *Date     `json:"expiresOn,omitempty"`
+}
+
+// PantryMatch is a recipe found by what is in the pantry, with how much of it the pantry covers
+type PantryMatch struct {
+	Recipe
+	// Coverage is the share of the recipe's required ingredients the pantry has enough of, from 0 to 1
+	Coverage float64 `json:"coverage"`
+	// Score ranks the results: the coverage plus a boost for each pantry item nearing expiry the recipe uses
+	Score float64 `json:"score"`
+	// Missing lists the required ingredients the pantry lacks or has too little of
+	Missing []Ingredient `json:"missing"`
+	// Expiring names the pantry items nearing expiry the recipe uses up
+	Expiring []string `json:"expiring"`
+}
+
+// NewPantryItem creates a new PantryItem with the given input, generated ID and owner
+func NewPantryItem(id string, ownerID string, input PantryItemInput) PantryItem {
+	now := time.Now()
+	return PantryItem{
+		ID:        id,
+		OwnerID:   ownerID,
+		Name:      input.Name,
+		Quantity:  input.Quantity,
+		Unit:      input.Unit,
+		ExpiresOn: input.ExpiresOn,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// UpdatePantryItem creates a new PantryItem with updated fields but preserves the original ID,
+// owner, and creation time
+func UpdatePantryItem(original PantryItem, input PantryItemInput) PantryItem {
+	return PantryItem{
+		ID:        original.ID,
+		OwnerID:   original.OwnerID,
+		Name:      input.Name,
+		Quantity:  input.Quantity,
+		Unit:      input.Unit,
+		ExpiresOn: input.ExpiresOn,
+		CreatedAt: original.CreatedAt,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// ExpiresWithin reports whether the item expires between today and days from today, inclusive
+func (i PantryItem) ExpiresWithin(today Date, days int) bool {
+	return i.ExpiresOn != nil && !i.IsExpired(today) && !i.ExpiresOn.After(today.AddDays(days).Time)
+}
+
+// IsExpired reports whether the item's expiry date is before today
+func (i PantryItem) IsExpired(today Date) bool {
+	return i.ExpiresOn != nil && i.ExpiresOn.Before(today.Time)
+}
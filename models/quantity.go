@@ -0,0 +1,252 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxQuantity is the largest amount a quantity can be parsed from; it keeps products of two
+// quantities in thousandths within int64
+const MaxQuantity = 1e9
+
+// Quantity represents an exact rational amount such as 1 1/2 or 3/4
+type Quantity struct {
+	Num int64
+	Den int64
+}
+
+// unicodeFractions maps vulgar fraction characters to their values
+var unicodeFractions = map[rune]Quantity{
+	'½': {1, 2},
+	'⅓': {1, 3},
+	'⅔': {2, 3},
+	'¼': {1, 4},
+	'¾': {3, 4},
+	'⅕': {1, 5},
+	'⅖': {2, 5},
+	'⅗': {3, 5},
+	'⅘': {4, 5},
+	'⅙': {1, 6},
+	'⅚': {5, 6},
+	'⅛': {1, 8},
+	'⅜': {3, 8},
+	'⅝': {5, 8},
+	'⅞': {7, 8},
+}
+
+// NewQuantity creates a Quantity reduced to lowest terms with a positive denominator
+func NewQuantity(num, den int64) Quantity {
+	if den == 0 {
+		return Quantity{0, 1}
+	}
+	if den < 0 {
+		num, den = -num, -den
+	}
+	g := gcd(abs(num), den)
+	if g > 1 {
+		num, den = num/g, den/g
+	}
+	return Quantity{num, den}
+}
+
+// WholeQuantity creates a Quantity for a whole number
+func WholeQuantity(n int64) Quantity {
+	return Quantity{n, 1}
+}
+
+// QuantityFromFloat converts a decimal value to a Quantity, exact to three decimal places. Values
+// beyond MaxQuantity either way are clamped to it and NaN is zero.
+func QuantityFromFloat(f float64) Quantity {
+	if math.IsNaN(f) {
+		return WholeQuantity(0)
+	}
+	f = math.Max(-MaxQuantity, math.Min(f, MaxQuantity))
+	return NewQuantity(int64(math.Round(f*1000)), 1000)
+}
+
+// ParseQuantity parses amounts like "2", "1.5", "3/4", "1 1/2", "½" and "1½"
+func ParseQuantity(s string) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Quantity{}, errors.New("empty quantity")
+	}
+
+	total := WholeQuantity(0)
+	for _, field := range strings.Fields(s) {
+		q, err := parseQuantityToken(field)
+		if err != nil {
+			return Quantity{}, err
+		}
+		total = total.Add(q)
+	}
+	if total.Float64() > MaxQuantity {
+		return Quantity{}, fmt.Errorf("quantity %q is too large", s)
+	}
+	return total, nil
+}
+
+// parseQuantityToken parses a single whitespace-free quantity token
+func parseQuantityToken(token string) (Quantity, error) {
+	// Trailing unicode fraction, e.g. "1½" or "¾"
+	runes := []rune(token)
+	if frac, ok := unicodeFractions[runes[len(runes)-1]]; ok {
+		if len(runes) == 1 {
+			return frac, nil
+		}
+		whole, err := strconv.ParseInt(string(runes[:len(runes)-1]), 10, 64)
+		if err != nil || whole < 0 || whole > MaxQuantity {
+			return Quantity{}, fmt.Errorf("invalid quantity %q", token)
+		}
+		return WholeQuantity(whole).Add(frac), nil
+	}
+
+	// Simple fraction, e.g. "3/4"
+	if num, den, ok := strings.Cut(token, "/"); ok {
+		n, err1 := strconv.ParseInt(num, 10, 64)
+		d, err2 := strconv.ParseInt(den, 10, 64)
+		if err1 != nil || err2 != nil || n < 0 || d <= 0 || float64(n)/float64(d) > MaxQuantity {
+			return Quantity{}, fmt.Errorf("invalid quantity %q", token)
+		}
+		return NewQuantity(n, d), nil
+	}
+
+	// Whole number or decimal, e.g. "2" or "1.25"
+	f, err := strconv.ParseFloat(token, 64)
+	if err != nil || f < 0 || f > MaxQuantity || math.IsNaN(f) {
+		return Quantity{}, fmt.Errorf("invalid quantity %q", token)
+	}
+	return QuantityFromFloat(f), nil
+}
+
+// Add returns the sum of two quantities. When the exact sum does not fit in int64 it is
+// rounded to three decimal places instead.
+func (q Quantity) Add(other Quantity) Quantity {
+	g := gcd(q.den(), other.den())
+	left, ok1 := mulInt64(q.Num, other.den()/g)
+	right, ok2 := mulInt64(other.Num, q.den()/g)
+	den, ok3 := mulInt64(q.den(), other.den()/g)
+	num, ok4 := addInt64(left, right)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return QuantityFromFloat(q.Float64() + other.Float64())
+	}
+	return NewQuantity(num, den)
+}
+
+// Mul returns the product of two quantities. When the exact product does not fit in int64 it
+// is rounded to three decimal places instead.
+func (q Quantity) Mul(other Quantity) Quantity {
+	// Cancel common factors first so that exact products overflow less often
+	g1 := gcd(abs(q.Num), other.den())
+	g2 := gcd(abs(other.Num), q.den())
+	num, ok1 := mulInt64(q.Num/g1, other.Num/g2)
+	den, ok2 := mulInt64(q.den()/g2, other.den()/g1)
+	if !ok1 || !ok2 {
+		return QuantityFromFloat(q.Float64() * other.Float64())
+	}
+	return NewQuantity(num, den)
+}
+
+// Float64 returns the quantity as a floating point number
+func (q Quantity) Float64() float64 {
+	return float64(q.Num) / float64(q.den())
+}
+
+// IsZero reports whether the quantity is zero
+func (q Quantity) IsZero() bool {
+	return q.Num == 0
+}
+
+// String renders the quantity as a whole number, fraction or mixed number
+func (q Quantity) String() string {
+	q = NewQuantity(q.Num, q.den())
+	sign := ""
+	if q.Num < 0 {
+		sign = "-"
+		q.Num = -q.Num
+	}
+
+	whole, rem := q.Num/q.Den, q.Num%q.Den
+	switch {
+	case rem == 0:
+		return fmt.Sprintf("%s%d", sign, whole)
+	case whole == 0:
+		return fmt.Sprintf("%s%d/%d", sign, rem, q.Den)
+	default:
+		return fmt.Sprintf("%s%d %d/%d", sign, whole, rem, q.Den)
+	}
+}
+
+// MarshalJSON encodes the quantity as a string such as "1 1/2"
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.String())
+}
+
+// UnmarshalJSON accepts either a JSON number or a quantity string
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err == nil {
+		if f < 0 || f > MaxQuantity {
+			return fmt.Errorf("invalid quantity %v", f)
+		}
+		*q = QuantityFromFloat(f)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("quantity must be a number or a string")
+	}
+	parsed, err := ParseQuantity(s)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// den returns the denominator, treating the zero value as a whole number
+func (q Quantity) den() int64 {
+	if q.Den == 0 {
+		return 1
+	}
+	return q.Den
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// mulInt64 returns a*b and whether it fits in int64
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return c, true
+}
+
+// addInt64 returns a+b and whether it fits in int64
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	if (c > a) != (b > 0) {
+		return 0, false
+	}
+	return c, true
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// TestParseQuantity tests parsing amounts and rejecting negative or oversized ones
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+		valid    bool
+	}{
+		{"2", "2", true},
+		{"1.5", "1 1/2", true},
+		{"3/4", "3/4", true},
+		{"1 1/2", "1 1/2", true},
+		{"1½", "1 1/2", true},
+		{"-0.5", "", false},
+		{"-1/2", "", false},
+		{"1/-2", "", false},
+		{"-1½", "", false},
+		{"1e20", "", false},
+		{"99999999999999999/1", "", false},
+		{"999999999 999999999", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			quantity, err := ParseQuantity(tc.text)
+			if !tc.valid {
+				if err == nil {
+					t.Errorf("Expected an error, but got %s", quantity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if quantity.String() != tc.expected {
+				t.Errorf("Expected '%s', but got '%s'", tc.expected, quantity)
+			}
+		})
+	}
+
+	var quantity Quantity
+	if err := json.Unmarshal([]byte(`1e20`), &quantity); err == nil {
+		t.Errorf("Expected an error for a huge JSON number, but got %s", quantity)
+	}
+}
+
+// TestQuantityOverflow tests that arithmetic too large for exact fractions stays close instead of wrapping
+func TestQuantityOverflow(t *testing.T) {
+	third := NewQuantity(1, 3)
+	if sum := third.Add(NewQuantity(1, 6)); sum.String() != "1/2" {
+		t.Errorf("Expected 1/2, but got %s", sum)
+	}
+	if product := NewQuantity(2, 3).Mul(NewQuantity(3, 4)); product.String() != "1/2" {
+		t.Errorf("Expected 1/2, but got %s", product)
+	}
+
+	big := NewQuantity(1, 999999937)
+	sum := big.Add(NewQuantity(1, 999999929)).Add(NewQuantity(1, 999999893))
+	if sum.Float64() < 0 || math.Abs(sum.Float64()-3e-9) > 0.001 {
+		t.Errorf("Expected a sum close to zero, but got %v", sum.Float64())
+	}
+
+	product := WholeQuantity(MaxQuantity).Mul(WholeQuantity(MaxQuantity)).Mul(WholeQuantity(MaxQuantity))
+	if product.Float64() <= 0 {
+		t.Errorf("Expected a large positive product, but got %v", product.Float64())
+	}
+}
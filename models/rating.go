@@ -6,13 +6,14 @@ import (
 
 // Rating represents a user's rating and review for a recipe
 type Rating struct {
-	ID        string    `json:"id"`
-	RecipeID  string    `json:"recipeId"`
-	UserID    string    `json:"userId"`
-	Score     int       `json:"score"` // 1-5 stars
-	Comment   string    `json:"comment"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        string     `json:"id"`
+	RecipeID  string     `json:"recipeId"`
+	UserID    string     `json:"userId"`
+	Score     int        `json:"score"` // 1-5 stars
+	Comment   string     `json:"comment"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
 
 // RatingInput represents the data needed to create or update a rating
@@ -46,4 +47,9 @@ func UpdateRating(original Rating, input RatingInput) Rating {
 		CreatedAt: original.CreatedAt,
 		UpdatedAt: time.Now(),
 	}
-}
\ No newline at end of file
+}
+
+// IsDeleted reports whether the rating is in the trash
+func (r Rating) IsDeleted() bool {
+	return r.DeletedAt != nil
+}
@@ -1,67 +1,245 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
+// RecipeStatus is the lifecycle state of a recipe
+type RecipeStatus string
+
+// Recipe lifecycle states
+const (
+	StatusDraft     RecipeStatus = "draft"
+	StatusPublished RecipeStatus = "published"
+	StatusArchived  RecipeStatus = "archived"
+)
+
+// RecipeVisibility controls who can find and open a recipe
+type RecipeVisibility string
+
+// Recipe visibility levels
+const (
+	VisibilityPrivate  RecipeVisibility = "private"
+	VisibilityUnlisted RecipeVisibility = "unlisted"
+	VisibilityPublic   RecipeVisibility = "public"
+)
+
+// IsValid reports whether the visibility is one of the known levels
+func (v RecipeVisibility) IsValid() bool {
+	return v == VisibilityPrivate || v == VisibilityUnlisted || v == VisibilityPublic
+}
+
+// UnmarshalJSON rejects unknown visibility levels
+func (v *RecipeVisibility) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	visibility := RecipeVisibility(value)
+	if visibility != "" && !visibility.IsValid() {
+		return fmt.Errorf("unknown visibility %q", value)
+	}
+
+	*v = visibility
+	return nil
+}
+
 // Recipe represents a cooking recipe
 type Recipe struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Ingredients []string  `json:"ingredients"`
-	Instructions []string  `json:"instructions"`
-	PrepTime    int       `json:"prepTime"` // in minutes
-	CookTime    int       `json:"cookTime"` // in minutes
-	Servings    int       `json:"servings"`
-	Tags        []string  `json:"tags"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          string       `json:"id"`
+	AuthorID    string       `json:"authorId"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Ingredients []Ingredient `json:"ingredients"`
+	// IngredientSections groups the ingredients; Ingredients is the same list flattened for older clients
+	IngredientSections []IngredientSection `json:"ingredientSections"`
+	Instructions       []string            `json:"instructions"`
+	// InstructionSections groups the steps; Instructions is the same list flattened for older clients
+	InstructionSections []InstructionSection `json:"instructionSections"`
+	PrepTime            int                  `json:"prepTime"` // in minutes
+	CookTime            int                  `json:"cookTime"` // in minutes
+	Servings            int                  `json:"servings"`
+	Tags                []string             `json:"tags"`
+	Diet                DietInfo             `json:"diet"`
+	Status              RecipeStatus         `json:"status"`
+	Visibility          RecipeVisibility     `json:"visibility"`
+	ForkedFrom          *ForkReference       `json:"forkedFrom,omitempty"`
+	Source              *RecipeSource        `json:"source,omitempty"`
+	// Images are in display order; they are managed through their own endpoints, not recipe updates
+	Images       []RecipeImage `json:"images"`
+	CoverImageID string        `json:"coverImageId,omitempty"`
+	Revision     int           `json:"revision"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+	DeletedAt    *time.Time    `json:"deletedAt,omitempty"`
 }
 
 // RecipeInput represents the data needed to create or update a recipe
 type RecipeInput struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Ingredients []string  `json:"ingredients"`
-	Instructions []string  `json:"instructions"`
-	PrepTime    int       `json:"prepTime"`
-	CookTime    int       `json:"cookTime"`
-	Servings    int       `json:"servings"`
-	Tags        []string  `json:"tags"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Ingredients  []Ingredient `json:"ingredients"`
+	Instructions []string     `json:"instructions"`
+	// Sections take precedence over the flat Ingredients and Instructions lists when both are sent
+	IngredientSections  []IngredientSection  `json:"ingredientSections,omitempty"`
+	InstructionSections []InstructionSection `json:"instructionSections,omitempty"`
+	PrepTime            int                  `json:"prepTime"`
+	CookTime            int                  `json:"cookTime"`
+	Servings            int                  `json:"servings"`
+	Tags                []string             `json:"tags"`
+	// Visibility defaults to public on create and is left unchanged on update when empty
+	Visibility RecipeVisibility `json:"visibility,omitempty"`
+	// Source credits where the recipe comes from and is left unchanged on update when empty
+	Source *RecipeSource `json:"source,omitempty"`
+	// Diet is derived from the ingredients by the recipe service before saving
+	Diet DietInfo `json:"-"`
 }
 
-// NewRecipe creates a new Recipe with the given input and generated ID
-func NewRecipe(id string, input RecipeInput) Recipe {
+// recipeInputFields mirrors RecipeInput without its JSON methods
+type recipeInputFields RecipeInput
+
+// UnmarshalJSON accepts each of the flat instructions either as text or as a step object. When any
+// step is an object and no sections are sent, the steps become a single unnamed section so their
+// durations, temperatures and ingredients are kept.
+func (in *RecipeInput) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		recipeInputFields
+		Instructions []json.RawMessage `json:"instructions"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*in = RecipeInput(fields.recipeInputFields)
+	in.Instructions = nil
+	if fields.Instructions == nil {
+		return nil
+	}
+
+	in.Instructions = make([]string, 0, len(fields.Instructions))
+	steps := make([]Step, 0, len(fields.Instructions))
+	structured := false
+	for _, raw := range fields.Instructions {
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil {
+			in.Instructions = append(in.Instructions, text)
+			steps = append(steps, ParseStep(text))
+			continue
+		}
+
+		var step Step
+		if err := json.Unmarshal(raw, &step); err != nil {
+			return err
+		}
+		in.Instructions = append(in.Instructions, step.Text)
+		steps = append(steps, step)
+		structured = true
+	}
+
+	if structured && len(in.InstructionSections) == 0 {
+		in.InstructionSections = []InstructionSection{{Steps: steps}}
+	}
+	return nil
+}
+
+// NewRecipe creates a new draft Recipe with the given input, generated ID and author
+func NewRecipe(id string, authorID string, input RecipeInput) Recipe {
 	now := time.Now()
+	input = input.NormalizeSections()
+
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = VisibilityPublic
+	}
+
 	return Recipe{
-		ID:          id,
-		Title:       input.Title,
-		Description: input.Description,
-		Ingredients: input.Ingredients,
-		Instructions: input.Instructions,
-		PrepTime:    input.PrepTime,
-		CookTime:    input.CookTime,
-		Servings:    input.Servings,
-		Tags:        input.Tags,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:                  id,
+		AuthorID:            authorID,
+		Title:               input.Title,
+		Description:         input.Description,
+		Ingredients:         input.Ingredients,
+		IngredientSections:  input.IngredientSections,
+		Instructions:        input.Instructions,
+		InstructionSections: input.InstructionSections,
+		PrepTime:            input.PrepTime,
+		CookTime:            input.CookTime,
+		Servings:            input.Servings,
+		Tags:                input.Tags,
+		Diet:                input.Diet,
+		Status:              StatusDraft,
+		Visibility:          visibility,
+		Source:              input.Source,
+		Images:              make([]RecipeImage, 0),
+		Revision:            1,
+		CreatedAt:           now,
+		UpdatedAt:           now,
 	}
 }
 
-// UpdateRecipe creates a new Recipe with updated fields but preserves the original ID and creation time
+// UpdateRecipe creates a new Recipe with updated fields and the next revision number
+// but preserves the original ID, author, status, fork origin, images, and creation time
 func UpdateRecipe(original Recipe, input RecipeInput) Recipe {
+	input = input.NormalizeSectionsFor(original)
+
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = original.Visibility
+	}
+
+	source := input.Source
+	if source == nil {
+		source = original.Source
+	}
+
 	return Recipe{
-		ID:          original.ID,
-		Title:       input.Title,
-		Description: input.Description,
-		Ingredients: input.Ingredients,
-		Instructions: input.Instructions,
-		PrepTime:    input.PrepTime,
-		CookTime:    input.CookTime,
-		Servings:    input.Servings,
-		Tags:        input.Tags,
-		CreatedAt:   original.CreatedAt,
-		UpdatedAt:   time.Now(),
+		ID:                  original.ID,
+		AuthorID:            original.AuthorID,
+		Title:               input.Title,
+		Description:         input.Description,
+		Ingredients:         input.Ingredients,
+		IngredientSections:  input.IngredientSections,
+		Instructions:        input.Instructions,
+		InstructionSections: input.InstructionSections,
+		PrepTime:            input.PrepTime,
+		CookTime:            input.CookTime,
+		Servings:            input.Servings,
+		Tags:                input.Tags,
+		Diet:                input.Diet,
+		Status:              original.Status,
+		Visibility:          visibility,
+		ForkedFrom:          original.ForkedFrom,
+		Source:              source,
+		Images:              original.Images,
+		CoverImageID:        original.CoverImageID,
+		Revision:            original.Revision + 1,
+		CreatedAt:           original.CreatedAt,
+		UpdatedAt:           time.Now(),
 	}
-}
\ No newline at end of file
+}
+
+// Input returns the editable content of the recipe as a RecipeInput. Visibility is left
+// empty so that saving the input, e.g. when reverting, keeps the current visibility.
+// The source is kept, so forks credit the original source too.
+func (r Recipe) Input() RecipeInput {
+	return RecipeInput{
+		Title:               r.Title,
+		Description:         r.Description,
+		Ingredients:         r.Ingredients,
+		IngredientSections:  r.IngredientSections,
+		Instructions:        r.Instructions,
+		InstructionSections: r.InstructionSections,
+		PrepTime:            r.PrepTime,
+		CookTime:            r.CookTime,
+		Servings:            r.Servings,
+		Tags:                r.Tags,
+		Source:              r.Source,
+		Diet:                r.Diet,
+	}
+}
+
+// IsDeleted reports whether the recipe is in the trash
+func (r Recipe) IsDeleted() bool {
+	return r.DeletedAt != nil
+}
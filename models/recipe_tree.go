@@ -0,0 +1,18 @@
+package models
+
+// RecipeTree is a recipe with the sub-recipes it uses, recursively. Times are in minutes;
+// the totals include every sub-recipe below the node, each made once.
+type RecipeTree struct {
+	RecipeID string `json:"recipeId"`
+	Title    string `json:"title,omitempty"`
+	// Batches is how many times the recipe as written is needed
+	Batches       float64 `json:"batches"`
+	PrepTime      int     `json:"prepTime"`
+	CookTime      int     `json:"cookTime"`
+	TotalPrepTime int     `json:"totalPrepTime"`
+	TotalCookTime int     `json:"totalCookTime"`
+	TotalTime     int     `json:"totalTime"`
+	// Unavailable marks a sub-recipe that was deleted or can no longer be seen
+	Unavailable bool         `json:"unavailable,omitempty"`
+	Children    []RecipeTree `json:"children"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// RecipeRevision is an immutable snapshot of a recipe taken every time it is saved
+type RecipeRevision struct {
+	RecipeID  string    `json:"recipeId"`
+	Number    int       `json:"number"`
+	EditorID  string    `json:"editorId"`
+	CreatedAt time.Time `json:"createdAt"`
+	Snapshot  Recipe    `json:"snapshot"`
+}
+
+// FieldChange describes a single field that differs between two recipe revisions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// RevisionDiff lists the field-level changes between two revisions of a recipe
+type RevisionDiff struct {
+	RecipeID string        `json:"recipeId"`
+	From     int           `json:"from"`
+	To       int           `json:"to"`
+	Changes  []FieldChange `json:"changes"`
+}
+
+// NewRecipeRevision creates a revision from the saved state of a recipe
+func NewRecipeRevision(recipe Recipe, editorID string) RecipeRevision {
+	return RecipeRevision{
+		RecipeID:  recipe.ID,
+		Number:    recipe.Revision,
+		EditorID:  editorID,
+		CreatedAt: recipe.UpdatedAt,
+		Snapshot:  recipe,
+	}
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"reflect"
+)
+
+// IngredientSection is a named group of ingredients, such as "For the dough".
+// Recipes without sections have a single section with an empty name.
+type IngredientSection struct {
+	Name        string       `json:"name"`
+	Ingredients []Ingredient `json:"ingredients"`
+}
+
+// InstructionSection is a named group of instruction steps, such as "For the filling".
+// Recipes without sections have a single section with an empty name.
+type InstructionSection struct {
+	Name  string `json:"name"`
+	Steps []Step `json:"steps"`
+}
+
+// NormalizeSections keeps the sections and the flat ingredient and instruction lists in step.
+// When sections are given they win and the flat lists are rebuilt from them; clients that only
+// send flat lists get a single unnamed section.
+func (in RecipeInput) NormalizeSections() RecipeInput {
+	if len(in.IngredientSections) > 0 {
+		in.Ingredients = flattenIngredients(in.IngredientSections)
+	} else if len(in.Ingredients) > 0 {
+		in.IngredientSections = []IngredientSection{{Ingredients: in.Ingredients}}
+	}
+
+	if len(in.InstructionSections) > 0 {
+		in.Instructions = flattenInstructions(in.InstructionSections)
+	} else if len(in.Instructions) > 0 {
+		in.InstructionSections = []InstructionSection{{Steps: ParseSteps(in.Instructions)}}
+	}
+
+	// Steps refer to ingredients by their index in the flat list
+	sections := make([]InstructionSection, 0, len(in.InstructionSections))
+	for _, section := range in.InstructionSections {
+		sections = append(sections, InstructionSection{
+			Name:  section.Name,
+			Steps: linkIngredients(section.Steps, in.Ingredients),
+		})
+	}
+	in.InstructionSections = sections
+
+	return in
+}
+
+// NormalizeSectionsFor normalizes an update of original. When both the sections and a flat list are
+// sent but only the flat list differs from original, as when a client that predates sections echoes
+// a recipe back with edits, the flat list wins and replaces the sections with a single unnamed one.
+func (in RecipeInput) NormalizeSectionsFor(original Recipe) RecipeInput {
+	if len(in.IngredientSections) > 0 && len(in.Ingredients) > 0 &&
+		reflect.DeepEqual(in.IngredientSections, original.IngredientSections) &&
+		!reflect.DeepEqual(in.Ingredients, original.Ingredients) {
+		in.IngredientSections = nil
+	}
+
+	if len(in.InstructionSections) > 0 && len(in.Instructions) > 0 &&
+		reflect.DeepEqual(in.InstructionSections, original.InstructionSections) &&
+		!reflect.DeepEqual(in.Instructions, original.Instructions) {
+		in.InstructionSections = nil
+	}
+
+	return in.NormalizeSections()
+}
+
+// MapIngredients returns a copy of the recipe with f applied to every ingredient in every section,
+// keeping the flat ingredient list in step
+func (r Recipe) MapIngredients(f func(Ingredient) Ingredient) Recipe {
+	sections := make([]IngredientSection, 0, len(r.IngredientSections))
+	for _, section := range r.IngredientSections {
+		mapped := make([]Ingredient, 0, len(section.Ingredients))
+		for _, ingredient := range section.Ingredients {
+			mapped = append(mapped, f(ingredient))
+		}
+		sections = append(sections, IngredientSection{Name: section.Name, Ingredients: mapped})
+	}
+
+	r.IngredientSections = sections
+	r.Ingredients = flattenIngredients(sections)
+	return r
+}
+
+// MapSteps returns a copy of the recipe with f applied to every instruction step in every section,
+// keeping the flat instruction list in step
+func (r Recipe) MapSteps(f func(Step) Step) Recipe {
+	sections := make([]InstructionSection, 0, len(r.InstructionSections))
+	for _, section := range r.InstructionSections {
+		mapped := make([]Step, 0, len(section.Steps))
+		for _, step := range section.Steps {
+			mapped = append(mapped, f(step))
+		}
+		sections = append(sections, InstructionSection{Name: section.Name, Steps: mapped})
+	}
+
+	r.InstructionSections = sections
+	r.Instructions = flattenInstructions(sections)
+	return r
+}
+
+// ReplaceIngredients returns a copy of the recipe with every ingredient replaced by the lines f returns
+// for it, which may be none or several. Steps that referred to an ingredient refer to its replacements.
+func (r Recipe) ReplaceIngredients(f func(Ingredient) []Ingredient) Recipe {
+	sections := make([]IngredientSection, 0, len(r.IngredientSections))
+	moved := make([][]int, 0, len(r.Ingredients))
+	next := 0
+	for _, section := range r.IngredientSections {
+		replaced := make([]Ingredient, 0, len(section.Ingredients))
+		for _, ingredient := range section.Ingredients {
+			lines := f(ingredient)
+			indexes := make([]int, 0, len(lines))
+			for range lines {
+				indexes = append(indexes, next)
+				next++
+			}
+			moved = append(moved, indexes)
+			replaced = append(replaced, lines...)
+		}
+		sections = append(sections, IngredientSection{Name: section.Name, Ingredients: replaced})
+	}
+
+	instructions := make([]InstructionSection, 0, len(r.InstructionSections))
+	for _, section := range r.InstructionSections {
+		steps := make([]Step, 0, len(section.Steps))
+		for _, step := range section.Steps {
+			references := make([]int, 0, len(step.Ingredients))
+			for _, i := range step.Ingredients {
+				if i >= 0 && i < len(moved) {
+					references = append(references, moved[i]...)
+				}
+			}
+			step.Ingredients = references
+			steps = append(steps, step)
+		}
+		instructions = append(instructions, InstructionSection{Name: section.Name, Steps: steps})
+	}
+
+	r.IngredientSections = sections
+	r.Ingredients = flattenIngredients(sections)
+	r.InstructionSections = instructions
+	return r
+}
+
+// flattenIngredients joins the ingredients of every section in order
+func flattenIngredients(sections []IngredientSection) []Ingredient {
+	result := make([]Ingredient, 0)
+	for _, section := range sections {
+		result = append(result, section.Ingredients...)
+	}
+	return result
+}
+
+// flattenInstructions joins the step texts of every section in order
+func flattenInstructions(sections []InstructionSection) []string {
+	result := make([]string, 0)
+	for _, section := range sections {
+		for _, step := range section.Steps {
+			result = append(result, step.Text)
+		}
+	}
+	return result
+}
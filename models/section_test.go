@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestRecipeSections tests that sections and flat lists are kept in step
+func TestRecipeSections(t *testing.T) {
+	payload := `{
+		"title": "Pie",
+		"ingredientSections": [
+			{"name": "For the dough", "ingredients": ["2 cups flour", "1/2 cup butter"]},
+			{"name": "For the filling", "ingredients": ["4 apples"]}
+		],
+		"instructionSections": [
+			{"name": "For the dough", "steps": ["Rub the butter into the flour"]},
+			{"name": "For the filling", "steps": ["Slice the apples", "Fill the pie"]}
+		]
+	}`
+
+	var input RecipeInput
+	if err := json.Unmarshal([]byte(payload), &input); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Sections are flattened for older clients
+	recipe := NewRecipe("recipe-1", "author-1", input)
+	if len(recipe.Ingredients) != 3 || recipe.Ingredients[2].Name != "apples" {
+		t.Errorf("Expected 3 flattened ingredients, but got %+v", recipe.Ingredients)
+	}
+	if len(recipe.Instructions) != 3 || recipe.Instructions[0] != "Rub the butter into the flour" {
+		t.Errorf("Expected 3 flattened instructions, but got %v", recipe.Instructions)
+	}
+
+	// Older clients that only send flat lists get a single unnamed section
+	legacy := NewRecipe("recipe-2", "author-1", RecipeInput{
+		Title:        "Toast",
+		Ingredients:  ParseIngredients([]string{"1 slice bread"}),
+		Instructions: []string{"Toast the bread"},
+	})
+	if len(legacy.IngredientSections) != 1 || legacy.IngredientSections[0].Name != "" {
+		t.Errorf("Expected a single unnamed ingredient section, but got %+v", legacy.IngredientSections)
+	}
+	if len(legacy.InstructionSections) != 1 || len(legacy.InstructionSections[0].Steps) != 1 {
+		t.Errorf("Expected a single unnamed instruction section, but got %+v", legacy.InstructionSections)
+	}
+
+	// An older client echoing the recipe back with an edited flat list keeps its edit
+	echoed := legacy.Input()
+	echoed.Instructions = []string{"Toast the bread", "Butter it"}
+	updated := UpdateRecipe(legacy, echoed)
+	if len(updated.InstructionSections) != 1 || len(updated.InstructionSections[0].Steps) != 2 {
+		t.Errorf("Expected the flat edit to reach the sections, but got %+v", updated.InstructionSections)
+	}
+
+	// The same holds when the recipe makes a round trip through JSON, with steps mentioning no ingredient
+	plain := NewRecipe("recipe-3", "author-1", RecipeInput{
+		Title:        "Toast",
+		Ingredients:  ParseIngredients([]string{"1 slice bread"}),
+		Instructions: []string{"Toast it until golden", "Butter the bread"},
+	})
+	data, err := json.Marshal(plain)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var roundTrip RecipeInput
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	roundTrip.Instructions = []string{"Serve it cold"}
+	updated = UpdateRecipe(plain, roundTrip)
+	if len(updated.Instructions) != 1 || updated.Instructions[0] != "Serve it cold" ||
+		updated.InstructionSections[0].Steps[0].Text != "Serve it cold" {
+		t.Errorf("Expected the flat edit to survive a JSON round trip, but got %v", updated.Instructions)
+	}
+
+	// Mapping ingredients keeps the sections
+	mapped := recipe.MapIngredients(func(ingredient Ingredient) Ingredient {
+		ingredient.Note = "organic"
+		return ingredient
+	})
+	if len(mapped.IngredientSections) != 2 || mapped.IngredientSections[1].Name != "For the filling" {
+		t.Fatalf("Expected sections to survive mapping, but got %+v", mapped.IngredientSections)
+	}
+	if mapped.Ingredients[0].Note != "organic" || mapped.IngredientSections[0].Ingredients[0].Note != "organic" {
+		t.Error("Expected both the sections and the flat list to be mapped")
+	}
+	if recipe.IngredientSections[0].Ingredients[0].Note != "" {
+		t.Error("Expected the original recipe to be left unchanged")
+	}
+}
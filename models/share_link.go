@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareLink grants read-only access to a single recipe to anyone holding its token
+type ShareLink struct {
+	ID        string     `json:"id"`
+	RecipeID  string     `json:"recipeId"`
+	CreatedBy string     `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	// Token is only returned when the link is created; it is never stored
+	Token string `json:"token,omitempty"`
+}
+
+// ShareLinkInput represents the data needed to create a share link
+type ShareLinkInput struct {
+	// ExpiresIn is a Go duration such as "72h"; empty means the default lifetime
+	ExpiresIn string `json:"expiresIn"`
+}
+
+// NewShareLink creates a new ShareLink for a recipe that expires at the given time
+func NewShareLink(id string, recipeID string, createdBy string, expiresAt time.Time) ShareLink {
+	return ShareLink{
+		ID:        id,
+		RecipeID:  recipeID,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// IsActive reports whether the link is neither revoked nor expired at the given time
+func (l ShareLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
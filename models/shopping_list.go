@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// AisleCategory is the store section an ingredient is shopped in
+type AisleCategory string
+
+// Aisle categories in the order a shopping list walks the store
+const (
+	AisleProduce   AisleCategory = "produce"
+	AisleBakery    AisleCategory = "bakery"
+	AisleMeat      AisleCategory = "meat-seafood"
+	AisleDairy     AisleCategory = "dairy-eggs"
+	AisleFrozen    AisleCategory = "frozen"
+	AislePantry    AisleCategory = "pantry"
+	AisleSpices    AisleCategory = "spices"
+	AisleBeverages AisleCategory = "beverages"
+	AisleOther     AisleCategory = "other"
+)
+
+// AisleCategories lists every aisle category in the order a shopping list walks the store
+var AisleCategories = []AisleCategory{
+	AisleProduce, AisleBakery, AisleMeat, AisleDairy, AisleFrozen, AislePantry, AisleSpices, AisleBeverages, AisleOther,
+}
+
+// aisleLabels are the headings printed above each aisle category
+var aisleLabels = map[AisleCategory]string{
+	AisleProduce:   "Produce",
+	AisleBakery:    "Bakery",
+	AisleMeat:      "Meat & Seafood",
+	AisleDairy:     "Dairy & Eggs",
+	AisleFrozen:    "Frozen",
+	AislePantry:    "Pantry",
+	AisleSpices:    "Spices",
+	AisleBeverages: "Beverages",
+	AisleOther:     "Other",
+}
+
+// Label returns the heading of the category, e.g. "Dairy & Eggs"
+func (c AisleCategory) Label() string {
+	if label, ok := aisleLabels[c]; ok {
+		return label
+	}
+	return aisleLabels[AisleOther]
+}
+
+// Order returns the position of the category in the store walk; unknown categories come last
+func (c AisleCategory) Order() int {
+	for i, category := range AisleCategories {
+		if category == c {
+			return i
+		}
+	}
+	return len(AisleCategories)
+}
+
+// ShoppingList is a consolidated list of the ingredients needed for a set of recipes
+type ShoppingList struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"ownerId"`
+	Name    string `json:"name"`
+	// Items are ordered by aisle category, then name
+	Items     []ShoppingListItem `json:"items"`
+	CreatedAt time.Time          `json:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+}
+
+// ShoppingListItem is an ingredient to buy, merged across every recipe that uses it
+type ShoppingListItem struct {
+	ID string `json:"id"`
+	// Quantity is nil for ingredients used without an amount, such as salt to taste
+	Quantity *Quantity     `json:"quantity,omitempty"`
+	Unit     string        `json:"unit,omitempty"`
+	Name     string        `json:"name"`
+	Category AisleCategory `json:"category"`
+	Checked  bool          `json:"checked"`
+	// RecipeIDs are the recipes that call for the ingredient
+	RecipeIDs []string `json:"recipeIds"`
+}
+
+// ShoppingListInput represents the recipes, or the meal plan days, to build a shopping list from
+type ShoppingListInput struct {
+	Name    string                    `json:"name"`
+	Recipes []ShoppingListRecipeInput `json:"recipes,omitempty"`
+	// MealPlanID adds the meals planned between From and To, inclusive; zero bounds are open
+	MealPlanID string `json:"mealPlanId,omitempty"`
+	From       Date   `json:"from,omitempty"`
+	To         Date   `json:"to,omitempty"`
+}
+
+// ShoppingListRecipeInput is a recipe to shop for, scaled to Servings when set
+type ShoppingListRecipeInput struct {
+	RecipeID string `json:"recipeId"`
+	Servings int    `json:"servings,omitempty"`
+}
+
+// ShoppingListItemInput represents a change to the check-off state of an item
+type ShoppingListItemInput struct {
+	Checked bool `json:"checked"`
+}
+
+// NewShoppingList creates a new ShoppingList with the given items, generated ID and owner
+func NewShoppingList(id string, ownerID string, name string, items []ShoppingListItem) ShoppingList {
+	now := time.Now()
+	return ShoppingList{
+		ID:        id,
+		OwnerID:   ownerID,
+		Name:      name,
+		Items:     items,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Ingredient returns the item as an ingredient line, e.g. for "2 cups milk"
+func (i ShoppingListItem) Ingredient() Ingredient {
+	return Ingredient{Quantity: i.Quantity, Unit: i.Unit, Name: i.Name}
+}
@@ -0,0 +1,8 @@
+package models
+
+// RecipeSource credits where a recipe comes from, such as the page it was imported from
+type RecipeSource struct {
+	URL       string `json:"url,omitempty"`
+	Author    string `json:"author,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Temperature is an oven or cooking temperature in °C or °F
+type Temperature struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// String renders the temperature as it is written in step text, e.g. "180°C"
+func (t Temperature) String() string {
+	return strconv.FormatFloat(t.Value, 'f', -1, 64) + t.Unit
+}
+
+// Step is a single structured instruction step
+type Step struct {
+	Text string `json:"text"`
+	// Duration is how long the step takes in seconds, zero when unknown
+	Duration int `json:"duration,omitempty"`
+	// Passive marks unattended time such as baking, resting or chilling
+	Passive     bool         `json:"passive,omitempty"`
+	Temperature *Temperature `json:"temperature,omitempty"`
+	// Ingredients are indexes into the recipe's flat ingredient list. When omitted they are
+	// filled in from the ingredient names mentioned in the text. Responses always include them,
+	// even when empty, so a recipe sent back as it was read keeps its steps as they are.
+	Ingredients []int `json:"ingredients"`
+}
+
+// stepFields mirrors Step without its JSON methods
+type stepFields Step
+
+var (
+	// temperaturePattern matches "180°C", "350 °F" and "180 degrees C"
+	temperaturePattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(?:[°º]\s*|degrees?\s+)(celsius|fahrenheit|c|f)\b`)
+	// compactTemperaturePattern matches "180C", "350 F" and "350 fahrenheit"; a spaced single letter
+	// must be a capital, so "2 c flour" stays cups
+	compactTemperaturePattern = regexp.MustCompile(`(\d+(?:\.\d+)?)(?:((?i:c|f))|\s*(C|F)|\s+((?i:celsius|fahrenheit)))\b`)
+	// durationPattern matches "25 minutes", "1 1/2 hours", "1½ hours", "1-2 hours" and "an hour";
+	// ranges keep the lower bound. Only hours and minutes go without a number, so "a second batch"
+	// is no duration.
+	durationPattern = regexp.MustCompile(`(?i)(?:(\b\d+\s+\d+/\d+|\b\d+\s*[½⅓⅔¼¾⅕⅖⅗⅘⅙⅚⅛⅜⅝⅞]|[½⅓⅔¼¾⅕⅖⅗⅘⅙⅚⅛⅜⅝⅞]|\b\d+/\d+|\b\d+(?:\.\d+)?)\s*(?:(?:-|–|to)\s*\d+(?:\.\d+)?\s*)?(hours?|hrs?|minutes?|mins?|seconds?|secs?)|\ban?\s+(hours?|minutes?))\b`)
+	// passivePattern matches verbs for steps that need no attention while the timer runs
+	passivePattern = regexp.MustCompile(`(?i)\b(bak|roast|simmer|rest|chill|refrigerat|marinat|rise|rising|proof|prove|cool|freez|soak|steep|braise|stand|set aside)\w*`)
+)
+
+// secondsPerUnit maps the first letter of a duration unit to its length in seconds
+var secondsPerUnit = map[byte]float64{'h': 3600, 'm': 60, 's': 1}
+
+// ParseStep turns legacy instruction text such as "Bake at 180°C for 25 minutes" into a Step,
+// extracting the oven temperature, the total duration and whether the time is passive
+func ParseStep(text string) Step {
+	step := Step{Text: strings.TrimSpace(text)}
+
+	if match := temperaturePattern.FindStringSubmatch(step.Text); match != nil {
+		step.Temperature = parseTemperature(match[1], match[2])
+	} else if match := compactTemperaturePattern.FindStringSubmatch(step.Text); match != nil {
+		step.Temperature = parseTemperature(match[1], match[2]+match[3]+match[4])
+	}
+
+	// Durations in one step add up, e.g. "1 hour 30 minutes"
+	seconds := 0.0
+	for _, match := range durationPattern.FindAllStringSubmatch(step.Text, -1) {
+		amount := 1.0
+		if value, err := ParseQuantity(match[1]); err == nil {
+			amount = value.Float64()
+		}
+		unit := strings.ToLower(match[2] + match[3])
+		seconds += amount * secondsPerUnit[unit[0]]
+	}
+	step.Duration = int(math.Round(seconds))
+	step.Passive = step.Duration > 0 && passivePattern.MatchString(step.Text)
+
+	return step
+}
+
+// WithTemperature returns a copy of the step at temperature t. When the text mentions the step's
+// current temperature, that mention is rewritten to t as well.
+func (s Step) WithTemperature(t Temperature) Step {
+	if s.Temperature != nil {
+		for _, pattern := range []*regexp.Regexp{temperaturePattern, compactTemperaturePattern} {
+			match := pattern.FindStringSubmatchIndex(s.Text)
+			if match == nil {
+				continue
+			}
+			if mentioned := ParseStep(s.Text[match[0]:match[1]]).Temperature; mentioned != nil && *mentioned == *s.Temperature {
+				s.Text = s.Text[:match[0]] + t.String() + s.Text[match[1]:]
+			}
+			break
+		}
+	}
+	s.Temperature = &t
+	return s
+}
+
+// ParseSteps parses a list of legacy instruction lines
+func ParseSteps(lines []string) []Step {
+	result := make([]Step, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, ParseStep(line))
+	}
+	return result
+}
+
+// parseTemperature builds a Temperature from a matched value and unit word
+func parseTemperature(value, unit string) *Temperature {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	canonical, ok := normalizeTemperatureUnit(unit)
+	if !ok {
+		return nil
+	}
+	return &Temperature{Value: amount, Unit: canonical}
+}
+
+// normalizeTemperatureUnit returns the canonical name for a temperature unit spelling
+func normalizeTemperatureUnit(unit string) (string, bool) {
+	switch strings.ToLower(strings.TrimLeft(strings.TrimSpace(unit), "°º")) {
+	case "c", "celsius":
+		return UnitCelsius, true
+	case "f", "fahrenheit":
+		return UnitFahrenheit, true
+	}
+	return "", false
+}
+
+// linkIngredients fills in the ingredient references of steps that have none, using the
+// ingredient names mentioned in each step, and drops references that point past the list
+func linkIngredients(steps []Step, ingredients []Ingredient) []Step {
+	result := make([]Step, 0, len(steps))
+	for _, step := range steps {
+		if step.Ingredients == nil {
+			step.Ingredients = make([]int, 0)
+			for i, ingredient := range ingredients {
+				if mentions(step.Text, ingredient.Name) {
+					step.Ingredients = append(step.Ingredients, i)
+				}
+			}
+		} else {
+			valid := make([]int, 0, len(step.Ingredients))
+			for _, i := range step.Ingredients {
+				if i >= 0 && i < len(ingredients) {
+					valid = append(valid, i)
+				}
+			}
+			step.Ingredients = valid
+		}
+		result = append(result, step)
+	}
+	return result
+}
+
+// mentions reports whether text contains name as whole words, also accepting the singular
+// of a plural name so "Beat the egg" mentions "eggs"
+func mentions(text, name string) bool {
+	words := func(s string) string {
+		fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		return " " + strings.Join(fields, " ") + " "
+	}
+
+	haystack := words(text)
+	phrase := strings.TrimSpace(words(name))
+	if phrase == "" {
+		return false
+	}
+	if strings.Contains(haystack, " "+phrase+" ") {
+		return true
+	}
+	singular := strings.TrimSuffix(phrase, "s")
+	return singular != phrase && strings.Contains(haystack, " "+singular+" ")
+}
+
+// UnmarshalJSON accepts either legacy instruction text, which is parsed, or a structured object
+func (s *Step) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = ParseStep(text)
+		return nil
+	}
+
+	var fields stepFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if fields.Duration < 0 {
+		return errors.New("step duration cannot be negative")
+	}
+	if fields.Temperature != nil {
+		unit, ok := normalizeTemperatureUnit(fields.Temperature.Unit)
+		if !ok {
+			return fmt.Errorf("unknown temperature unit %q", fields.Temperature.Unit)
+		}
+		fields.Temperature.Unit = unit
+	}
+	*s = Step(fields)
+	return nil
+}
+
+// StepTimer is a timed step that clients can render as a timer
+type StepTimer struct {
+	Section string `json:"section"`
+	// Step is the 1-based position of the step across all sections
+	Step        int          `json:"step"`
+	Text        string       `json:"text"`
+	Duration    int          `json:"duration"`
+	Passive     bool         `json:"passive"`
+	Temperature *Temperature `json:"temperature,omitempty"`
+}
+
+// RecipeTiming splits the time a recipe takes into active and passive minutes, computed from its steps
+type RecipeTiming struct {
+	RecipeID    string      `json:"recipeId"`
+	ActiveTime  int         `json:"activeTime"`  // in minutes
+	PassiveTime int         `json:"passiveTime"` // in minutes
+	TotalTime   int         `json:"totalTime"`   // in minutes
+	Timers      []StepTimer `json:"timers"`
+}
+
+// Timing computes the active and passive time of the recipe and lists a timer for every timed step
+func (r Recipe) Timing() RecipeTiming {
+	timing := RecipeTiming{
+		RecipeID: r.ID,
+		Timers:   make([]StepTimer, 0),
+	}
+
+	active, passive, number := 0, 0, 0
+	for _, section := range r.InstructionSections {
+		for _, step := range section.Steps {
+			number++
+			if step.Duration == 0 {
+				continue
+			}
+			if step.Passive {
+				passive += step.Duration
+			} else {
+				active += step.Duration
+			}
+			timing.Timers = append(timing.Timers, StepTimer{
+				Section:     section.Name,
+				Step:        number,
+				Text:        step.Text,
+				Duration:    step.Duration,
+				Passive:     step.Passive,
+				Temperature: step.Temperature,
+			})
+		}
+	}
+
+	// Round up so a 30 second step still counts as a minute
+	timing.ActiveTime = (active + 59) / 60
+	timing.PassiveTime = (passive + 59) / 60
+	timing.TotalTime = (active + passive + 59) / 60
+	return timing
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestParseStep tests extracting temperatures, durations and passive time from instruction text
+func TestParseStep(t *testing.T) {
+	tests := []struct {
+		text        string
+		duration    int
+		passive     bool
+		temperature *Temperature
+	}{
+		{"Bake at 180°C for 25 minutes", 1500, true, &Temperature{180, UnitCelsius}},
+		{"Preheat the oven to 350 °F", 0, false, &Temperature{350, UnitFahrenheit}},
+		{"Roast at 200 degrees C for 1 hour 30 minutes", 5400, true, &Temperature{200, UnitCelsius}},
+		{"Bake at 425F for 10-12 mins", 600, true, &Temperature{425, UnitFahrenheit}},
+		{"Knead the dough for 10 minutes", 600, false, nil},
+		{"Let the dough rise for an hour", 3600, true, nil},
+		{"Whisk 2 c flour with the sugar", 0, false, nil},
+		{"Stir for 30 seconds", 30, false, nil},
+		{"Preheat the oven to 350 F", 0, false, &Temperature{350, UnitFahrenheit}},
+		{"Bake at 180 C for 20 minutes", 1200, true, &Temperature{180, UnitCelsius}},
+		{"Add 350 flour", 0, false, nil},
+		{"Set aside for a second batch", 0, false, nil},
+		{"Stir for a minute", 60, false, nil},
+		{"Simmer 1 1/2 hours", 5400, true, nil},
+		{"Simmer for ½ hour", 1800, true, nil},
+		{"Rest 1½ hours", 5400, true, nil},
+		{"Chill for 3/4 hour", 2700, true, nil},
+		{"Bake at 190°C for 1.5 hours", 5400, true, &Temperature{190, UnitCelsius}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			step := ParseStep(tc.text)
+
+			if step.Text != tc.text {
+				t.Errorf("Expected text '%s', but got '%s'", tc.text, step.Text)
+			}
+			if step.Duration != tc.duration {
+				t.Errorf("Expected duration %d, but got %d", tc.duration, step.Duration)
+			}
+			if step.Passive != tc.passive {
+				t.Errorf("Expected passive %v, but got %v", tc.passive, step.Passive)
+			}
+			if !reflect.DeepEqual(step.Temperature, tc.temperature) {
+				t.Errorf("Expected temperature %+v, but got %+v", tc.temperature, step.Temperature)
+			}
+		})
+	}
+}
+
+// TestRecipeTiming tests step ingredient references and active versus passive time
+func TestRecipeTiming(t *testing.T) {
+	payload := `{
+		"title": "Bread",
+		"ingredients": ["500 g flour", "7 g yeast", "300 ml water", "1 tsp salt"],
+		"instructionSections": [
+			{"name": "Dough", "steps": [
+				"Mix the flour, yeast and water",
+				"Knead with the salt for 10 minutes",
+				{"text": "Leave somewhere warm", "duration": 3600, "passive": true, "ingredients": [0, 9]}
+			]},
+			{"name": "Baking", "steps": ["Bake at 220°C for 35 minutes"]}
+		]
+	}`
+
+	var input RecipeInput
+	if err := json.Unmarshal([]byte(payload), &input); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	recipe := NewRecipe("recipe-1", "author-1", input)
+
+	steps := recipe.InstructionSections[0].Steps
+	if !reflect.DeepEqual(steps[0].Ingredients, []int{0, 1, 2}) {
+		t.Errorf("Expected the first step to use flour, yeast and water, but got %v", steps[0].Ingredients)
+	}
+	if !reflect.DeepEqual(steps[1].Ingredients, []int{3}) {
+		t.Errorf("Expected the second step to use salt, but got %v", steps[1].Ingredients)
+	}
+	if !reflect.DeepEqual(steps[2].Ingredients, []int{0}) {
+		t.Errorf("Expected references past the ingredient list to be dropped, but got %v", steps[2].Ingredients)
+	}
+	if len(recipe.Instructions) != 4 || recipe.Instructions[3] != "Bake at 220°C for 35 minutes" {
+		t.Errorf("Expected the flat instructions to hold the step texts, but got %v", recipe.Instructions)
+	}
+
+	timing := recipe.Timing()
+	if timing.ActiveTime != 10 || timing.PassiveTime != 95 || timing.TotalTime != 105 {
+		t.Errorf("Expected 10 active and 95 passive minutes, but got %+v", timing)
+	}
+	if len(timing.Timers) != 3 || timing.Timers[2].Step != 4 || timing.Timers[2].Section != "Baking" {
+		t.Errorf("Expected 3 timers ending with step 4 in Baking, but got %+v", timing.Timers)
+	}
+}
+
+// TestFlatStepObjects tests that the flat instructions list accepts step objects next to plain text
+func TestFlatStepObjects(t *testing.T) {
+	payload := `{
+		"title": "Bread",
+		"ingredients": ["500 g flour", "300 ml water"],
+		"instructions": [
+			"Mix the flour and water",
+			{"text": "Leave somewhere warm", "duration": 3600, "passive": true}
+		]
+	}`
+
+	var input RecipeInput
+	if err := json.Unmarshal([]byte(payload), &input); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	recipe := NewRecipe("recipe-1", "author-1", input)
+
+	if !reflect.DeepEqual(recipe.Instructions, []string{"Mix the flour and water", "Leave somewhere warm"}) {
+		t.Errorf("Expected the flat instructions to hold the step texts, but got %v", recipe.Instructions)
+	}
+	if len(recipe.InstructionSections) != 1 || len(recipe.InstructionSections[0].Steps) != 2 {
+		t.Fatalf("Expected one unnamed section with both steps, but got %+v", recipe.InstructionSections)
+	}
+	if step := recipe.InstructionSections[0].Steps[1]; step.Duration != 3600 || !step.Passive {
+		t.Errorf("Expected the step object to keep its duration, but got %+v", step)
+	}
+
+	var record BulkRecord
+	if err := json.Unmarshal([]byte(`{"title": "Bread", "instructions": [{"text": "Bake"}], "status": "published"}`), &record); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if record.Status != StatusPublished || record.Instructions[0] != "Bake" {
+		t.Errorf("Expected a published record with one step, but got %+v", record)
+	}
+}
+
+// TestStepRejectsNegativeDuration tests that a step object cannot take negative time
+func TestStepRejectsNegativeDuration(t *testing.T) {
+	var step Step
+	if err := json.Unmarshal([]byte(`{"text": "Wait", "duration": -60}`), &step); err == nil {
+		t.Errorf("Expected an error for a negative duration, but got %+v", step)
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+// SubstitutionRule is a curated swap: For can be replaced by the ingredients in Use, in the
+// amounts given, e.g. 1 cup buttermilk by 1 cup milk and 1 tbsp lemon juice
+type SubstitutionRule struct {
+	For Ingredient   `json:"for"`
+	Use []Ingredient `json:"use"`
+	// Labels are the dietary labels the swap helps a recipe meet, e.g. vegan for flax eggs
+	Labels []DietaryLabel `json:"labels,omitempty"`
+	Note   string         `json:"note,omitempty"`
+}
+
+// Substitution is a swap proposed for an ingredient of a recipe
+type Substitution struct {
+	// Use lists the replacement ingredients, scaled to the amount the recipe calls for when Scaled is set
+	// and in the proportions of Ratio otherwise
+	Use    []Ingredient   `json:"use"`
+	Scaled bool           `json:"scaled"`
+	Ratio  string         `json:"ratio"`
+	Labels []DietaryLabel `json:"labels"`
+	Note   string         `json:"note,omitempty"`
+}
+
+// IngredientSubstitutions lists the swaps proposed for one ingredient of a recipe
+type IngredientSubstitutions struct {
+	// Index is the position of the ingredient in the recipe's flat ingredient list
+	Index         int            `json:"index"`
+	Ingredient    Ingredient     `json:"ingredient"`
+	Substitutions []Substitution `json:"substitutions"`
+}
+
+// AppliedSubstitution records an ingredient of a recipe variant and the swap made for it
+type AppliedSubstitution struct {
+	Ingredient   Ingredient   `json:"ingredient"`
+	Substitution Substitution `json:"substitution"`
+}
+
+// RecipeVariant is a recipe with substitutions applied so that it meets a dietary label.
+// Variants are worked out on request and never saved.
+type RecipeVariant struct {
+	Label   DietaryLabel          `json:"label"`
+	Recipe  Recipe                `json:"recipe"`
+	Applied []AppliedSubstitution `json:"applied"`
+	// Unresolved lists the ingredients that still rule out the label because no swap is known
+	Unresolved []Ingredient `json:"unresolved"`
+}
+
+// SubstitutionReport holds the swaps proposed for a recipe and, when a dietary label was asked for,
+// the variant of the recipe that meets it
+type SubstitutionReport struct {
+	Substitutions []IngredientSubstitutions `json:"substitutions"`
+	Variant       *RecipeVariant            `json:"variant,omitempty"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// Trash holds a user's soft-deleted recipes and ratings until they are restored or purged
+type Trash struct {
+	Recipes []Recipe `json:"recipes"`
+	Ratings []Rating `json:"ratings"`
+}
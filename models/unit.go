@@ -0,0 +1,89 @@
+package models
+
+import "strings"
+
+// Canonical unit names stored on ingredients
+const (
+	UnitTeaspoon   = "tsp"
+	UnitTablespoon = "tbsp"
+	UnitFluidOunce = "fl oz"
+	UnitCup        = "cup"
+	UnitPint       = "pt"
+	UnitQuart      = "qt"
+	UnitGallon     = "gal"
+	UnitMilliliter = "ml"
+	UnitLiter      = "l"
+	UnitGram       = "g"
+	UnitKilogram   = "kg"
+	UnitOunce      = "oz"
+	UnitPound      = "lb"
+	UnitCelsius    = "°C"
+	UnitFahrenheit = "°F"
+	UnitBatch      = "batch"
+	UnitServing    = "serving"
+)
+
+// unitAliases maps the spellings found in recipes to canonical unit names
+var unitAliases = map[string]string{
+	"tsp": UnitTeaspoon, "tsps": UnitTeaspoon, "teaspoon": UnitTeaspoon, "teaspoons": UnitTeaspoon,
+	"tbsp": UnitTablespoon, "tbsps": UnitTablespoon, "tbs": UnitTablespoon, "tbl": UnitTablespoon, "tablespoon": UnitTablespoon, "tablespoons": UnitTablespoon,
+	"fl oz": UnitFluidOunce, "fl. oz": UnitFluidOunce, "floz": UnitFluidOunce, "fluid ounce": UnitFluidOunce, "fluid ounces": UnitFluidOunce,
+	"c": UnitCup, "cup": UnitCup, "cups": UnitCup,
+	"pt": UnitPint, "pint": UnitPint, "pints": UnitPint,
+	"qt": UnitQuart, "quart": UnitQuart, "quarts": UnitQuart,
+	"gal": UnitGallon, "gallon": UnitGallon, "gallons": UnitGallon,
+	"ml": UnitMilliliter, "milliliter": UnitMilliliter, "milliliters": UnitMilliliter, "millilitre": UnitMilliliter, "millilitres": UnitMilliliter,
+	"l": UnitLiter, "liter": UnitLiter, "liters": UnitLiter, "litre": UnitLiter, "litres": UnitLiter,
+	"g": UnitGram, "gr": UnitGram, "gram": UnitGram, "grams": UnitGram, "gramme": UnitGram, "grammes": UnitGram,
+	"kg": UnitKilogram, "kgs": UnitKilogram, "kilogram": UnitKilogram, "kilograms": UnitKilogram,
+	"oz": UnitOunce, "ounce": UnitOunce, "ounces": UnitOunce,
+	"lb": UnitPound, "lbs": UnitPound, "pound": UnitPound, "pounds": UnitPound,
+	"pinch": "pinch", "pinches": "pinch",
+	"dash": "dash", "dashes": "dash",
+	"clove": "clove", "cloves": "clove",
+	"can": "can", "cans": "can",
+	"slice": "slice", "slices": "slice",
+	"stick": "stick", "sticks": "stick",
+	"piece": "piece", "pieces": "piece",
+	"bunch": "bunch", "bunches": "bunch",
+	"sprig": "sprig", "sprigs": "sprig",
+	"handful": "handful", "handfuls": "handful",
+	"package": "package", "packages": "package", "pkg": "package",
+	"batch": UnitBatch, "batches": UnitBatch,
+	"serving": UnitServing, "servings": UnitServing,
+}
+
+// unitPlurals holds display plurals for units that are words rather than abbreviations
+var unitPlurals = map[string]string{
+	UnitCup:     "cups",
+	"pinch":     "pinches",
+	"dash":      "dashes",
+	"clove":     "cloves",
+	"can":       "cans",
+	"slice":     "slices",
+	"stick":     "sticks",
+	"piece":     "pieces",
+	"bunch":     "bunches",
+	"sprig":     "sprigs",
+	"handful":   "handfuls",
+	"package":   "packages",
+	UnitBatch:   "batches",
+	UnitServing: "servings",
+}
+
+// NormalizeUnit returns the canonical name for a unit spelling
+func NormalizeUnit(unit string) (string, bool) {
+	key := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(unit), "."))
+	canonical, ok := unitAliases[key]
+	return canonical, ok
+}
+
+// UnitLabel returns the display form of a canonical unit for the given amount
+func UnitLabel(unit string, plural bool) string {
+	if plural {
+		if label, ok := unitPlurals[unit]; ok {
+			return label
+		}
+	}
+	return unit
+}
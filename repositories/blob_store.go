@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBlobNotFound is returned when no blob is stored under a key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// ErrInvalidBlobKey is returned for keys that are not relative slash-separated paths
+var ErrInvalidBlobKey = errors.New("invalid blob key")
+
+// BlobStore defines the interface for storing binary content such as images under slash-separated keys
+type BlobStore interface {
+	Put(key string, content io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// FileSystemBlobStore implements BlobStore with one file per blob below a root directory
+type FileSystemBlobStore struct {
+	root string
+}
+
+// NewFileSystemBlobStore creates a blob store in the given directory, creating it if needed
+func NewFileSystemBlobStore(root string) (*FileSystemBlobStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSystemBlobStore{root: root}, nil
+}
+
+// Put stores content under key, replacing any existing blob. The content is written to a
+// temporary file first so readers never see a partial blob.
+func (s *FileSystemBlobStore) Put(key string, content io.Reader) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), name)
+}
+
+// Open returns a reader for the blob stored under key
+func (s *FileSystemBlobStore) Open(key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+// Delete removes the blob stored under key; deleting a missing blob is not an error
+func (s *FileSystemBlobStore) Delete(key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps a key to a file below the root, rejecting keys that would escape it
+func (s *FileSystemBlobStore) path(key string) (string, error) {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") ||
+		strings.Contains(key, "\\") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidBlobKey, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"playground/models"
+)
+
+// CollectionRepository defines the interface for collection storage operations
+type CollectionRepository interface {
+	FindByID(id string) (models.Collection, error)
+	FindByOwnerID(ownerID string) []models.Collection
+	Create(ownerID string, input models.CollectionInput) models.Collection
+	Update(id string, input models.CollectionInput) (models.Collection, error)
+	SetRecipes(id string, recipeIDs []string) (models.Collection, error)
+	Delete(id string) error
+	RemoveRecipe(recipeID string) int
+}
+
+// InMemoryCollectionRepository implements CollectionRepository with in-memory storage
+type InMemoryCollectionRepository struct {
+	collections map[string]models.Collection
+	mutex       sync.RWMutex
+}
+
+// NewInMemoryCollectionRepository creates a new in-memory collection repository
+func NewInMemoryCollectionRepository() *InMemoryCollectionRepository {
+	return &InMemoryCollectionRepository{
+		collections: make(map[string]models.Collection),
+	}
+}
+
+// FindByID returns a collection by ID
+func (r *InMemoryCollectionRepository) FindByID(id string) (models.Collection, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	collection, exists := r.collections[id]
+	if !exists {
+		return models.Collection{}, errors.New("collection not found")
+	}
+	return collection, nil
+}
+
+// FindByOwnerID returns all collections of a specific user
+func (r *InMemoryCollectionRepository) FindByOwnerID(ownerID string) []models.Collection {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.Collection, 0)
+	for _, collection := range r.collections {
+		if collection.OwnerID == ownerID {
+			result = append(result, collection)
+		}
+	}
+	return result
+}
+
+// Create adds a new empty collection owned by the given user
+func (r *InMemoryCollectionRepository) Create(ownerID string, input models.CollectionInput) models.Collection {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id := uuid.New().String()
+	collection := models.NewCollection(id, ownerID, input)
+
+	// Store a copy of the collection (immutable pattern)
+	r.collections[id] = collection
+
+	return collection
+}
+
+// Update modifies the name, description and visibility of an existing collection
+func (r *InMemoryCollectionRepository) Update(id string, input models.CollectionInput) (models.Collection, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	original, exists := r.collections[id]
+	if !exists {
+		return models.Collection{}, errors.New("collection not found")
+	}
+
+	// Create a new collection with updated fields (immutable pattern)
+	updated := models.UpdateCollection(original, input)
+	r.collections[id] = updated
+
+	return updated, nil
+}
+
+// SetRecipes replaces the ordered recipe IDs of a collection
+func (r *InMemoryCollectionRepository) SetRecipes(id string, recipeIDs []string) (models.Collection, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	collection, exists := r.collections[id]
+	if !exists {
+		return models.Collection{}, errors.New("collection not found")
+	}
+
+	collection.RecipeIDs = recipeIDs
+	collection.UpdatedAt = time.Now()
+	r.collections[id] = collection
+	return collection, nil
+}
+
+// Delete removes a collection; its recipes are not affected
+func (r *InMemoryCollectionRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.collections[id]; !exists {
+		return errors.New("collection not found")
+	}
+
+	delete(r.collections, id)
+	return nil
+}
+
+// RemoveRecipe takes a recipe out of every collection and returns how many collections contained it
+func (r *InMemoryCollectionRepository) RemoveRecipe(recipeID string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for id, collection := range r.collections {
+		remaining := make([]string, 0, len(collection.RecipeIDs))
+		for _, existing := range collection.RecipeIDs {
+			if existing != recipeID {
+				remaining = append(remaining, existing)
+			}
+		}
+		if len(remaining) != len(collection.RecipeIDs) {
+			collection.RecipeIDs = remaining
+			collection.UpdatedAt = time.Now()
+			r.collections[id] = collection
+			count++
+		}
+	}
+	return count
+}
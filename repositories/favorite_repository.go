@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+
+	"playground/models"
+)
+
+// FavoriteRepository defines the interface for favorite storage operations
+type FavoriteRepository interface {
+	FindByUserID(userID string) []models.Favorite
+	Exists(userID string, recipeID string) bool
+	CountByRecipeID(recipeID string) int
+	Add(userID string, recipeID string) models.Favorite
+	Remove(userID string, recipeID string) bool
+	DeleteByRecipeID(recipeID string) int
+}
+
+// favoriteKey identifies a favorite by user and recipe
+type favoriteKey struct {
+	userID   string
+	recipeID string
+}
+
+// InMemoryFavoriteRepository implements FavoriteRepository with in-memory storage
+type InMemoryFavoriteRepository struct {
+	favorites map[favoriteKey]models.Favorite
+	mutex     sync.RWMutex
+}
+
+// NewInMemoryFavoriteRepository creates a new in-memory favorite repository
+func NewInMemoryFavoriteRepository() *InMemoryFavoriteRepository {
+	return &InMemoryFavoriteRepository{
+		favorites: make(map[favoriteKey]models.Favorite),
+	}
+}
+
+// FindByUserID returns all favorites of a specific user
+func (r *InMemoryFavoriteRepository) FindByUserID(userID string) []models.Favorite {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.Favorite, 0)
+	for _, favorite := range r.favorites {
+		if favorite.UserID == userID {
+			result = append(result, favorite)
+		}
+	}
+	return result
+}
+
+// Exists reports whether a user has favorited a recipe
+func (r *InMemoryFavoriteRepository) Exists(userID string, recipeID string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	_, exists := r.favorites[favoriteKey{userID, recipeID}]
+	return exists
+}
+
+// CountByRecipeID returns how many users have favorited a recipe
+func (r *InMemoryFavoriteRepository) CountByRecipeID(recipeID string) int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	count := 0
+	for key := range r.favorites {
+		if key.recipeID == recipeID {
+			count++
+		}
+	}
+	return count
+}
+
+// Add favorites a recipe for a user; adding an existing favorite returns it unchanged
+func (r *InMemoryFavoriteRepository) Add(userID string, recipeID string) models.Favorite {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := favoriteKey{userID, recipeID}
+	if favorite, exists := r.favorites[key]; exists {
+		return favorite
+	}
+
+	favorite := models.Favorite{
+		UserID:    userID,
+		RecipeID:  recipeID,
+		CreatedAt: time.Now(),
+	}
+	r.favorites[key] = favorite
+	return favorite
+}
+
+// Remove takes a recipe out of a user's favorites and reports whether it was there
+func (r *InMemoryFavoriteRepository) Remove(userID string, recipeID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := favoriteKey{userID, recipeID}
+	if _, exists := r.favorites[key]; !exists {
+		return false
+	}
+	delete(r.favorites, key)
+	return true
+}
+
+// DeleteByRecipeID removes every favorite of a recipe and returns how many were removed
+func (r *InMemoryFavoriteRepository) DeleteByRecipeID(recipeID string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for key := range r.favorites {
+		if key.recipeID == recipeID {
+			delete(r.favorites, key)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"playground/models"
+)
+
+// MealPlanRepository defines the interface for meal plan storage operations
+type MealPlanRepository interface {
+	FindByID(id string) (models.MealPlan, error)
+	FindByOwnerID(ownerID string) []models.MealPlan
+	Create(ownerID string, input models.MealPlanInput) models.MealPlan
+	Update(id string, input models.MealPlanInput) (models.MealPlan, error)
+	SetEntries(id string, entries []models.MealPlanEntry) (models.MealPlan, error)
+	Delete(id string) error
+	RemoveRecipe(recipeID string) int
+}
+
+// InMemoryMealPlanRepository implements MealPlanRepository with in-memory storage
+type InMemoryMealPlanRepository struct {
+	plans map[string]models.MealPlan
+	mutex sync.RWMutex
+}
+
+// NewInMemoryMealPlanRepository creates a new in-memory meal plan repository
+func NewInMemoryMealPlanRepository() *InMemoryMealPlanRepository {
+	return &InMemoryMealPlanRepository{
+		plans: make(map[string]models.MealPlan),
+	}
+}
+
+// FindByID returns a meal plan by ID
+func (r *InMemoryMealPlanRepository) FindByID(id string) (models.MealPlan, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	plan, exists := r.plans[id]
+	if !exists {
+		return models.MealPlan{}, errors.New("meal plan not found")
+	}
+	return plan, nil
+}
+
+// FindByOwnerID returns all meal plans of a specific user
+func (r *InMemoryMealPlanRepository) FindByOwnerID(ownerID string) []models.MealPlan {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.MealPlan, 0)
+	for _, plan := range r.plans {
+		if plan.OwnerID == ownerID {
+			result = append(result, plan)
+		}
+	}
+	return result
+}
+
+// Create adds a new empty meal plan owned by the given user
+func (r *InMemoryMealPlanRepository) Create(ownerID string, input models.MealPlanInput) models.MealPlan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id := uuid.New().String()
+	plan := models.NewMealPlan(id, ownerID, input)
+
+	// Store a copy of the meal plan (immutable pattern)
+	r.plans[id] = plan
+
+	return plan
+}
+
+// Update renames an existing meal plan
+func (r *InMemoryMealPlanRepository) Update(id string, input models.MealPlanInput) (models.MealPlan, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	original, exists := r.plans[id]
+	if !exists {
+		return models.MealPlan{}, errors.New("meal plan not found")
+	}
+
+	// Create a new meal plan with updated fields (immutable pattern)
+	updated := models.UpdateMealPlan(original, input)
+	r.plans[id] = updated
+
+	return updated, nil
+}
+
+// SetEntries replaces the entries of a meal plan
+func (r *InMemoryMealPlanRepository) SetEntries(id string, entries []models.MealPlanEntry) (models.MealPlan, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	plan, exists := r.plans[id]
+	if !exists {
+		return models.MealPlan{}, errors.New("meal plan not found")
+	}
+
+	plan.Entries = entries
+	plan.UpdatedAt = time.Now()
+	r.plans[id] = plan
+	return plan, nil
+}
+
+// Delete removes a meal plan
+func (r *InMemoryMealPlanRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.plans[id]; !exists {
+		return errors.New("meal plan not found")
+	}
+
+	delete(r.plans, id)
+	return nil
+}
+
+// RemoveRecipe removes the entries of a recipe from every meal plan and returns how many were removed
+func (r *InMemoryMealPlanRepository) RemoveRecipe(recipeID string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for id, plan := range r.plans {
+		remaining := make([]models.MealPlanEntry, 0, len(plan.Entries))
+		for _, entry := range plan.Entries {
+			if entry.RecipeID != recipeID {
+				remaining = append(remaining, entry)
+			}
+		}
+		if removed := len(plan.Entries) - len(remaining); removed > 0 {
+			plan.Entries = remaining
+			plan.UpdatedAt = time.Now()
+			r.plans[id] = plan
+			count += removed
+		}
+	}
+	return count
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"playground/models"
+)
+
+// NoteRepository defines the interface for recipe note storage operations
+type NoteRepository interface {
+	Find(userID string, recipeID string) (models.RecipeNote, error)
+	Save(userID string, recipeID string, input models.RecipeNoteInput) models.RecipeNote
+	Delete(userID string, recipeID string) error
+	DeleteByRecipeID(recipeID string) int
+}
+
+// noteKey identifies a note by user and recipe; every user keeps at most one note per recipe
+type noteKey struct {
+	userID   string
+	recipeID string
+}
+
+// InMemoryNoteRepository implements NoteRepository with in-memory storage
+type InMemoryNoteRepository struct {
+	notes map[noteKey]models.RecipeNote
+	mutex sync.RWMutex
+}
+
+// NewInMemoryNoteRepository creates a new in-memory note repository
+func NewInMemoryNoteRepository() *InMemoryNoteRepository {
+	return &InMemoryNoteRepository{
+		notes: make(map[noteKey]models.RecipeNote),
+	}
+}
+
+// Find returns a user's note on a recipe
+func (r *InMemoryNoteRepository) Find(userID string, recipeID string) (models.RecipeNote, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	note, exists := r.notes[noteKey{userID, recipeID}]
+	if !exists {
+		return models.RecipeNote{}, errors.New("note not found")
+	}
+	return note, nil
+}
+
+// Save creates or replaces a user's note on a recipe
+func (r *InMemoryNoteRepository) Save(userID string, recipeID string, input models.RecipeNoteInput) models.RecipeNote {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	key := noteKey{userID, recipeID}
+	note, exists := r.notes[key]
+	if !exists {
+		note = models.RecipeNote{
+			RecipeID:  recipeID,
+			UserID:    userID,
+			CreatedAt: now,
+		}
+	}
+	note.Text = input.Text
+	note.UpdatedAt = now
+
+	r.notes[key] = note
+	return note
+}
+
+// Delete removes a user's note on a recipe
+func (r *InMemoryNoteRepository) Delete(userID string, recipeID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := noteKey{userID, recipeID}
+	if _, exists := r.notes[key]; !exists {
+		return errors.New("note not found")
+	}
+	delete(r.notes, key)
+	return nil
+}
+
+// DeleteByRecipeID removes every note on a recipe and returns how many were removed
+func (r *InMemoryNoteRepository) DeleteByRecipeID(recipeID string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for key := range r.notes {
+		if key.recipeID == recipeID {
+			delete(r.notes, key)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"playground/models"
+)
+
+// PantryRepository defines the interface for pantry item storage operations
+type PantryRepository interface {
+	FindByID(id string) (models.PantryItem, error)
+	FindByOwnerID(ownerID string) []models.PantryItem
+	Create(ownerID string, input models.PantryItemInput) models.PantryItem
+	Update(id string, input models.PantryItemInput) (models.PantryItem, error)
+	Delete(id string) error
+}
+
+// InMemoryPantryRepository implements PantryRepository with in-memory storage
+type InMemoryPantryRepository struct {
+	items map[string]models.PantryItem
+	mutex sync.RWMutex
+}
+
+// NewInMemoryPantryRepository creates a new in-memory pantry repository
+func NewInMemoryPantryRepository() *InMemoryPantryRepository {
+	return &InMemoryPantryRepository{
+		items: make(map[string]models.PantryItem),
+	}
+}
+
+// FindByID returns a pantry item by ID
+func (r *InMemoryPantryRepository) FindByID(id string) (models.PantryItem, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	item, exists := r.items[id]
+	if !exists {
+		return models.PantryItem{}, errors.New("pantry item not found")
+	}
+	return item, nil
+}
+
+// FindByOwnerID returns all pantry items of a specific user
+func (r *InMemoryPantryRepository) FindByOwnerID(ownerID string) []models.PantryItem {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.PantryItem, 0)
+	for _, item := range r.items {
+		if item.OwnerID == ownerID {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Create adds a new pantry item
+func (r *InMemoryPantryRepository) Create(ownerID string, input models.PantryItemInput) models.PantryItem {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	item := models.NewPantryItem(uuid.New().String(), ownerID, input)
+	r.items[item.ID] = item
+	return item
+}
+
+// Update modifies an existing pantry item
+func (r *InMemoryPantryRepository) Update(id string, input models.PantryItemInput) (models.PantryItem, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	item, exists := r.items[id]
+	if !exists {
+		return models.PantryItem{}, errors.New("pantry item not found")
+	}
+
+	updated := models.UpdatePantryItem(item, input)
+	r.items[id] = updated
+	return updated, nil
+}
+
+// Delete removes a pantry item
+func (r *InMemoryPantryRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.items[id]; !exists {
+		return errors.New("pantry item not found")
+	}
+	delete(r.items, id)
+	return nil
+}
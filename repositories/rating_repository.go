@@ -3,6 +3,7 @@ package repositories
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"playground/models"
@@ -17,6 +18,12 @@ type RatingRepository interface {
 	Create(recipeID string, userID string, input models.RatingInput) models.Rating
 	Update(id string, input models.RatingInput) (models.Rating, error)
 	Delete(id string) error
+	DeleteByRecipeID(recipeID string, deletedAt time.Time) int
+	FindDeletedByUserID(userID string) []models.Rating
+	Restore(id string) (models.Rating, error)
+	RestoreByRecipeID(recipeID string, deletedAt time.Time) int
+	Purge(deletedBefore time.Time) int
+	PurgeByRecipeID(recipeID string) int
 }
 
 // InMemoryRatingRepository implements RatingRepository with in-memory storage
@@ -32,52 +39,54 @@ func NewInMemoryRatingRepository() *InMemoryRatingRepository {
 	}
 }
 
-// FindAll returns all ratings
+// FindAll returns all ratings that are not in the trash
 func (r *InMemoryRatingRepository) FindAll() []models.Rating {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	result := make([]models.Rating, 0, len(r.ratings))
 	for _, rating := range r.ratings {
-		result = append(result, rating)
+		if !rating.IsDeleted() {
+			result = append(result, rating)
+		}
 	}
 	return result
 }
 
-// FindByID returns a rating by ID
+// FindByID returns a rating by ID unless it is in the trash
 func (r *InMemoryRatingRepository) FindByID(id string) (models.Rating, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	rating, exists := r.ratings[id]
-	if !exists {
+	if !exists || rating.IsDeleted() {
 		return models.Rating{}, errors.New("rating not found")
 	}
 	return rating, nil
 }
 
-// FindByRecipeID returns all ratings for a specific recipe
+// FindByRecipeID returns all ratings for a specific recipe that are not in the trash
 func (r *InMemoryRatingRepository) FindByRecipeID(recipeID string) []models.Rating {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	result := make([]models.Rating, 0)
 	for _, rating := range r.ratings {
-		if rating.RecipeID == recipeID {
+		if rating.RecipeID == recipeID && !rating.IsDeleted() {
 			result = append(result, rating)
 		}
 	}
 	return result
 }
 
-// FindByUserID returns all ratings by a specific user
+// FindByUserID returns all ratings by a specific user that are not in the trash
 func (r *InMemoryRatingRepository) FindByUserID(userID string) []models.Rating {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	result := make([]models.Rating, 0)
 	for _, rating := range r.ratings {
-		if rating.UserID == userID {
+		if rating.UserID == userID && !rating.IsDeleted() {
 			result = append(result, rating)
 		}
 	}
@@ -91,10 +100,10 @@ func (r *InMemoryRatingRepository) Create(recipeID string, userID string, input
 
 	id := uuid.New().String()
 	rating := models.NewRating(id, recipeID, userID, input)
-	
+
 	// Store a copy of the rating (immutable pattern)
 	r.ratings[id] = rating
-	
+
 	return rating
 }
 
@@ -104,29 +113,126 @@ func (r *InMemoryRatingRepository) Update(id string, input models.RatingInput) (
 	defer r.mutex.Unlock()
 
 	original, exists := r.ratings[id]
-	if !exists {
+	if !exists || original.IsDeleted() {
 		return models.Rating{}, errors.New("rating not found")
 	}
 
 	// Create a new rating with updated fields (immutable pattern)
 	updated := models.UpdateRating(original, input)
-	
+
 	// Store the updated rating
 	r.ratings[id] = updated
-	
+
 	return updated, nil
 }
 
-// Delete removes a rating
+// Delete moves a rating to the trash by setting its deletion time
 func (r *InMemoryRatingRepository) Delete(id string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	_, exists := r.ratings[id]
-	if !exists {
+	rating, exists := r.ratings[id]
+	if !exists || rating.IsDeleted() {
 		return errors.New("rating not found")
 	}
 
-	delete(r.ratings, id)
+	now := time.Now()
+	rating.DeletedAt = &now
+	r.ratings[id] = rating
 	return nil
-}
\ No newline at end of file
+}
+
+// DeleteByRecipeID moves every rating of a recipe to the trash with the given deletion time
+// and returns how many were deleted
+func (r *InMemoryRatingRepository) DeleteByRecipeID(recipeID string, deletedAt time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for id, rating := range r.ratings {
+		if rating.RecipeID == recipeID && !rating.IsDeleted() {
+			rating.DeletedAt = &deletedAt
+			r.ratings[id] = rating
+			count++
+		}
+	}
+	return count
+}
+
+// FindDeletedByUserID returns all ratings by a specific user that are in the trash
+func (r *InMemoryRatingRepository) FindDeletedByUserID(userID string) []models.Rating {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.Rating, 0)
+	for _, rating := range r.ratings {
+		if rating.UserID == userID && rating.IsDeleted() {
+			result = append(result, rating)
+		}
+	}
+	return result
+}
+
+// Restore takes a rating out of the trash
+func (r *InMemoryRatingRepository) Restore(id string) (models.Rating, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rating, exists := r.ratings[id]
+	if !exists || !rating.IsDeleted() {
+		return models.Rating{}, errors.New("rating not found in trash")
+	}
+
+	rating.DeletedAt = nil
+	r.ratings[id] = rating
+	return rating, nil
+}
+
+// RestoreByRecipeID takes the ratings of a recipe that were deleted at exactly the given time
+// out of the trash and returns how many were restored
+func (r *InMemoryRatingRepository) RestoreByRecipeID(recipeID string, deletedAt time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for id, rating := range r.ratings {
+		if rating.RecipeID == recipeID && rating.IsDeleted() && rating.DeletedAt.Equal(deletedAt) {
+			rating.DeletedAt = nil
+			r.ratings[id] = rating
+			count++
+		}
+	}
+	return count
+}
+
+// Purge permanently removes ratings that were moved to the trash before the given time
+// and returns how many were removed
+func (r *InMemoryRatingRepository) Purge(deletedBefore time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for id, rating := range r.ratings {
+		if rating.IsDeleted() && rating.DeletedAt.Before(deletedBefore) {
+			delete(r.ratings, id)
+			count++
+		}
+	}
+	return count
+}
+
+// PurgeByRecipeID permanently removes every rating of a recipe, in the trash or not,
+// and returns how many were removed
+func (r *InMemoryRatingRepository) PurgeByRecipeID(recipeID string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for id, rating := range r.ratings {
+		if rating.RecipeID == recipeID {
+			delete(r.ratings, id)
+			count++
+		}
+	}
+	return count
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"playground/models"
@@ -12,91 +13,293 @@ import (
 type RecipeRepository interface {
 	FindAll() []models.Recipe
 	FindByID(id string) (models.Recipe, error)
-	Create(input models.RecipeInput) models.Recipe
-	Update(id string, input models.RecipeInput) (models.Recipe, error)
+	FindByAuthorID(authorID string) []models.Recipe
+	Create(authorID string, input models.RecipeInput) models.Recipe
+	CreateFork(authorID string, parent models.Recipe) models.Recipe
+	FindForks(parentID string) []models.Recipe
+	Update(id string, editorID string, input models.RecipeInput) (models.Recipe, error)
+	SetStatus(id string, status models.RecipeStatus) (models.Recipe, error)
+	SetImages(id string, images []models.RecipeImage, coverImageID string) (models.Recipe, error)
+	SetDiet(id string, diet models.DietInfo) (models.Recipe, error)
 	Delete(id string) error
+	FindDeletedByID(id string) (models.Recipe, error)
+	FindDeletedByAuthorID(authorID string) []models.Recipe
+	Restore(id string) (models.Recipe, error)
+	Purge(deletedBefore time.Time) []models.Recipe
+	FindRevisions(id string) ([]models.RecipeRevision, error)
+	FindRevision(id string, number int) (models.RecipeRevision, error)
 }
 
 // InMemoryRecipeRepository implements RecipeRepository with in-memory storage
 type InMemoryRecipeRepository struct {
-	recipes map[string]models.Recipe
-	mutex   sync.RWMutex
+	recipes   map[string]models.Recipe
+	revisions map[string][]models.RecipeRevision
+	mutex     sync.RWMutex
 }
 
 // NewInMemoryRecipeRepository creates a new in-memory recipe repository
 func NewInMemoryRecipeRepository() *InMemoryRecipeRepository {
 	return &InMemoryRecipeRepository{
-		recipes: make(map[string]models.Recipe),
+		recipes:   make(map[string]models.Recipe),
+		revisions: make(map[string][]models.RecipeRevision),
 	}
 }
 
-// FindAll returns all recipes
+// FindAll returns all recipes that are not in the trash
 func (r *InMemoryRecipeRepository) FindAll() []models.Recipe {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	result := make([]models.Recipe, 0, len(r.recipes))
 	for _, recipe := range r.recipes {
-		result = append(result, recipe)
+		if !recipe.IsDeleted() {
+			result = append(result, recipe)
+		}
 	}
 	return result
 }
 
-// FindByID returns a recipe by ID
+// FindByID returns a recipe by ID unless it is in the trash
 func (r *InMemoryRecipeRepository) FindByID(id string) (models.Recipe, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	recipe, exists := r.recipes[id]
-	if !exists {
+	if !exists || recipe.IsDeleted() {
 		return models.Recipe{}, errors.New("recipe not found")
 	}
 	return recipe, nil
 }
 
-// Create adds a new recipe
-func (r *InMemoryRecipeRepository) Create(input models.RecipeInput) models.Recipe {
+// FindByAuthorID returns all recipes created by a specific user that are not in the trash
+func (r *InMemoryRecipeRepository) FindByAuthorID(authorID string) []models.Recipe {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.Recipe, 0)
+	for _, recipe := range r.recipes {
+		if recipe.AuthorID == authorID && !recipe.IsDeleted() {
+			result = append(result, recipe)
+		}
+	}
+	return result
+}
+
+// Create adds a new recipe owned by the given author
+func (r *InMemoryRecipeRepository) Create(authorID string, input models.RecipeInput) models.Recipe {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	id := uuid.New().String()
-	recipe := models.NewRecipe(id, input)
-	
-	// Store a copy of the recipe (immutable pattern)
+	recipe := models.NewRecipe(id, authorID, input)
+
+	// Store a copy of the recipe (immutable pattern) and its first revision
 	r.recipes[id] = recipe
-	
+	r.revisions[id] = []models.RecipeRevision{models.NewRecipeRevision(recipe, authorID)}
+
 	return recipe
 }
 
-// Update modifies an existing recipe
-func (r *InMemoryRecipeRepository) Update(id string, input models.RecipeInput) (models.Recipe, error) {
+// CreateFork adds a new recipe owned by the given author that copies the content of parent
+func (r *InMemoryRecipeRepository) CreateFork(authorID string, parent models.Recipe) models.Recipe {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id := uuid.New().String()
+	fork := models.NewFork(id, authorID, parent)
+
+	// Store a copy of the fork (immutable pattern) and its first revision
+	r.recipes[id] = fork
+	r.revisions[id] = []models.RecipeRevision{models.NewRecipeRevision(fork, authorID)}
+
+	return fork
+}
+
+// FindForks returns the recipes forked directly from a recipe that are not in the trash
+func (r *InMemoryRecipeRepository) FindForks(parentID string) []models.Recipe {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.Recipe, 0)
+	for _, recipe := range r.recipes {
+		if recipe.ForkedFrom != nil && recipe.ForkedFrom.RecipeID == parentID && !recipe.IsDeleted() {
+			result = append(result, recipe)
+		}
+	}
+	return result
+}
+
+// Update modifies an existing recipe and records the result as a new revision
+func (r *InMemoryRecipeRepository) Update(id string, editorID string, input models.RecipeInput) (models.Recipe, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	original, exists := r.recipes[id]
-	if !exists {
+	if !exists || original.IsDeleted() {
 		return models.Recipe{}, errors.New("recipe not found")
 	}
 
 	// Create a new recipe with updated fields (immutable pattern)
 	updated := models.UpdateRecipe(original, input)
-	
-	// Store the updated recipe
+
+	// Store the updated recipe and append its revision
 	r.recipes[id] = updated
-	
+	r.revisions[id] = append(r.revisions[id], models.NewRecipeRevision(updated, editorID))
+
 	return updated, nil
 }
 
-// Delete removes a recipe
+// SetStatus changes the lifecycle state of a recipe without creating a revision
+func (r *InMemoryRecipeRepository) SetStatus(id string, status models.RecipeStatus) (models.Recipe, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recipe, exists := r.recipes[id]
+	if !exists || recipe.IsDeleted() {
+		return models.Recipe{}, errors.New("recipe not found")
+	}
+
+	recipe.Status = status
+	recipe.UpdatedAt = time.Now()
+	r.recipes[id] = recipe
+	return recipe, nil
+}
+
+// SetImages replaces the images and cover image of a recipe without creating a revision
+func (r *InMemoryRecipeRepository) SetImages(id string, images []models.RecipeImage, coverImageID string) (models.Recipe, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recipe, exists := r.recipes[id]
+	if !exists || recipe.IsDeleted() {
+		return models.Recipe{}, errors.New("recipe not found")
+	}
+
+	recipe.Images = images
+	recipe.CoverImageID = coverImageID
+	recipe.UpdatedAt = time.Now()
+	r.recipes[id] = recipe
+	return recipe, nil
+}
+
+// SetDiet replaces the derived diet of a recipe without creating a revision or touching its update time
+func (r *InMemoryRecipeRepository) SetDiet(id string, diet models.DietInfo) (models.Recipe, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recipe, exists := r.recipes[id]
+	if !exists || recipe.IsDeleted() {
+		return models.Recipe{}, errors.New("recipe not found")
+	}
+
+	recipe.Diet = diet
+	r.recipes[id] = recipe
+	return recipe, nil
+}
+
+// Delete moves a recipe to the trash by setting its deletion time
 func (r *InMemoryRecipeRepository) Delete(id string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	_, exists := r.recipes[id]
-	if !exists {
+	recipe, exists := r.recipes[id]
+	if !exists || recipe.IsDeleted() {
 		return errors.New("recipe not found")
 	}
 
-	delete(r.recipes, id)
+	now := time.Now()
+	recipe.DeletedAt = &now
+	r.recipes[id] = recipe
 	return nil
-}
\ No newline at end of file
+}
+
+// FindDeletedByID returns a recipe by ID only if it is in the trash
+func (r *InMemoryRecipeRepository) FindDeletedByID(id string) (models.Recipe, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	recipe, exists := r.recipes[id]
+	if !exists || !recipe.IsDeleted() {
+		return models.Recipe{}, errors.New("recipe not found in trash")
+	}
+	return recipe, nil
+}
+
+// FindDeletedByAuthorID returns all recipes created by a specific user that are in the trash
+func (r *InMemoryRecipeRepository) FindDeletedByAuthorID(authorID string) []models.Recipe {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.Recipe, 0)
+	for _, recipe := range r.recipes {
+		if recipe.AuthorID == authorID && recipe.IsDeleted() {
+			result = append(result, recipe)
+		}
+	}
+	return result
+}
+
+// Restore takes a recipe out of the trash
+func (r *InMemoryRecipeRepository) Restore(id string) (models.Recipe, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recipe, exists := r.recipes[id]
+	if !exists || !recipe.IsDeleted() {
+		return models.Recipe{}, errors.New("recipe not found in trash")
+	}
+
+	recipe.DeletedAt = nil
+	r.recipes[id] = recipe
+	return recipe, nil
+}
+
+// Purge permanently removes recipes that were moved to the trash before the given time,
+// along with their revisions, and returns the removed recipes
+func (r *InMemoryRecipeRepository) Purge(deletedBefore time.Time) []models.Recipe {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := make([]models.Recipe, 0)
+	for id, recipe := range r.recipes {
+		if recipe.IsDeleted() && recipe.DeletedAt.Before(deletedBefore) {
+			purged = append(purged, recipe)
+			delete(r.recipes, id)
+			delete(r.revisions, id)
+		}
+	}
+	return purged
+}
+
+// FindRevisions returns every revision of a recipe, oldest first
+func (r *InMemoryRecipeRepository) FindRevisions(id string) ([]models.RecipeRevision, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	revisions, exists := r.revisions[id]
+	if !exists {
+		return nil, errors.New("recipe not found")
+	}
+
+	result := make([]models.RecipeRevision, len(revisions))
+	copy(result, revisions)
+	return result, nil
+}
+
+// FindRevision returns a single revision of a recipe by number
+func (r *InMemoryRecipeRepository) FindRevision(id string, number int) (models.RecipeRevision, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	revisions, exists := r.revisions[id]
+	if !exists {
+		return models.RecipeRevision{}, errors.New("recipe not found")
+	}
+
+	for _, revision := range revisions {
+		if revision.Number == number {
+			return revision, nil
+		}
+	}
+	return models.RecipeRevision{}, errors.New("revision not found")
+}
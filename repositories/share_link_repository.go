@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"playground/models"
+)
+
+// ShareLinkRepository defines the interface for share link storage operations
+type ShareLinkRepository interface {
+	FindByID(id string) (models.ShareLink, error)
+	FindByRecipeID(recipeID string) []models.ShareLink
+	Create(recipeID string, createdBy string, expiresAt time.Time) models.ShareLink
+	Revoke(id string) (models.ShareLink, error)
+	DeleteByRecipeID(recipeID string) int
+}
+
+// InMemoryShareLinkRepository implements ShareLinkRepository with in-memory storage
+type InMemoryShareLinkRepository struct {
+	links map[string]models.ShareLink
+	mutex sync.RWMutex
+}
+
+// NewInMemoryShareLinkRepository creates a new in-memory share link repository
+func NewInMemoryShareLinkRepository() *InMemoryShareLinkRepository {
+	return &InMemoryShareLinkRepository{
+		links: make(map[string]models.ShareLink),
+	}
+}
+
+// FindByID returns a share link by ID
+func (r *InMemoryShareLinkRepository) FindByID(id string) (models.ShareLink, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	link, exists := r.links[id]
+	if !exists {
+		return models.ShareLink{}, errors.New("share link not found")
+	}
+	return link, nil
+}
+
+// FindByRecipeID returns all share links for a specific recipe, including revoked and expired ones
+func (r *InMemoryShareLinkRepository) FindByRecipeID(recipeID string) []models.ShareLink {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.ShareLink, 0)
+	for _, link := range r.links {
+		if link.RecipeID == recipeID {
+			result = append(result, link)
+		}
+	}
+	return result
+}
+
+// Create adds a new share link
+func (r *InMemoryShareLinkRepository) Create(recipeID string, createdBy string, expiresAt time.Time) models.ShareLink {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id := uuid.New().String()
+	link := models.NewShareLink(id, recipeID, createdBy, expiresAt)
+	r.links[id] = link
+
+	return link
+}
+
+// Revoke marks a share link as revoked so its token stops working
+func (r *InMemoryShareLinkRepository) Revoke(id string) (models.ShareLink, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	link, exists := r.links[id]
+	if !exists {
+		return models.ShareLink{}, errors.New("share link not found")
+	}
+
+	if link.RevokedAt == nil {
+		now := time.Now()
+		link.RevokedAt = &now
+		r.links[id] = link
+	}
+	return link, nil
+}
+
+// DeleteByRecipeID permanently removes every share link for a recipe and returns how many were removed
+func (r *InMemoryShareLinkRepository) DeleteByRecipeID(recipeID string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for id, link := range r.links {
+		if link.RecipeID == recipeID {
+			delete(r.links, id)
+			count++
+		}
+	}
+	return count
+}
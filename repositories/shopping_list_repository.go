@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"playground/models"
+)
+
+// ShoppingListRepository defines the interface for shopping list storage operations
+type ShoppingListRepository interface {
+	FindByID(id string) (models.ShoppingList, error)
+	FindByOwnerID(ownerID string) []models.ShoppingList
+	Create(ownerID string, name string, items []models.ShoppingListItem) models.ShoppingList
+	SetItems(id string, items []models.ShoppingListItem) (models.ShoppingList, error)
+	Delete(id string) error
+}
+
+// InMemoryShoppingListRepository implements ShoppingListRepository with in-memory storage
+type InMemoryShoppingListRepository struct {
+	lists map[string]models.ShoppingList
+	mutex sync.RWMutex
+}
+
+// NewInMemoryShoppingListRepository creates a new in-memory shopping list repository
+func NewInMemoryShoppingListRepository() *InMemoryShoppingListRepository {
+	return &InMemoryShoppingListRepository{
+		lists: make(map[string]models.ShoppingList),
+	}
+}
+
+// FindByID returns a shopping list by ID
+func (r *InMemoryShoppingListRepository) FindByID(id string) (models.ShoppingList, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	list, exists := r.lists[id]
+	if !exists {
+		return models.ShoppingList{}, errors.New("shopping list not found")
+	}
+	return list, nil
+}
+
+// FindByOwnerID returns all shopping lists of a specific user
+func (r *InMemoryShoppingListRepository) FindByOwnerID(ownerID string) []models.ShoppingList {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.ShoppingList, 0)
+	for _, list := range r.lists {
+		if list.OwnerID == ownerID {
+			result = append(result, list)
+		}
+	}
+	return result
+}
+
+// Create adds a new shopping list with the given items
+func (r *InMemoryShoppingListRepository) Create(ownerID string, name string, items []models.ShoppingListItem) models.ShoppingList {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	list := models.NewShoppingList(uuid.New().String(), ownerID, name, items)
+	r.lists[list.ID] = list
+	return list
+}
+
+// SetItems replaces the items of a shopping list
+func (r *InMemoryShoppingListRepository) SetItems(id string, items []models.ShoppingListItem) (models.ShoppingList, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	list, exists := r.lists[id]
+	if !exists {
+		return models.ShoppingList{}, errors.New("shopping list not found")
+	}
+
+	list.Items = items
+	list.UpdatedAt = time.Now()
+	r.lists[id] = list
+	return list, nil
+}
+
+// Delete removes a shopping list
+func (r *InMemoryShoppingListRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.lists[id]; !exists {
+		return errors.New("shopping list not found")
+	}
+	delete(r.lists, id)
+	return nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import "playground/models"
+
+// aisleRules maps ingredient phrases to the store aisle they are found in. Longer phrases are tried
+// first, so "coconut milk" is pantry rather than dairy and "frozen peas" is frozen rather than produce.
+var aisleRules = map[string]models.AisleCategory{
+	// Produce
+	"onion": models.AisleProduce, "garlic": models.AisleProduce, "shallot": models.AisleProduce, "leek": models.AisleProduce,
+	"potato": models.AisleProduce, "sweet potato": models.AisleProduce, "carrot": models.AisleProduce, "celery": models.AisleProduce,
+	"tomato": models.AisleProduce, "cucumber": models.AisleProduce, "pepper": models.AisleProduce, "bell pepper": models.AisleProduce,
+	"chili": models.AisleProduce, "zucchini": models.AisleProduce, "eggplant": models.AisleProduce, "squash": models.AisleProduce,
+	"pumpkin": models.AisleProduce, "mushroom": models.AisleProduce, "spinach": models.AisleProduce, "kale": models.AisleProduce,
+	"lettuce": models.AisleProduce, "cabbage": models.AisleProduce, "broccoli": models.AisleProduce, "cauliflower": models.AisleProduce,
+	"pea": models.AisleProduce, "bean sprout": models.AisleProduce, "green bean": models.AisleProduce, "corn": models.AisleProduce,
+	"avocado": models.AisleProduce, "ginger": models.AisleProduce, "scallion": models.AisleProduce, "spring onion": models.AisleProduce,
+	"apple": models.AisleProduce, "banana": models.AisleProduce, "lemon": models.AisleProduce, "lime": models.AisleProduce,
+	"orange": models.AisleProduce, "berry": models.AisleProduce, "strawberry": models.AisleProduce, "blueberry": models.AisleProduce,
+	"raspberry": models.AisleProduce, "grape": models.AisleProduce, "pear": models.AisleProduce, "peach": models.AisleProduce,
+	"mango": models.AisleProduce, "pineapple": models.AisleProduce, "cherry": models.AisleProduce,
+	"basil": models.AisleProduce, "parsley": models.AisleProduce, "cilantro": models.AisleProduce, "coriander": models.AisleProduce,
+	"mint": models.AisleProduce, "dill": models.AisleProduce, "chive": models.AisleProduce, "fresh thyme": models.AisleProduce,
+	"fresh rosemary": models.AisleProduce, "lemon juice": models.AisleProduce, "lime juice": models.AisleProduce,
+
+	// Bakery
+	"bread": models.AisleBakery, "baguette": models.AisleBakery, "bun": models.AisleBakery, "roll": models.AisleBakery,
+	"tortilla": models.AisleBakery, "pita": models.AisleBakery, "croissant": models.AisleBakery, "brioche": models.AisleBakery,
+
+	// Meat and seafood
+	"beef": models.AisleMeat, "pork": models.AisleMeat, "chicken": models.AisleMeat, "lamb": models.AisleMeat, "turkey": models.AisleMeat,
+	"bacon": models.AisleMeat, "ham": models.AisleMeat, "sausage": models.AisleMeat, "veal": models.AisleMeat, "duck": models.AisleMeat,
+	"mince": models.AisleMeat, "ground beef": models.AisleMeat, "prosciutto": models.AisleMeat, "chorizo": models.AisleMeat,
+	"fish": models.AisleMeat, "salmon": models.AisleMeat, "tuna": models.AisleMeat, "cod": models.AisleMeat, "trout": models.AisleMeat,
+	"shrimp": models.AisleMeat, "prawn": models.AisleMeat, "crab": models.AisleMeat, "mussel": models.AisleMeat, "clam": models.AisleMeat,
+	"scallop": models.AisleMeat, "squid": models.AisleMeat,
+
+	// Dairy and eggs
+	"milk": models.AisleDairy, "butter": models.AisleDairy, "cream": models.AisleDairy, "cheese": models.AisleDairy,
+	"cheddar": models.AisleDairy, "parmesan": models.AisleDairy, "mozzarella": models.AisleDairy, "ricotta": models.AisleDairy,
+	"feta": models.AisleDairy, "mascarpone": models.AisleDairy, "yogurt": models.AisleDairy, "yoghurt": models.AisleDairy,
+	"buttermilk": models.AisleDairy, "sour cream": models.AisleDairy, "creme fraiche": models.AisleDairy, "egg": models.AisleDairy,
+	"tofu": models.AisleDairy,
+
+	// Frozen
+	"frozen": models.AisleFrozen, "ice cream": models.AisleFrozen, "puff pastry": models.AisleFrozen,
+
+	// Pantry
+	"flour": models.AislePantry, "sugar": models.AislePantry, "brown sugar": models.AislePantry, "rice": models.AislePantry,
+	"pasta": models.AislePantry, "spaghetti": models.AislePantry, "noodle": models.AislePantry, "oat": models.AislePantry,
+	"couscous": models.AislePantry, "quinoa": models.AislePantry, "lentil": models.AislePantry, "bean": models.AislePantry,
+	"chickpea": models.AislePantry, "oil": models.AislePantry, "olive oil": models.AislePantry, "vinegar": models.AislePantry,
+	"soy sauce": models.AislePantry, "fish sauce": models.AislePantry, "stock": models.AislePantry, "broth": models.AislePantry,
+	"tomato paste": models.AislePantry, "canned tomato": models.AislePantry, "coconut milk": models.AislePantry,
+	"honey": models.AislePantry, "maple syrup": models.AislePantry, "peanut butter": models.AislePantry, "jam": models.AislePantry,
+	"baking powder": models.AislePantry, "baking soda": models.AislePantry, "yeast": models.AislePantry,
+	"cocoa powder": models.AislePantry, "chocolate": models.AislePantry, "chocolate chip": models.AislePantry,
+	"vanilla": models.AislePantry, "breadcrumb": models.AislePantry, "panko": models.AislePantry, "nut": models.AislePantry,
+	"almond": models.AislePantry, "walnut": models.AislePantry, "raisin": models.AislePantry, "mustard": models.AislePantry,
+	"chicken stock": models.AislePantry, "chicken broth": models.AislePantry, "beef stock": models.AislePantry,
+	"beef broth": models.AislePantry, "coconut cream": models.AislePantry, "ketchup": models.AislePantry, "mayonnaise": models.AislePantry, "tahini": models.AislePantry, "cornstarch": models.AislePantry,
+
+	// Spices
+	"salt": models.AisleSpices, "black pepper": models.AisleSpices, "peppercorn": models.AisleSpices, "cumin": models.AisleSpices,
+	"paprika": models.AisleSpices, "cinnamon": models.AisleSpices, "nutmeg": models.AisleSpices, "oregano": models.AisleSpices,
+	"thyme": models.AisleSpices, "rosemary": models.AisleSpices, "bay leaf": models.AisleSpices, "chili powder": models.AisleSpices,
+	"chili flake": models.AisleSpices, "turmeric": models.AisleSpices, "curry powder": models.AisleSpices, "clove": models.AisleSpices,
+	"cardamom": models.AisleSpices, "garam masala": models.AisleSpices, "ground ginger": models.AisleSpices,
+	"ground coriander": models.AisleSpices, "garlic powder": models.AisleSpices, "onion powder": models.AisleSpices,
+	"pepper flake": models.AisleSpices,
+
+	// Beverages
+	"water": models.AisleBeverages, "coffee": models.AisleBeverages, "tea": models.AisleBeverages, "juice": models.AisleBeverages,
+	"wine": models.AisleBeverages, "beer": models.AisleBeverages, "soda": models.AisleBeverages,
+}
+
+// aislePhrases are the aisle rule keys, longest first so specific phrases are tried first
+var aislePhrases = func() []string {
+	phrases := make([]string, 0, len(aisleRules))
+	for phrase := range aisleRules {
+		phrases = append(phrases, phrase)
+	}
+	sortLongestFirst(phrases)
+	return phrases
+}()
+
+// aisleCategory returns the store aisle of an ingredient; ingredients no rule recognizes are filed under other
+func aisleCategory(ingredient string) models.AisleCategory {
+	name := singularize(normalizeText(ingredient))
+	for _, phrase := range aislePhrases {
+		if containsPhrase(name, phrase) {
+			return aisleRules[phrase]
+		}
+	}
+	return models.AisleOther
+}
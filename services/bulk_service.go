@@ -0,0 +1,329 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"playground/models"
+)
+
+// MaxBulkImportBytes is the largest file a bulk import accepts
+const MaxBulkImportBytes = 100 << 20
+
+// MaxBulkRecordBytes is the longest NDJSON line a bulk import reads
+const MaxBulkRecordBytes = 1 << 20
+
+// BulkFormat is a file format a bulk import reads
+type BulkFormat string
+
+// Bulk import formats
+const (
+	BulkNDJSON BulkFormat = "ndjson"
+	BulkCSV    BulkFormat = "csv"
+)
+
+// ErrInvalidBulkRecord is returned for a record of a bulk import that cannot be imported
+var ErrInvalidBulkRecord = errors.New("invalid record")
+
+// ErrInvalidBulkImport is returned when a bulk import has an unknown format or a CSV file has no usable header
+var ErrInvalidBulkImport = errors.New("import must be NDJSON, or CSV with a header row including a title column")
+
+// bulkCSVColumns are the columns a CSV import reads, in the order they are documented. Ingredients and
+// instructions hold one entry per line of the cell and tags are separated by commas.
+var bulkCSVColumns = []string{
+	"title", "description", "ingredients", "instructions", "prepTime", "cookTime", "servings", "tags",
+	"visibility", "status", "sourceUrl", "sourceAuthor", "sourcePublisher",
+}
+
+// bulkStatusPath lists the transitions that bring a newly created draft to each imported status
+var bulkStatusPath = map[models.RecipeStatus][]models.RecipeStatus{
+	models.StatusDraft:     nil,
+	models.StatusPublished: {models.StatusPublished},
+	models.StatusArchived:  {models.StatusPublished, models.StatusArchived},
+}
+
+// BulkService exports and imports recipes in bulk
+type BulkService struct {
+	recipeService *RecipeService
+	ratingService *RatingService
+}
+
+// NewBulkService creates a new bulk service with the given services
+func NewBulkService(recipeService *RecipeService, ratingService *RatingService) *BulkService {
+	return &BulkService{
+		recipeService: recipeService,
+		ratingService: ratingService,
+	}
+}
+
+// ExportRecipes writes the recipes listed for the caller that match filter to w as NDJSON, one recipe
+// with its ratings per line, oldest first
+func (s *BulkService) ExportRecipes(w io.Writer, caller Caller, filter models.ExportFilter) error {
+	recipes := s.recipeService.GetAllRecipes(caller)
+	if filter.AuthorID != "" {
+		recipes = s.recipeService.GetRecipesByAuthor(filter.AuthorID, caller)
+	}
+	if filter.Tag != "" {
+		recipes = Filter(recipes, func(recipe models.Recipe) bool {
+			return Contains(recipe.Tags, filter.Tag)
+		})
+	}
+	sort.SliceStable(recipes, func(i, j int) bool {
+		if !recipes[i].CreatedAt.Equal(recipes[j].CreatedAt) {
+			return recipes[i].CreatedAt.Before(recipes[j].CreatedAt)
+		}
+		return recipes[i].ID < recipes[j].ID
+	})
+
+	encoder := json.NewEncoder(w)
+	for _, recipe := range recipes {
+		ratings := s.ratingService.GetRatingsByRecipeID(recipe.ID)
+		if ratings == nil {
+			ratings = []models.Rating{}
+		}
+		if err := encoder.Encode(models.RecipeExport{Recipe: recipe, Ratings: ratings}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportRecipes reads recipes in format from r and creates them for the caller through the recipe
+// service, one at a time. Records that fail are reported by line without stopping the import. When
+// the stream itself cannot be read further, as when it is over the size limit, the import stops with
+// an error wrapping the read error; the records before that point stay imported. A dry run validates
+// every record the same way without saving anything.
+func (s *BulkService) ImportRecipes(r io.Reader, format BulkFormat, caller Caller, dryRun bool) (models.BulkImportReport, error) {
+	report := models.BulkImportReport{
+		DryRun:  dryRun,
+		Recipes: []models.BulkImportResult{},
+		Errors:  []models.BulkImportError{},
+	}
+	add := func(line int, record models.BulkRecord, err error) {
+		report.Total++
+		id := ""
+		if err == nil {
+			id, err = s.importRecord(caller, record, dryRun)
+		}
+		if err != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, models.BulkImportError{Line: line, Error: err.Error()})
+			return
+		}
+		report.Imported++
+		report.Recipes = append(report.Recipes, models.BulkImportResult{Line: line, ID: id, Title: record.Title})
+	}
+
+	var line int
+	var err error
+	switch format {
+	case BulkNDJSON:
+		line, err = readNDJSON(r, add)
+	case BulkCSV:
+		line, err = readBulkCSV(r, add)
+	default:
+		return report, ErrInvalidBulkImport
+	}
+	if err != nil && !errors.Is(err, ErrInvalidBulkImport) {
+		err = fmt.Errorf("import stopped at line %d: %w", line, err)
+	}
+	return report, err
+}
+
+// importRecord checks a record and creates it, or only validates it on a dry run; it returns the
+// ID of the new recipe
+func (s *BulkService) importRecord(caller Caller, record models.BulkRecord, dryRun bool) (string, error) {
+	input := record.RecipeInput.NormalizeSections()
+	if strings.TrimSpace(input.Title) == "" {
+		return "", fmt.Errorf("%w: title is required", ErrInvalidBulkRecord)
+	}
+	if input.PrepTime < 0 || input.CookTime < 0 || input.Servings < 0 {
+		return "", fmt.Errorf("%w: times and servings cannot be negative", ErrInvalidBulkRecord)
+	}
+
+	status := record.Status
+	if status == "" {
+		status = models.StatusDraft
+	}
+	transitions, ok := bulkStatusPath[status]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown status %q", ErrInvalidBulkRecord, status)
+	}
+	if status != models.StatusDraft && !isPublishable(input) {
+		return "", ErrNotPublishable
+	}
+
+	if dryRun {
+		return "", s.recipeService.ValidateRecipe(caller.UserID, input)
+	}
+
+	recipe, err := s.recipeService.CreateRecipe(caller.UserID, input)
+	if err != nil {
+		return "", err
+	}
+	for _, next := range transitions {
+		if recipe, err = s.recipeService.TransitionRecipe(recipe.ID, caller, next); err != nil {
+			return recipe.ID, err
+		}
+	}
+	return recipe.ID, nil
+}
+
+// readNDJSON passes each non-blank line of r to add as a record, with the line number it is on.
+// It returns the line it stopped at when r cannot be read.
+func readNDJSON(r io.Reader, add func(line int, record models.BulkRecord, err error)) (int, error) {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, tooLong, err := readBulkLine(reader)
+		if err != nil && err != io.EOF {
+			return line, err
+		}
+
+		switch {
+		case tooLong:
+			add(line, models.BulkRecord{}, fmt.Errorf("%w: line is longer than %d bytes", ErrInvalidBulkRecord, MaxBulkRecordBytes))
+		case len(strings.TrimSpace(string(data))) > 0:
+			var record models.BulkRecord
+			if decodeErr := json.Unmarshal(data, &record); decodeErr != nil {
+				add(line, record, fmt.Errorf("%w: %v", ErrInvalidBulkRecord, decodeErr))
+			} else {
+				add(line, record, nil)
+			}
+		}
+
+		if err == io.EOF {
+			return line, nil
+		}
+	}
+}
+
+// readBulkLine reads one line without its line ending; the rest of a line over MaxBulkRecordBytes is
+// skipped and reported as too long
+func readBulkLine(reader *bufio.Reader) ([]byte, bool, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > MaxBulkRecordBytes+2 {
+				tooLong, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		return []byte(strings.TrimRight(string(line), "\r\n")), tooLong, err
+	}
+}
+
+// readBulkCSV passes each row of a CSV file to add as a record, with the line it starts on. The first
+// row names the columns; unknown columns are ignored. It returns the line it stopped at when r cannot
+// be read.
+func readBulkCSV(r io.Reader, add func(line int, record models.BulkRecord, err error)) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 1, fmt.Errorf("%w: %w", ErrInvalidBulkImport, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		for _, known := range bulkCSVColumns {
+			if strings.EqualFold(name, known) {
+				columns[known] = i
+			}
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return 1, ErrInvalidBulkImport
+	}
+
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return line, nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			line = parseErr.Line
+			add(parseErr.StartLine, models.BulkRecord{}, fmt.Errorf("%w: %v", ErrInvalidBulkRecord, parseErr.Err))
+			continue
+		}
+		if err != nil {
+			return line + 1, err
+		}
+		line, _ = reader.FieldPos(0)
+
+		cell := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		record, err := csvRecord(cell)
+		add(line, record, err)
+	}
+}
+
+// csvRecord builds a record from the cells of a CSV row
+func csvRecord(cell func(column string) string) (models.BulkRecord, error) {
+	record := models.BulkRecord{
+		RecipeInput: models.RecipeInput{
+			Title:        cell("title"),
+			Description:  cell("description"),
+			Ingredients:  models.ParseIngredients(cellLines(cell("ingredients"))),
+			Instructions: cellLines(cell("instructions")),
+			Tags:         ldKeywords(cell("tags")),
+			Visibility:   models.RecipeVisibility(strings.ToLower(cell("visibility"))),
+		},
+		Status: models.RecipeStatus(strings.ToLower(cell("status"))),
+	}
+	if record.Visibility != "" && !record.Visibility.IsValid() {
+		return record, fmt.Errorf("%w: unknown visibility %q", ErrInvalidBulkRecord, record.Visibility)
+	}
+
+	numbers := []struct {
+		column string
+		field  *int
+	}{{"prepTime", &record.PrepTime}, {"cookTime", &record.CookTime}, {"servings", &record.Servings}}
+	for _, number := range numbers {
+		if value := cell(number.column); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return record, fmt.Errorf("%w: %s must be a whole number", ErrInvalidBulkRecord, number.column)
+			}
+			*number.field = parsed
+		}
+	}
+
+	source := models.RecipeSource{URL: cell("sourceUrl"), Author: cell("sourceAuthor"), Publisher: cell("sourcePublisher")}
+	if source != (models.RecipeSource{}) {
+		record.Source = &source
+	}
+	return record, nil
+}
+
+// cellLines splits a multi-line CSV cell into its non-blank lines
+func cellLines(cell string) []string {
+	var lines []string
+	for _, line := range strings.Split(cell, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestBulkExportImport tests exporting recipes as NDJSON and importing NDJSON and CSV in bulk
+func TestBulkExportImport(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	ratingRepo := repositories.NewInMemoryRatingRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	ratingService := NewRatingService(ratingRepo, recipeService)
+	service := NewBulkService(recipeService, ratingService)
+
+	author := Caller{UserID: "author-1"}
+	importer := Caller{UserID: "importer-1"}
+
+	soup := createPublishedRecipe(t, recipeService, author.UserID, "Soup")
+	input := soup.Input()
+	input.Tags = []string{"winter"}
+	if _, err := recipeService.UpdateRecipe(soup.ID, author, input); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	createPublishedRecipe(t, recipeService, "author-2", "Salad")
+	if _, err := ratingService.CreateRating(soup.ID, "rater-1", models.RatingInput{Score: 4}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// The export has one line per recipe, filtered by tag, with its ratings
+	var export bytes.Buffer
+	if err := service.ExportRecipes(&export, importer, models.ExportFilter{Tag: "winter"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(export.String()), "\n")
+	var exported models.RecipeExport
+	if err := json.Unmarshal([]byte(lines[0]), &exported); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(lines) != 1 || exported.Title != "Soup" || len(exported.Ratings) != 1 || exported.Ratings[0].Score != 4 {
+		t.Errorf("Expected the soup with its rating, but got %s", export.String())
+	}
+	export.Reset()
+	if err := service.ExportRecipes(&export, importer, models.ExportFilter{AuthorID: "author-2"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !strings.Contains(export.String(), `"title":"Salad"`) || strings.Count(export.String(), "\n") != 1 {
+		t.Errorf("Expected only the salad, but got %s", export.String())
+	}
+
+	// Exported lines import as they are; broken lines are reported without stopping the batch
+	ndjson := strings.Join([]string{
+		lines[0],
+		`{"title": "Broken"`,
+		"",
+		`{"instructions": ["Stir"]}`,
+		`{"title": "Bare toast", "status": "published"}`,
+		`{"title": "Toast", "ingredients": ["2 slices bread"], "instructions": ["Toast the bread"], "servings": 1}`,
+	}, "\n")
+
+	report, err := service.ImportRecipes(strings.NewReader(ndjson), BulkNDJSON, importer, true)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !report.DryRun || report.Total != 5 || report.Imported != 2 || report.Failed != 3 {
+		t.Errorf("Expected 2 of 5 records to pass, but got %+v", report)
+	}
+	var failed []int
+	for _, lineError := range report.Errors {
+		failed = append(failed, lineError.Line)
+	}
+	if len(failed) != 3 || failed[0] != 2 || failed[1] != 4 || failed[2] != 5 {
+		t.Errorf("Expected lines 2, 4 and 5 to fail, but got %+v", report.Errors)
+	}
+	if recipes := recipeService.GetRecipesByAuthor(importer.UserID, importer); len(recipes) != 0 {
+		t.Errorf("Expected a dry run to save nothing, but got %d recipes", len(recipes))
+	}
+
+	report, err = service.ImportRecipes(strings.NewReader(ndjson), BulkNDJSON, importer, false)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if report.Imported != 2 || report.Recipes[0].Line != 1 || report.Recipes[0].ID == "" {
+		t.Fatalf("Expected 2 imported recipes, but got %+v", report)
+	}
+	copied, err := recipeService.GetRecipeByID(report.Recipes[0].ID)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if copied.AuthorID != importer.UserID || copied.Status != models.StatusPublished || copied.Tags[0] != "winter" {
+		t.Errorf("Expected a published copy of the soup owned by the importer, but got %+v", copied)
+	}
+
+	// CSV cells hold one ingredient or step per line
+	csv := "title,ingredients,instructions,servings,tags,status,sourceUrl\n" +
+		"Porridge,\"50 g oats\n300 ml milk\",\"Simmer the oats\nServe\",2,\"breakfast, quick\",archived,https://example.com/porridge\n" +
+		"Pancakes,1 cup flour,Fry,two,,,\n" +
+		",,,,,,\n"
+	report, err = service.ImportRecipes(strings.NewReader(csv), BulkCSV, importer, false)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if report.Total != 3 || report.Imported != 1 || len(report.Errors) != 2 || report.Errors[0].Line != 5 || report.Errors[1].Line != 6 {
+		t.Fatalf("Expected the porridge to be imported and lines 5 and 6 to fail, but got %+v", report)
+	}
+	porridge, _ := recipeService.GetRecipeByID(report.Recipes[0].ID)
+	if len(porridge.Ingredients) != 2 || len(porridge.Instructions) != 2 || porridge.Servings != 2 ||
+		len(porridge.Tags) != 2 || porridge.Status != models.StatusArchived || porridge.Source == nil {
+		t.Errorf("Expected an archived porridge with 2 ingredients, steps and tags, but got %+v", porridge)
+	}
+
+	// A CSV file needs a title column
+	if _, err := service.ImportRecipes(strings.NewReader("name\nSoup\n"), BulkCSV, importer, false); !errors.Is(err, ErrInvalidBulkImport) {
+		t.Errorf("Expected ErrInvalidBulkImport, but got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package services
+
+// AdminRole is the role that may act on resources owned by other users
+const AdminRole = "admin"
+
+// Caller identifies the user on whose behalf a service operation runs
+type Caller struct {
+	UserID string
+	Role   string
+}
+
+// IsAdmin reports whether the caller has the admin role
+func (c Caller) IsAdmin() bool {
+	return c.Role == AdminRole
+}
+
+// CanModify reports whether the caller may change a resource owned by ownerID
+func (c Caller) CanModify(ownerID string) bool {
+	return c.IsAdmin() || (c.UserID != "" && c.UserID == ownerID)
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"playground/models"
+)
+
+// ingredientClass describes what an ingredient contains
+type ingredientClass struct {
+	allergens []models.Allergen
+	// meat marks meat and other slaughter products such as gelatin
+	meat bool
+	// animal marks animal products that are neither meat nor an allergen, such as honey
+	animal bool
+}
+
+// merge returns the combination of two ingredient classes
+func (c ingredientClass) merge(other ingredientClass) ingredientClass {
+	merged := ingredientClass{
+		allergens: append([]models.Allergen{}, c.allergens...),
+		meat:      c.meat || other.meat,
+		animal:    c.animal || other.animal,
+	}
+	for _, allergen := range other.allergens {
+		if !Contains(merged.allergens, allergen) {
+			merged.allergens = append(merged.allergens, allergen)
+		}
+	}
+	return merged
+}
+
+// Shorthands for the ingredient rules below
+var (
+	classNone        = ingredientClass{}
+	classMeat        = ingredientClass{meat: true}
+	classAnimal      = ingredientClass{animal: true}
+	classCelery      = ingredientClass{allergens: []models.Allergen{models.AllergenCelery}}
+	classGluten      = ingredientClass{allergens: []models.Allergen{models.AllergenGluten}}
+	classCrustaceans = ingredientClass{allergens: []models.Allergen{models.AllergenCrustaceans}}
+	classEggs        = ingredientClass{allergens: []models.Allergen{models.AllergenEggs}}
+	classFish        = ingredientClass{allergens: []models.Allergen{models.AllergenFish}}
+	classLupin       = ingredientClass{allergens: []models.Allergen{models.AllergenLupin}}
+	classMilk        = ingredientClass{allergens: []models.Allergen{models.AllergenMilk}}
+	classMolluscs    = ingredientClass{allergens: []models.Allergen{models.AllergenMolluscs}}
+	classMustard     = ingredientClass{allergens: []models.Allergen{models.AllergenMustard}}
+	classTreeNuts    = ingredientClass{allergens: []models.Allergen{models.AllergenTreeNuts}}
+	classPeanuts     = ingredientClass{allergens: []models.Allergen{models.AllergenPeanuts}}
+	classSesame      = ingredientClass{allergens: []models.Allergen{models.AllergenSesame}}
+	classSoy         = ingredientClass{allergens: []models.Allergen{models.AllergenSoy}}
+	classSulphites   = ingredientClass{allergens: []models.Allergen{models.AllergenSulphites}}
+)
+
+// ingredientRules maps ingredient phrases to what they contain. Longer phrases are matched first and
+// consume their words, so "peanut butter" is peanuts rather than dairy and "rice flour" is not gluten.
+var ingredientRules = map[string]ingredientClass{
+	// Gluten
+	"flour": classGluten, "wheat": classGluten, "bread": classGluten, "breadcrumbs": classGluten, "panko": classGluten,
+	"pasta": classGluten, "spaghetti": classGluten, "macaroni": classGluten, "noodle": classGluten, "couscous": classGluten,
+	"barley": classGluten, "rye": classGluten, "semolina": classGluten, "bulgur": classGluten, "spelt": classGluten,
+	"seitan": classGluten, "oat": classGluten, "oats": classGluten, "cracker": classGluten, "beer": classGluten, "tortilla": classGluten,
+	"soy sauce": classSoy.merge(classGluten), "worcestershire sauce": classFish.merge(classGluten),
+	"rice flour": classNone, "corn flour": classNone, "cornflour": classNone, "coconut flour": classNone,
+	"almond flour": classTreeNuts, "gluten-free flour": classNone, "gluten free flour": classNone,
+	"gluten-free pasta": classNone, "gluten-free bread": classNone, "rice noodle": classNone, "corn tortilla": classNone,
+	"buckwheat flour": classNone, "oat milk": classGluten,
+
+	// Milk
+	"milk": classMilk, "butter": classMilk, "cream": classMilk, "cheese": classMilk, "cheddar": classMilk, "parmesan": classMilk,
+	"mozzarella": classMilk, "ricotta": classMilk, "feta": classMilk, "mascarpone": classMilk, "yogurt": classMilk, "yoghurt": classMilk,
+	"buttermilk": classMilk, "ghee": classMilk, "whey": classMilk, "creme fraiche": classMilk, "custard": classMilk.merge(classEggs),
+	"coconut milk": classNone, "coconut cream": classNone, "almond milk": classTreeNuts, "soy milk": classSoy, "rice milk": classNone,
+	"cocoa butter": classNone, "peanut butter": classPeanuts, "almond butter": classTreeNuts, "cream of tartar": classNone,
+	"vegan butter": classNone, "vegan cheese": classNone, "butter bean": classNone, "butter beans": classNone,
+	"cashew milk": classTreeNuts, "hemp milk": classNone,
+
+	// Eggs
+	"egg": classEggs, "mayonnaise": classEggs, "mayo": classEggs, "meringue": classEggs, "vegan mayonnaise": classNone,
+
+	// Fish, crustaceans and molluscs
+	"fish": classFish, "salmon": classFish, "tuna": classFish, "cod": classFish, "anchovy": classFish, "sardine": classFish,
+	"trout": classFish, "haddock": classFish, "mackerel": classFish, "fish sauce": classFish,
+	"shrimp": classCrustaceans, "prawn": classCrustaceans, "crab": classCrustaceans, "lobster": classCrustaceans, "crayfish": classCrustaceans,
+	"mussel": classMolluscs, "clam": classMolluscs, "oyster": classMolluscs, "scallop": classMolluscs, "squid": classMolluscs,
+	"octopus": classMolluscs, "oyster sauce": classMolluscs,
+
+	// Nuts
+	"peanut": classPeanuts, "groundnut": classPeanuts,
+	"nut": classTreeNuts, "almond": classTreeNuts, "walnut": classTreeNuts, "pecan": classTreeNuts, "cashew": classTreeNuts,
+	"hazelnut": classTreeNuts, "pistachio": classTreeNuts, "macadamia": classTreeNuts, "brazil nut": classTreeNuts, "pine nut": classTreeNuts,
+
+	// Other allergens
+	"soy": classSoy, "soya": classSoy, "tofu": classSoy, "tempeh": classSoy, "edamame": classSoy, "miso": classSoy, "tamari": classSoy,
+	"sesame": classSesame, "tahini": classSesame,
+	"mustard": classMustard,
+	"celery":  classCelery, "celeriac": classCelery,
+	"lupin": classLupin, "lupine": classLupin,
+	"wine": classSulphites, "dried apricot": classSulphites,
+
+	// Meat and other animal products
+	"beef": classMeat, "pork": classMeat, "chicken": classMeat, "lamb": classMeat, "turkey": classMeat, "bacon": classMeat, "ham": classMeat,
+	"sausage": classMeat, "veal": classMeat, "duck": classMeat, "prosciutto": classMeat, "salami": classMeat, "chorizo": classMeat,
+	"gelatin": classMeat, "gelatine": classMeat, "lard": classMeat,
+	"honey": classAnimal,
+}
+
+// labelExclusions lists what an ingredient must not contain for a recipe to carry each dietary label
+var labelExclusions = map[models.DietaryLabel]ingredientClass{
+	models.LabelVegan: {
+		allergens: []models.Allergen{models.AllergenEggs, models.AllergenMilk, models.AllergenFish, models.AllergenCrustaceans, models.AllergenMolluscs},
+		meat:      true,
+		animal:    true,
+	},
+	models.LabelVegetarian: {
+		allergens: []models.Allergen{models.AllergenFish, models.AllergenCrustaceans, models.AllergenMolluscs},
+		meat:      true,
+	},
+	models.LabelGlutenFree: {allergens: []models.Allergen{models.AllergenGluten}},
+	models.LabelDairyFree:  {allergens: []models.Allergen{models.AllergenMilk}},
+	models.LabelNutFree:    {allergens: []models.Allergen{models.AllergenTreeNuts, models.AllergenPeanuts}},
+}
+
+// excludes reports whether an ingredient of the given class rules out a label described by exclusions
+func (exclusions ingredientClass) excludes(class ingredientClass) bool {
+	if (exclusions.meat && class.meat) || (exclusions.animal && class.animal) {
+		return true
+	}
+	for _, allergen := range class.allergens {
+		if Contains(exclusions.allergens, allergen) {
+			return true
+		}
+	}
+	return false
+}
+
+// IngredientClassifier derives allergens and dietary labels from ingredient names
+type IngredientClassifier struct {
+	rules map[string]ingredientClass
+	// phrases are the rule keys, longest first so specific phrases consume their words first
+	phrases []string
+}
+
+// NewIngredientClassifier creates a new classifier with the built-in ingredient rules
+func NewIngredientClassifier() *IngredientClassifier {
+	phrases := make([]string, 0, len(ingredientRules))
+	for phrase := range ingredientRules {
+		phrases = append(phrases, phrase)
+	}
+	sortLongestFirst(phrases)
+
+	return &IngredientClassifier{
+		rules:   ingredientRules,
+		phrases: phrases,
+	}
+}
+
+// Classify derives the allergens and dietary labels of a recipe from its ingredients and flags
+// dietary tags that the ingredients contradict. Ingredients no rule recognizes are assumed to be
+// free of allergens and animal products.
+func (c *IngredientClassifier) Classify(input models.RecipeInput) models.DietInfo {
+	classes := make([]ingredientClass, 0, len(input.Ingredients))
+	var recipeClass ingredientClass
+	for _, ingredient := range input.Ingredients {
+		class := c.classifyIngredient(ingredient.Name)
+		classes = append(classes, class)
+		recipeClass = recipeClass.merge(class)
+	}
+
+	diet := models.DietInfo{
+		Allergens: make([]models.Allergen, 0),
+		Labels:    make([]models.DietaryLabel, 0),
+	}
+	for _, allergen := range models.AllAllergens {
+		if Contains(recipeClass.allergens, allergen) {
+			diet.Allergens = append(diet.Allergens, allergen)
+		}
+	}
+	for _, label := range models.AllDietaryLabels {
+		if !labelExclusions[label].excludes(recipeClass) {
+			diet.Labels = append(diet.Labels, label)
+		}
+	}
+
+	// Hand-entered dietary tags must agree with the derived labels
+	for _, tag := range input.Tags {
+		label := models.DietaryLabel(strings.ReplaceAll(normalizeText(tag), " ", "-"))
+		if _, known := labelExclusions[label]; !known || diet.HasLabel(label) {
+			continue
+		}
+
+		offending := make([]string, 0)
+		for i, ingredient := range input.Ingredients {
+			if labelExclusions[label].excludes(classes[i]) {
+				offending = append(offending, ingredient.Name)
+			}
+		}
+		diet.Conflicts = append(diet.Conflicts, models.TagConflict{
+			Tag:    tag,
+			Reason: fmt.Sprintf("not %s: %s", label, strings.Join(offending, ", ")),
+		})
+	}
+
+	return diet
+}
+
+// classifyIngredient returns what an ingredient contains, combining every rule that matches its name
+// as written or in singular form
+func (c *IngredientClassifier) classifyIngredient(name string) ingredientClass {
+	var class ingredientClass
+	normalized := normalizeText(name)
+	for _, candidate := range []string{normalized, singularize(normalized)} {
+		text := " " + candidate + " "
+		for _, phrase := range c.phrases {
+			if strings.Contains(text, " "+phrase+" ") {
+				class = class.merge(c.rules[phrase])
+				// Consume the phrase so shorter rules cannot match its words again
+				text = strings.ReplaceAll(text, " "+phrase+" ", " | ")
+			}
+		}
+	}
+	return class
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestClassify tests allergen and dietary label detection from ingredient lines
+func TestClassify(t *testing.T) {
+	classifier := NewIngredientClassifier()
+
+	tests := []struct {
+		name      string
+		lines     []string
+		allergens []models.Allergen
+		labels    []models.DietaryLabel
+	}{
+		{
+			name:      "pancakes",
+			lines:     []string{"1 1/2 cups flour", "2 eggs", "1 cup milk", "2 tbsp butter, melted"},
+			allergens: []models.Allergen{models.AllergenGluten, models.AllergenEggs, models.AllergenMilk},
+			labels:    []models.DietaryLabel{models.LabelVegetarian, models.LabelNutFree},
+		},
+		{
+			name:      "specific phrases win",
+			lines:     []string{"2 tbsp peanut butter", "1 cup coconut milk", "1 cup rice flour", "1 tsp cream of tartar"},
+			allergens: []models.Allergen{models.AllergenPeanuts},
+			labels:    []models.DietaryLabel{models.LabelVegan, models.LabelVegetarian, models.LabelGlutenFree, models.LabelDairyFree},
+		},
+		{
+			name:      "whole words only",
+			lines:     []string{"1 eggplant", "1 tsp nutmeg", "1 butternut squash"},
+			allergens: []models.Allergen{},
+			labels:    models.AllDietaryLabels,
+		},
+		{
+			name:      "meat and honey",
+			lines:     []string{"500 g chicken thighs", "2 tbsp honey", "1 tbsp soy sauce"},
+			allergens: []models.Allergen{models.AllergenGluten, models.AllergenSoy},
+			labels:    []models.DietaryLabel{models.LabelDairyFree, models.LabelNutFree},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diet := classifier.Classify(models.RecipeInput{Ingredients: models.ParseIngredients(tc.lines)})
+
+			if !reflect.DeepEqual(diet.Allergens, tc.allergens) {
+				t.Errorf("Expected allergens %v, but got %v", tc.allergens, diet.Allergens)
+			}
+			if !reflect.DeepEqual(diet.Labels, tc.labels) {
+				t.Errorf("Expected labels %v, but got %v", tc.labels, diet.Labels)
+			}
+		})
+	}
+}
+
+// TestRecipeDiet tests that the diet is refreshed on save, tag conflicts are flagged and recipes can be filtered by diet
+func TestRecipeDiet(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the services with the repository
+	recipeService := NewRecipeService(repo)
+	searchService := NewSearchService(recipeService, nil, nil)
+
+	author := Caller{UserID: "author-1"}
+	recipe, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:       "Salad",
+		Tags:        []string{"Vegan", "Gluten Free", "quick"},
+		Ingredients: models.ParseIngredients([]string{"1 head lettuce", "50 g feta, crumbled", "2 tbsp olive oil"}),
+	})
+
+	if len(recipe.Diet.Conflicts) != 1 || recipe.Diet.Conflicts[0].Tag != "Vegan" {
+		t.Fatalf("Expected a conflict for the vegan tag, but got %+v", recipe.Diet.Conflicts)
+	}
+	if recipe.Diet.Conflicts[0].Reason != "not vegan: feta" {
+		t.Errorf("Expected the conflict to name feta, but got %q", recipe.Diet.Conflicts[0].Reason)
+	}
+
+	// Updating the ingredients refreshes the diet
+	input := recipe.Input()
+	input.Ingredients = models.ParseIngredients([]string{"1 head lettuce", "2 tbsp olive oil"})
+	updated, err := recipeService.UpdateRecipe(recipe.ID, author, input)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(updated.Diet.Conflicts) != 0 || !updated.Diet.HasLabel(models.LabelVegan) {
+		t.Errorf("Expected a conflict-free vegan recipe, but got %+v", updated.Diet)
+	}
+
+	recipeService.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:       "Satay",
+		Ingredients: models.ParseIngredients([]string{"200 g tofu", "3 tbsp peanut butter"}),
+	})
+
+	vegan := searchService.SearchByDiet([]models.DietaryLabel{models.LabelVegan}, nil, author)
+	if len(vegan) != 2 {
+		t.Errorf("Expected 2 vegan recipes, but got %v", titles(vegan))
+	}
+	peanutFree := searchService.SearchByDiet([]models.DietaryLabel{models.LabelVegan}, []models.Allergen{models.AllergenPeanuts}, author)
+	if len(peanutFree) != 1 || peanutFree[0].ID != recipe.ID {
+		t.Errorf("Expected only the salad to be vegan and peanut-free, but got %v", titles(peanutFree))
+	}
+}
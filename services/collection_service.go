@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// ErrCollectionNotFound is returned when a collection does not exist or the caller may not see it
+var ErrCollectionNotFound = errors.New("collection not found")
+
+// ErrNotCollectionOwner is returned when a caller tries to change a collection they do not own
+var ErrNotCollectionOwner = errors.New("unauthorized: collection belongs to another user")
+
+// ErrInvalidCollection is returned when a collection has no name
+var ErrInvalidCollection = errors.New("collection needs a name")
+
+// ErrRecipeAlreadyInCollection is returned when a recipe is added to a collection twice
+var ErrRecipeAlreadyInCollection = errors.New("recipe is already in the collection")
+
+// CollectionService handles business logic for recipe collections
+type CollectionService struct {
+	repository    repositories.CollectionRepository
+	recipeService *RecipeService
+	// mutex serializes changes to recipe lists, which are read, changed and written back
+	mutex sync.Mutex
+}
+
+// NewCollectionService creates a new collection service with the given repository
+func NewCollectionService(repository repositories.CollectionRepository, recipeService *RecipeService) *CollectionService {
+	service := &CollectionService{
+		repository:    repository,
+		recipeService: recipeService,
+	}
+
+	// Recipes in the trash are hidden from collections and come back in place when restored;
+	// purged recipes are taken out for good
+	recipeService.On(RecipePurged, func(recipe models.Recipe) {
+		repository.RemoveRecipe(recipe.ID)
+	})
+
+	return service
+}
+
+// GetCollections returns the caller's own collections, oldest first
+func (s *CollectionService) GetCollections(caller Caller) []models.Collection {
+	return sortCollections(s.repository.FindByOwnerID(caller.UserID))
+}
+
+// GetCollectionsByOwner returns the collections of a user that the caller may see, oldest first
+func (s *CollectionService) GetCollectionsByOwner(ownerID string, caller Caller) []models.Collection {
+	collections := Filter(s.repository.FindByOwnerID(ownerID), func(collection models.Collection) bool {
+		return canViewCollection(collection, caller)
+	})
+	return sortCollections(collections)
+}
+
+// GetCollection returns a collection the caller may see with the recipes in it the caller may see,
+// in order. Recipe IDs the caller may not see, such as recipes in the trash, are left out.
+func (s *CollectionService) GetCollection(id string, caller Caller) (models.CollectionDetail, error) {
+	collection, err := s.repository.FindByID(id)
+	if err != nil || !canViewCollection(collection, caller) {
+		return models.CollectionDetail{}, ErrCollectionNotFound
+	}
+
+	detail := models.CollectionDetail{
+		Collection: collection,
+		Recipes:    make([]models.Recipe, 0, len(collection.RecipeIDs)),
+	}
+	detail.RecipeIDs = make([]string, 0, len(collection.RecipeIDs))
+	for _, recipeID := range collection.RecipeIDs {
+		recipe, err := s.recipeService.GetVisibleRecipe(recipeID, caller)
+		if err != nil {
+			continue
+		}
+		detail.RecipeIDs = append(detail.RecipeIDs, recipeID)
+		detail.Recipes = append(detail.Recipes, recipe)
+	}
+	return detail, nil
+}
+
+// CreateCollection adds a new empty collection owned by the caller
+func (s *CollectionService) CreateCollection(caller Caller, input models.CollectionInput) (models.Collection, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return models.Collection{}, ErrInvalidCollection
+	}
+	return s.repository.Create(caller.UserID, input), nil
+}
+
+// UpdateCollection changes the name, description and visibility of a collection the caller owns
+func (s *CollectionService) UpdateCollection(id string, caller Caller, input models.CollectionInput) (models.Collection, error) {
+	if _, err := s.ownedCollection(id, caller); err != nil {
+		return models.Collection{}, err
+	}
+	if strings.TrimSpace(input.Name) == "" {
+		return models.Collection{}, ErrInvalidCollection
+	}
+	return s.repository.Update(id, input)
+}
+
+// DeleteCollection removes a collection the caller owns; the recipes in it are not affected
+func (s *CollectionService) DeleteCollection(id string, caller Caller) error {
+	if _, err := s.ownedCollection(id, caller); err != nil {
+		return err
+	}
+	return s.repository.Delete(id)
+}
+
+// AddRecipe puts a recipe the caller may see into a collection the caller owns, at the given
+// position or at the end. Positions past the end add the recipe at the end.
+func (s *CollectionService) AddRecipe(id string, caller Caller, input models.CollectionRecipeInput) (models.Collection, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	collection, err := s.ownedCollection(id, caller)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	if _, err := s.recipeService.GetVisibleRecipe(input.RecipeID, caller); err != nil {
+		return models.Collection{}, err
+	}
+	if Contains(collection.RecipeIDs, input.RecipeID) {
+		return models.Collection{}, ErrRecipeAlreadyInCollection
+	}
+
+	position := len(collection.RecipeIDs)
+	if input.Position != nil && *input.Position >= 0 && *input.Position < position {
+		position = *input.Position
+	}
+
+	recipeIDs := make([]string, 0, len(collection.RecipeIDs)+1)
+	recipeIDs = append(recipeIDs, collection.RecipeIDs[:position]...)
+	recipeIDs = append(recipeIDs, input.RecipeID)
+	recipeIDs = append(recipeIDs, collection.RecipeIDs[position:]...)
+	return s.repository.SetRecipes(id, recipeIDs)
+}
+
+// RemoveRecipe takes a recipe out of a collection the caller owns
+func (s *CollectionService) RemoveRecipe(id string, caller Caller, recipeID string) (models.Collection, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	collection, err := s.ownedCollection(id, caller)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	if !Contains(collection.RecipeIDs, recipeID) {
+		return models.Collection{}, ErrRecipeNotFound
+	}
+
+	recipeIDs := Filter(collection.RecipeIDs, func(existing string) bool {
+		return existing != recipeID
+	})
+	return s.repository.SetRecipes(id, recipeIDs)
+}
+
+// ReorderRecipes moves the given recipes of a collection the caller owns to the front, in the given
+// order. Recipes that are not listed, such as those in the trash, follow in their current order.
+func (s *CollectionService) ReorderRecipes(id string, caller Caller, recipeIDs []string) (models.Collection, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	collection, err := s.ownedCollection(id, caller)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	ordered := make([]string, 0, len(collection.RecipeIDs))
+	for _, recipeID := range recipeIDs {
+		if !Contains(collection.RecipeIDs, recipeID) || Contains(ordered, recipeID) {
+			return models.Collection{}, ErrRecipeNotFound
+		}
+		ordered = append(ordered, recipeID)
+	}
+	for _, recipeID := range collection.RecipeIDs {
+		if !Contains(ordered, recipeID) {
+			ordered = append(ordered, recipeID)
+		}
+	}
+	return s.repository.SetRecipes(id, ordered)
+}
+
+// ownedCollection returns a collection if the caller owns it or is an admin. Private collections
+// of other users are reported as missing rather than forbidden.
+func (s *CollectionService) ownedCollection(id string, caller Caller) (models.Collection, error) {
+	collection, err := s.repository.FindByID(id)
+	if err != nil || !canViewCollection(collection, caller) {
+		return models.Collection{}, ErrCollectionNotFound
+	}
+	if !caller.CanModify(collection.OwnerID) {
+		return models.Collection{}, ErrNotCollectionOwner
+	}
+	return collection, nil
+}
+
+// canViewCollection reports whether the caller may open a collection: owners and admins
+// always can, everyone else only when it is shared
+func canViewCollection(collection models.Collection, caller Caller) bool {
+	return caller.CanModify(collection.OwnerID) || collection.Visibility == models.CollectionShared
+}
+
+// sortCollections orders collections by creation time, oldest first
+func sortCollections(collections []models.Collection) []models.Collection {
+	Sort(collections, func(i, j int) bool {
+		return collections[i].CreatedAt.Before(collections[j].CreatedAt)
+	})
+	return collections
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestCollections tests collection visibility, recipe ordering and cleanup of deleted recipes
+func TestCollections(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	collectionRepo := repositories.NewInMemoryCollectionRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	service := NewCollectionService(collectionRepo, recipeService)
+
+	owner := Caller{UserID: "owner-1"}
+	reader := Caller{UserID: "reader-1"}
+
+	soup := createPublishedRecipe(t, recipeService, owner.UserID, "Soup")
+	stew := createPublishedRecipe(t, recipeService, owner.UserID, "Stew")
+	salad := createPublishedRecipe(t, recipeService, reader.UserID, "Salad")
+	draft, _ := recipeService.CreateRecipe(reader.UserID, models.RecipeInput{Title: "Secret"})
+
+	if _, err := service.CreateCollection(owner, models.CollectionInput{Name: "  "}); !errors.Is(err, ErrInvalidCollection) {
+		t.Errorf("Expected ErrInvalidCollection, but got %v", err)
+	}
+	collection, err := service.CreateCollection(owner, models.CollectionInput{Name: "Weeknight", Description: "Quick dinners"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if collection.Visibility != models.CollectionPrivate || len(collection.RecipeIDs) != 0 {
+		t.Errorf("Expected an empty private collection, but got %+v", collection)
+	}
+
+	// Recipes must be visible to the owner and can only be added once
+	add := func(recipeID string, position *int) (models.Collection, error) {
+		return service.AddRecipe(collection.ID, owner, models.CollectionRecipeInput{RecipeID: recipeID, Position: position})
+	}
+	if _, err := add(draft.ID, nil); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound for another user's draft, but got %v", err)
+	}
+	add(soup.ID, nil)
+	add(stew.ID, nil)
+	first := 0
+	collection, err = add(salad.ID, &first)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := add(soup.ID, nil); !errors.Is(err, ErrRecipeAlreadyInCollection) {
+		t.Errorf("Expected ErrRecipeAlreadyInCollection, but got %v", err)
+	}
+	if !reflect.DeepEqual(collection.RecipeIDs, []string{salad.ID, soup.ID, stew.ID}) {
+		t.Errorf("Expected salad to be added first, but got %v", collection.RecipeIDs)
+	}
+
+	collection, err = service.ReorderRecipes(collection.ID, owner, []string{stew.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !reflect.DeepEqual(collection.RecipeIDs, []string{stew.ID, salad.ID, soup.ID}) {
+		t.Errorf("Expected stew first and the rest in their order, but got %v", collection.RecipeIDs)
+	}
+
+	// Private collections are hidden from other users until shared
+	if _, err := service.GetCollection(collection.ID, reader); !errors.Is(err, ErrCollectionNotFound) {
+		t.Errorf("Expected ErrCollectionNotFound, but got %v", err)
+	}
+	if _, err := service.UpdateCollection(collection.ID, owner, models.CollectionInput{Name: "Weeknight", Visibility: models.CollectionShared}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := service.UpdateCollection(collection.ID, reader, models.CollectionInput{Name: "Mine"}); !errors.Is(err, ErrNotCollectionOwner) {
+		t.Errorf("Expected ErrNotCollectionOwner, but got %v", err)
+	}
+	if shared := service.GetCollectionsByOwner(owner.UserID, reader); len(shared) != 1 {
+		t.Errorf("Expected the shared collection to be listed, but got %d collections", len(shared))
+	}
+
+	// Recipes in the trash are hidden and come back in place when restored
+	if err := recipeService.DeleteRecipe(salad.ID, reader); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	detail, err := service.GetCollection(collection.ID, reader)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !reflect.DeepEqual(detail.RecipeIDs, []string{stew.ID, soup.ID}) || len(detail.Recipes) != 2 || detail.Recipes[0].Title != "Stew" {
+		t.Errorf("Expected stew and soup, but got %v", detail.RecipeIDs)
+	}
+	if _, err := recipeService.RestoreRecipe(salad.ID, reader); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	detail, _ = service.GetCollection(collection.ID, reader)
+	if !reflect.DeepEqual(detail.RecipeIDs, []string{stew.ID, salad.ID, soup.ID}) {
+		t.Errorf("Expected the restored salad back in place, but got %v", detail.RecipeIDs)
+	}
+
+	// Purged recipes are taken out for good
+	recipeService.DeleteRecipe(soup.ID, owner)
+	recipeService.PurgeDeletedRecipes(time.Now().Add(time.Minute))
+	stored, _ := collectionRepo.FindByID(collection.ID)
+	if !reflect.DeepEqual(stored.RecipeIDs, []string{stew.ID, salad.ID}) {
+		t.Errorf("Expected the purged soup to be removed, but got %v", stored.RecipeIDs)
+	}
+
+	if err := service.DeleteCollection(collection.ID, owner); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if collections := service.GetCollections(owner); len(collections) != 0 {
+		t.Errorf("Expected no collections, but got %d", len(collections))
+	}
+}
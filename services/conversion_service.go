@@ -0,0 +1,277 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"playground/models"
+)
+
+// MeasurementSystem identifies the set of units a kitchen works in
+type MeasurementSystem string
+
+// Measurement systems
+const (
+	MetricSystem   MeasurementSystem = "metric"
+	ImperialSystem MeasurementSystem = "imperial"
+)
+
+// Dimension is the physical quantity a unit measures
+type Dimension string
+
+// Dimensions
+const (
+	Volume      Dimension = "volume"
+	Weight      Dimension = "weight"
+	Temperature Dimension = "temperature"
+)
+
+// ErrIncompatibleUnits is returned when two units cannot be converted into each other
+var ErrIncompatibleUnits = errors.New("incompatible units")
+
+// unitDefinition describes a unit by its dimension and its size in the dimension's base unit
+// (milliliters for volume, grams for weight)
+type unitDefinition struct {
+	dimension Dimension
+	toBase    float64
+}
+
+// unitDefinitions lists every unit the conversion service understands
+var unitDefinitions = map[string]unitDefinition{
+	models.UnitTeaspoon:   {Volume, 4.92892},
+	models.UnitTablespoon: {Volume, 14.7868},
+	models.UnitFluidOunce: {Volume, 29.5735},
+	models.UnitCup:        {Volume, 236.588},
+	models.UnitPint:       {Volume, 473.176},
+	models.UnitQuart:      {Volume, 946.353},
+	models.UnitGallon:     {Volume, 3785.41},
+	models.UnitMilliliter: {Volume, 1},
+	models.UnitLiter:      {Volume, 1000},
+	models.UnitGram:       {Weight, 1},
+	models.UnitKilogram:   {Weight, 1000},
+	models.UnitOunce:      {Weight, 28.3495},
+	models.UnitPound:      {Weight, 453.592},
+	models.UnitCelsius:    {Temperature, 1},
+	models.UnitFahrenheit: {Temperature, 1},
+}
+
+// defaultDensities holds grams per milliliter for common ingredients, so that
+// for example 1 cup of flour converts to about 120 g
+var defaultDensities = map[string]float64{
+	"all-purpose flour": 0.507,
+	"flour":             0.507,
+	"bread flour":       0.536,
+	"whole wheat flour": 0.507,
+	"sugar":             0.845,
+	"brown sugar":       0.93,
+	"powdered sugar":    0.507,
+	"icing sugar":       0.507,
+	"butter":            0.959,
+	"milk":              1.03,
+	"buttermilk":        1.03,
+	"cream":             1.01,
+	"heavy cream":       1.01,
+	"yogurt":            1.03,
+	"water":             1,
+	"oil":               0.92,
+	"olive oil":         0.92,
+	"honey":             1.42,
+	"maple syrup":       1.32,
+	"rice":              0.782,
+	"oats":              0.38,
+	"rolled oats":       0.38,
+	"cocoa powder":      0.36,
+	"salt":              1.22,
+	"kosher salt":       0.61,
+	"baking powder":     0.81,
+	"baking soda":       0.92,
+	"grated cheese":     0.42,
+	"parmesan":          0.42,
+	"breadcrumbs":       0.46,
+	"chocolate chips":   0.72,
+}
+
+// ConversionService converts amounts between volume, weight and temperature units
+type ConversionService struct {
+	densities map[string]float64
+	// densityKeys are the density table keys, longest first so "brown sugar" wins over "sugar"
+	densityKeys []string
+}
+
+// NewConversionService creates a new conversion service with the built-in density table
+func NewConversionService() *ConversionService {
+	return NewConversionServiceWithDensities(defaultDensities)
+}
+
+// NewConversionServiceWithDensities creates a new conversion service with a custom density table
+// in grams per milliliter
+func NewConversionServiceWithDensities(densities map[string]float64) *ConversionService {
+	keys := make([]string, 0, len(densities))
+	table := make(map[string]float64, len(densities))
+	for name, density := range densities {
+		key := normalizeText(name)
+		table[key] = density
+		keys = append(keys, key)
+	}
+	sortLongestFirst(keys)
+
+	return &ConversionService{
+		densities:   table,
+		densityKeys: keys,
+	}
+}
+
+// ParseMeasurementSystem validates a measurement system name
+func ParseMeasurementSystem(name string) (MeasurementSystem, error) {
+	switch MeasurementSystem(strings.ToLower(name)) {
+	case MetricSystem:
+		return MetricSystem, nil
+	case ImperialSystem:
+		return ImperialSystem, nil
+	}
+	return "", fmt.Errorf("unknown measurement system %q", name)
+}
+
+// UnitDimension returns the dimension a canonical unit measures
+func (s *ConversionService) UnitDimension(unit string) (Dimension, bool) {
+	def, ok := unitDefinitions[unit]
+	return def.dimension, ok
+}
+
+// Convert converts an amount between two units of the same dimension
+func (s *ConversionService) Convert(amount float64, from, to string) (float64, error) {
+	fromDef, ok1 := unitDefinitions[from]
+	toDef, ok2 := unitDefinitions[to]
+	if !ok1 || !ok2 || fromDef.dimension != toDef.dimension {
+		return 0, fmt.Errorf("%w: %s to %s", ErrIncompatibleUnits, from, to)
+	}
+
+	if fromDef.dimension == Temperature {
+		return s.ConvertTemperature(amount, from, to)
+	}
+	return amount * fromDef.toBase / toDef.toBase, nil
+}
+
+// ConvertTemperature converts a temperature between Celsius and Fahrenheit
+func (s *ConversionService) ConvertTemperature(value float64, from, to string) (float64, error) {
+	switch {
+	case from == to && (from == models.UnitCelsius || from == models.UnitFahrenheit):
+		return value, nil
+	case from == models.UnitCelsius && to == models.UnitFahrenheit:
+		return value*9/5 + 32, nil
+	case from == models.UnitFahrenheit && to == models.UnitCelsius:
+		return (value - 32) * 5 / 9, nil
+	}
+	return 0, fmt.Errorf("%w: %s to %s", ErrIncompatibleUnits, from, to)
+}
+
+// ConvertIngredientAmount converts an amount of a named ingredient between any two units,
+// using the density table to cross between volume and weight
+func (s *ConversionService) ConvertIngredientAmount(amount float64, from, to, ingredient string) (float64, error) {
+	fromDef, ok1 := unitDefinitions[from]
+	toDef, ok2 := unitDefinitions[to]
+	if !ok1 || !ok2 {
+		return 0, fmt.Errorf("%w: %s to %s", ErrIncompatibleUnits, from, to)
+	}
+	if fromDef.dimension == toDef.dimension {
+		return s.Convert(amount, from, to)
+	}
+
+	density, ok := s.Density(ingredient)
+	if !ok {
+		return 0, fmt.Errorf("%w: no density known for %q", ErrIncompatibleUnits, ingredient)
+	}
+
+	switch {
+	case fromDef.dimension == Volume && toDef.dimension == Weight:
+		return amount * fromDef.toBase * density / toDef.toBase, nil
+	case fromDef.dimension == Weight && toDef.dimension == Volume:
+		return amount * fromDef.toBase / density / toDef.toBase, nil
+	}
+	return 0, fmt.Errorf("%w: %s to %s", ErrIncompatibleUnits, from, to)
+}
+
+// Density returns the density in grams per milliliter of the best matching ingredient
+func (s *ConversionService) Density(ingredient string) (float64, bool) {
+	name := normalizeText(ingredient)
+	if density, ok := s.densities[name]; ok {
+		return density, true
+	}
+	for _, key := range s.densityKeys {
+		if containsPhrase(name, key) {
+			return s.densities[key], true
+		}
+	}
+	return 0, false
+}
+
+// ConvertIngredient expresses an ingredient in the given measurement system. Metric kitchens
+// weigh anything with a known density; imperial kitchens keep volumes in cups and spoons.
+// Ingredients without a convertible unit are returned unchanged.
+func (s *ConversionService) ConvertIngredient(ingredient models.Ingredient, system MeasurementSystem) models.Ingredient {
+	def, ok := unitDefinitions[ingredient.Unit]
+	if ingredient.Quantity == nil || !ok || def.dimension == Temperature {
+		return ingredient
+	}
+
+	amount := ingredient.Quantity.Float64()
+	var target string
+	switch system {
+	case MetricSystem:
+		target = models.UnitMilliliter
+		if def.dimension == Weight {
+			target = models.UnitGram
+		} else if _, known := s.Density(ingredient.Name); known {
+			target = models.UnitGram
+		}
+	case ImperialSystem:
+		target = models.UnitTeaspoon
+		if def.dimension == Weight {
+			target = models.UnitOunce
+		}
+	default:
+		return ingredient
+	}
+
+	converted, err := s.ConvertIngredientAmount(amount, ingredient.Unit, target, ingredient.Name)
+	if err != nil {
+		return ingredient
+	}
+
+	quantity, unit := rebalanceUnit(models.QuantityFromFloat(converted), target)
+	quantity = roundQuantity(quantity, unit)
+	ingredient.Quantity = &quantity
+	ingredient.Unit = unit
+	return ingredient
+}
+
+// ConvertStep expresses the temperature of a step, and its mention in the text, in the given
+// measurement system: °C for metric and °F for imperial, rounded to 5 degrees like oven dials
+func (s *ConversionService) ConvertStep(step models.Step, system MeasurementSystem) models.Step {
+	target := models.UnitCelsius
+	if system == ImperialSystem {
+		target = models.UnitFahrenheit
+	}
+	if step.Temperature == nil || step.Temperature.Unit == target {
+		return step
+	}
+
+	converted, err := s.ConvertTemperature(step.Temperature.Value, step.Temperature.Unit, target)
+	if err != nil {
+		return step
+	}
+	return step.WithTemperature(models.Temperature{Value: math.Round(converted/5) * 5, Unit: target})
+}
+
+// ConvertRecipe returns a copy of a recipe with its ingredients and step temperatures expressed in
+// the given measurement system
+func (s *ConversionService) ConvertRecipe(recipe models.Recipe, system MeasurementSystem) models.Recipe {
+	recipe = recipe.MapIngredients(func(ingredient models.Ingredient) models.Ingredient {
+		return s.ConvertIngredient(ingredient, system)
+	})
+	return recipe.MapSteps(func(step models.Step) models.Step {
+		return s.ConvertStep(step, system)
+	})
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"playground/models"
+)
+
+// TestConvert tests the Convert function of ConversionService
+func TestConvert(t *testing.T) {
+	service := NewConversionService()
+
+	tests := []struct {
+		name     string
+		amount   float64
+		from     string
+		to       string
+		expected float64
+	}{
+		{"Cups to milliliters", 1, models.UnitCup, models.UnitMilliliter, 236.588},
+		{"Tablespoons to teaspoons", 1, models.UnitTablespoon, models.UnitTeaspoon, 3},
+		{"Pounds to grams", 1, models.UnitPound, models.UnitGram, 453.592},
+		{"Kilograms to ounces", 1, models.UnitKilogram, models.UnitOunce, 35.274},
+		{"Fahrenheit to Celsius", 350, models.UnitFahrenheit, models.UnitCelsius, 176.667},
+		{"Celsius to Fahrenheit", 200, models.UnitCelsius, models.UnitFahrenheit, 392},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := service.Convert(tc.amount, tc.from, tc.to)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if math.Abs(result-tc.expected) > 0.01 {
+				t.Errorf("Expected %.3f, but got %.3f", tc.expected, result)
+			}
+		})
+	}
+
+	// Volume and weight cannot be converted without an ingredient
+	if _, err := service.Convert(1, models.UnitCup, models.UnitGram); err == nil {
+		t.Error("Expected error converting cups to grams, but got none")
+	}
+}
+
+// TestConvertIngredient tests converting ingredients between measurement systems
+func TestConvertIngredient(t *testing.T) {
+	service := NewConversionService()
+
+	tests := []struct {
+		line     string
+		system   MeasurementSystem
+		expected string
+	}{
+		{"1 cup flour", MetricSystem, "120 g flour"},
+		{"2 cups brown sugar", MetricSystem, "440 g brown sugar"},
+		{"1 cup cauliflower florets", MetricSystem, "237 ml cauliflower florets"},
+		{"2 lb potatoes", MetricSystem, "907 g potatoes"},
+		{"3 eggs", MetricSystem, "3 eggs"},
+		{"250 ml milk", ImperialSystem, "1 cup milk"},
+		{"15 ml vanilla", ImperialSystem, "1 tbsp vanilla"},
+		{"500 g butter", ImperialSystem, "1 1/8 lb butter"},
+		{"2 kg flour", ImperialSystem, "4 1/3 lb flour"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.line, func(t *testing.T) {
+			converted := service.ConvertIngredient(models.ParseIngredient(tc.line), tc.system)
+			if got := converted.String(); got != tc.expected {
+				t.Errorf("Expected '%s', but got '%s'", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestConvertRecipeTemperatures tests that converting a recipe converts the temperatures of its steps
+func TestConvertRecipeTemperatures(t *testing.T) {
+	service := NewConversionService()
+	recipe := models.NewRecipe("recipe-1", "author-1", models.RecipeInput{
+		Title:        "Roast potatoes",
+		Ingredients:  models.ParseIngredients([]string{"2 lb potatoes"}),
+		Instructions: []string{"Preheat the oven to 425 F", "Roast for 40 minutes", "Keep warm at 200 degrees Fahrenheit"},
+	})
+
+	metric := service.ConvertRecipe(recipe, MetricSystem)
+	expected := []string{"Preheat the oven to 220°C", "Roast for 40 minutes", "Keep warm at 95°C"}
+	if !reflect.DeepEqual(metric.Instructions, expected) {
+		t.Errorf("Expected instructions %v, but got %v", expected, metric.Instructions)
+	}
+	steps := metric.InstructionSections[0].Steps
+	if steps[0].Temperature == nil || *steps[0].Temperature != (models.Temperature{Value: 220, Unit: models.UnitCelsius}) {
+		t.Errorf("Expected the first step at 220°C, but got %+v", steps[0].Temperature)
+	}
+	if steps[1].Temperature != nil || steps[1].Duration != 2400 {
+		t.Errorf("Expected the second step to keep no temperature and its duration, but got %+v", steps[1])
+	}
+
+	// Converting back gives oven temperatures, and a recipe already in the system is left alone
+	imperial := service.ConvertRecipe(metric, ImperialSystem)
+	if imperial.Instructions[0] != "Preheat the oven to 430°F" {
+		t.Errorf("Expected 'Preheat the oven to 430°F', but got '%s'", imperial.Instructions[0])
+	}
+	if same := service.ConvertRecipe(recipe, ImperialSystem); !reflect.DeepEqual(same.Instructions, recipe.Instructions) {
+		t.Errorf("Expected imperial instructions to stay as written, but got %v", same.Instructions)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"playground/models"
+)
+
+// MaxCookbookRecipes is the most recipes a cookbook can hold
+const MaxCookbookRecipes = 500
+
+// ErrInvalidCookbook is returned when a cookbook does not select its recipes by exactly one of IDs,
+// tag or collection, or selects none or too many
+var ErrInvalidCookbook = errors.New("select between 1 and 500 recipes by ids, tag or collection")
+
+// CookbookService packages sets of recipes into e-books
+type CookbookService struct {
+	recipeService     *RecipeService
+	collectionService *CollectionService
+	imageService      *ImageService
+}
+
+// NewCookbookService creates a new cookbook service with the given services
+func NewCookbookService(recipeService *RecipeService, collectionService *CollectionService, imageService *ImageService) *CookbookService {
+	return &CookbookService{
+		recipeService:     recipeService,
+		collectionService: collectionService,
+		imageService:      imageService,
+	}
+}
+
+// GetCookbook returns the recipes the caller may see that selection chooses: the given IDs in
+// order, the listed recipes with a tag by title, or a collection in its own order
+func (s *CookbookService) GetCookbook(caller Caller, selection models.CookbookSelection) (models.Cookbook, error) {
+	selectors := 0
+	for _, selected := range []bool{len(selection.IDs) > 0, selection.Tag != "", selection.CollectionID != ""} {
+		if selected {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return models.Cookbook{}, ErrInvalidCookbook
+	}
+
+	cookbook := models.Cookbook{Title: strings.TrimSpace(selection.Title)}
+	switch {
+	case len(selection.IDs) > 0:
+		for _, id := range selection.IDs {
+			recipe, err := s.recipeService.GetVisibleRecipe(id, caller)
+			if err != nil {
+				return models.Cookbook{}, err
+			}
+			cookbook.Recipes = append(cookbook.Recipes, recipe)
+		}
+		if cookbook.Title == "" {
+			cookbook.Title = "Cookbook"
+		}
+	case selection.Tag != "":
+		cookbook.Recipes = s.recipeService.FilterRecipesByTag(selection.Tag, caller)
+		Sort(cookbook.Recipes, func(i, j int) bool {
+			return strings.ToLower(cookbook.Recipes[i].Title) < strings.ToLower(cookbook.Recipes[j].Title)
+		})
+		if cookbook.Title == "" {
+			cookbook.Title = selection.Tag
+		}
+	default:
+		collection, err := s.collectionService.GetCollection(selection.CollectionID, caller)
+		if err != nil {
+			return models.Cookbook{}, err
+		}
+		cookbook.Recipes = collection.Recipes
+		if cookbook.Title == "" {
+			cookbook.Title = collection.Name
+		}
+	}
+
+	if len(cookbook.Recipes) == 0 || len(cookbook.Recipes) > MaxCookbookRecipes {
+		return models.Cookbook{}, ErrInvalidCookbook
+	}
+	return cookbook, nil
+}
+
+// WriteCookbookEPUB writes a cookbook as an EPUB 3 file, with the medium rendition of each recipe's
+// cover image. Covers that cannot be read are left out.
+func (s *CookbookService) WriteCookbookEPUB(w io.Writer, caller Caller, cookbook models.Cookbook) error {
+	covers := make(map[string]epubImage)
+	for _, recipe := range cookbook.Recipes {
+		if recipe.CoverImageID == "" {
+			continue
+		}
+		reader, image, err := s.imageService.OpenImage(recipe.ID, recipe.CoverImageID, models.ImageMedium, caller)
+		if err != nil {
+			log.Printf("Cannot add the cover of recipe %s to a cookbook: %v", recipe.ID, err)
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			log.Printf("Cannot add the cover of recipe %s to a cookbook: %v", recipe.ID, err)
+			continue
+		}
+		covers[recipe.ID] = epubImage{ContentType: image.ContentType, data: data}
+	}
+
+	return writeEPUB(w, cookbook, covers, time.Now())
+}
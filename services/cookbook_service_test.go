@@ -0,0 +1,139 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestCookbookEPUB tests choosing the recipes of a cookbook and packaging them as an EPUB
+func TestCookbookEPUB(t *testing.T) {
+	store, err := repositories.NewFileSystemBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	collectionRepo := repositories.NewInMemoryCollectionRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	collectionService := NewCollectionService(collectionRepo, recipeService)
+	imageService := NewImageService(store, recipeService)
+	service := NewCookbookService(recipeService, collectionService, imageService)
+
+	author := Caller{UserID: "author-1"}
+	reader := Caller{UserID: "reader-1"}
+
+	soup := createPublishedRecipe(t, recipeService, author.UserID, "Tomato & Basil Soup")
+	salad := createPublishedRecipe(t, recipeService, author.UserID, "Salad <Niçoise>")
+	draft, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{Title: "Secret Stew"})
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage(400, 200)); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := imageService.UploadImage(soup.ID, author, &encoded, ""); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	collection, err := collectionService.CreateCollection(author, models.CollectionInput{Name: "Lunches", Visibility: models.CollectionShared})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, id := range []string{salad.ID, soup.ID, draft.ID} {
+		if _, err := collectionService.AddRecipe(collection.ID, author, models.CollectionRecipeInput{RecipeID: id}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	// Recipes are chosen by exactly one selector, from those the caller may see
+	if _, err := service.GetCookbook(reader, models.CookbookSelection{}); !errors.Is(err, ErrInvalidCookbook) {
+		t.Errorf("Expected ErrInvalidCookbook without a selector, but got %v", err)
+	}
+	if _, err := service.GetCookbook(reader, models.CookbookSelection{Tag: "soup", CollectionID: collection.ID}); !errors.Is(err, ErrInvalidCookbook) {
+		t.Errorf("Expected ErrInvalidCookbook with two selectors, but got %v", err)
+	}
+	if _, err := service.GetCookbook(reader, models.CookbookSelection{IDs: []string{soup.ID, draft.ID}}); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound for another user's draft, but got %v", err)
+	}
+	if _, err := service.GetCookbook(reader, models.CookbookSelection{Tag: "missing"}); !errors.Is(err, ErrInvalidCookbook) {
+		t.Errorf("Expected ErrInvalidCookbook for a tag without recipes, but got %v", err)
+	}
+
+	cookbook, err := service.GetCookbook(reader, models.CookbookSelection{CollectionID: collection.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if cookbook.Title != "Lunches" || len(cookbook.Recipes) != 2 || cookbook.Recipes[0].ID != salad.ID {
+		t.Fatalf("Expected the salad and the soup in Lunches, but got %+v", cookbook)
+	}
+
+	var book bytes.Buffer
+	if err := service.WriteCookbookEPUB(&book, reader, cookbook); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(book.Bytes()), int64(book.Len()))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// The mimetype comes first, stored as it is
+	first := archive.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store || len(first.Extra) != 0 ||
+		!bytes.HasPrefix(book.Bytes()[30:], []byte("mimetypeapplication/epub+zip")) {
+		t.Errorf("Expected an uncompressed mimetype entry first, but got %+v", first.FileHeader)
+	}
+
+	// Every generated file is well-formed XML, and the chapters follow the collection's order
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		contents, err := file.Open()
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		data, _ := io.ReadAll(contents)
+		contents.Close()
+		files[file.Name] = string(data)
+
+		if strings.HasSuffix(file.Name, ".xml") || strings.HasSuffix(file.Name, ".opf") ||
+			strings.HasSuffix(file.Name, ".ncx") || strings.HasSuffix(file.Name, ".xhtml") {
+			decoder := xml.NewDecoder(bytes.NewReader(data))
+			for {
+				if _, err := decoder.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("Expected %s to be well-formed, but got: %v", file.Name, err)
+					break
+				}
+			}
+		}
+	}
+
+	for _, name := range []string{"META-INF/container.xml", "OEBPS/package.opf", "OEBPS/nav.xhtml", "OEBPS/toc.ncx",
+		"OEBPS/recipe-001.xhtml", "OEBPS/recipe-002.xhtml", "OEBPS/images/recipe-002-cover.png"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected the EPUB to contain %s", name)
+		}
+	}
+	if !strings.Contains(files["OEBPS/package.opf"], `href="images/recipe-002-cover.png" media-type="image/png" properties="cover-image"`) {
+		t.Errorf("Expected the soup's cover to be the book cover, but got:\n%s", files["OEBPS/package.opf"])
+	}
+	nav := files["OEBPS/nav.xhtml"]
+	if strings.Index(nav, "Salad &lt;Niçoise&gt;") > strings.Index(nav, "Tomato &amp; Basil Soup") {
+		t.Errorf("Expected the salad before the soup in the table of contents, but got:\n%s", nav)
+	}
+	if chapter := files["OEBPS/recipe-002.xhtml"]; !strings.Contains(chapter, `<img src="images/recipe-002-cover.png"`) ||
+		!strings.Contains(chapter, "<li>1 cup water</li>") || !strings.Contains(chapter, "<li>Cook it</li>") {
+		t.Errorf("Expected the soup's cover, ingredients and steps, but got:\n%s", chapter)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+
+	"playground/models"
+)
+
+// diffIgnoredFields are recipe fields that change on every save, never change or are
+// derived from other fields, so they are left out of revision diffs. The flat ingredient
+// and instruction lists mirror the sections, which are diffed instead. Images are not part
+// of the content and are changed without a new revision.
+var diffIgnoredFields = map[string]bool{
+	"id":           true,
+	"authorId":     true,
+	"forkedFrom":   true,
+	"diet":         true,
+	"ingredients":  true,
+	"instructions": true,
+	"images":       true,
+	"coverImageId": true,
+	"revision":     true,
+	"createdAt":    true,
+	"updatedAt":    true,
+}
+
+// diffRecipes returns the fields that differ between two recipe snapshots, named by their JSON keys
+func diffRecipes(from, to models.Recipe) []models.FieldChange {
+	changes := make([]models.FieldChange, 0)
+
+	fromValue := reflect.ValueOf(from)
+	toValue := reflect.ValueOf(to)
+	recipeType := fromValue.Type()
+
+	for i := 0; i < recipeType.NumField(); i++ {
+		field := recipeType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || diffIgnoredFields[name] {
+			continue
+		}
+
+		oldValue := fromValue.Field(i).Interface()
+		newValue := toValue.Field(i).Interface()
+		if isEmptyCollection(fromValue.Field(i)) && isEmptyCollection(toValue.Field(i)) {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, models.FieldChange{
+				Field: name,
+				From:  oldValue,
+				To:    newValue,
+			})
+		}
+	}
+
+	return changes
+}
+
+// isEmptyCollection treats nil and empty slices or maps alike, so a missing list is not reported as a change
+func isEmptyCollection(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return false
+}
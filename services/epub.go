@@ -0,0 +1,197 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"fmt"
+	"hash/crc32"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"playground/models"
+)
+
+// epubFiles holds the templates and fixed files of an EPUB cookbook
+//
+//go:embed templates/epub
+var epubFiles embed.FS
+
+// epubTemplates renders the package document, the tables of contents and the recipe chapters
+var epubTemplates = template.Must(template.New("epub").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).ParseFS(epubFiles, "templates/epub/*.opf", "templates/epub/*.ncx", "templates/epub/*.xhtml"))
+
+// xmlDeclaration starts every generated XML file; html/template would escape it inside a template
+const xmlDeclaration = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+// epubImageExtensions maps the content types of recipe images to their file extension
+var epubImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// epubImage is an image embedded in an EPUB
+type epubImage struct {
+	ID          string
+	Href        string
+	ContentType string
+	// BookCover marks the image shown for the whole book on e-reader shelves
+	BookCover bool
+	data      []byte
+}
+
+// epubChapter is the chapter of one recipe
+type epubChapter struct {
+	ID     string
+	Href   string
+	Recipe models.Recipe
+	Facts  string
+	Cover  *epubImage
+}
+
+// epubBook is the data of the package document and the tables of contents
+type epubBook struct {
+	Identifier string
+	Title      string
+	Modified   string
+	Chapters   []epubChapter
+}
+
+// writeEPUB writes a cookbook as an EPUB 3 file with a table of contents and a chapter per recipe.
+// covers holds the cover image data of recipes by recipe ID, with their content type; the first
+// one also becomes the cover of the book.
+func writeEPUB(w io.Writer, cookbook models.Cookbook, covers map[string]epubImage, modified time.Time) error {
+	// The same recipes always make the same book, so readers recognize a re-export as an update
+	ids := make([]string, 0, len(cookbook.Recipes))
+	for _, recipe := range cookbook.Recipes {
+		ids = append(ids, recipe.ID)
+	}
+	book := epubBook{
+		Identifier: "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte(strings.Join(ids, ","))).String(),
+		Title:      cookbook.Title,
+		Modified:   modified.UTC().Format("2006-01-02T15:04:05Z"),
+		Chapters:   make([]epubChapter, 0, len(cookbook.Recipes)),
+	}
+
+	bookCover := true
+	for i, recipe := range cookbook.Recipes {
+		chapter := epubChapter{
+			ID:     fmt.Sprintf("recipe-%03d", i+1),
+			Recipe: recipe,
+			Facts:  recipeFacts(recipe),
+		}
+		chapter.Href = chapter.ID + ".xhtml"
+		if cover, ok := covers[recipe.ID]; ok {
+			if extension, known := epubImageExtensions[cover.ContentType]; known {
+				cover.ID = chapter.ID + "-cover"
+				cover.Href = "images/" + cover.ID + extension
+				cover.BookCover, bookCover = bookCover, false
+				chapter.Cover = &cover
+			}
+		}
+		book.Chapters = append(book.Chapters, chapter)
+	}
+
+	archive := zip.NewWriter(w)
+
+	// The mimetype comes first, uncompressed and without extra fields, so readers can identify the file
+	mimetype := []byte("application/epub+zip")
+	part, err := archive.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(mimetype),
+		CompressedSize64:   uint64(len(mimetype)),
+		UncompressedSize64: uint64(len(mimetype)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(mimetype); err != nil {
+		return err
+	}
+
+	add := func(name string, data []byte) error {
+		part, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(data)
+		return err
+	}
+	copyFile := func(name, source string) error {
+		data, err := epubFiles.ReadFile("templates/epub/" + source)
+		if err != nil {
+			return err
+		}
+		return add(name, data)
+	}
+	render := func(name, templateName string, data interface{}) error {
+		var buf bytes.Buffer
+		buf.WriteString(xmlDeclaration)
+		if err := epubTemplates.ExecuteTemplate(&buf, templateName, data); err != nil {
+			return err
+		}
+		return add(name, bytes.Map(xmlRune, buf.Bytes()))
+	}
+
+	if err := copyFile("META-INF/container.xml", "container.xml"); err != nil {
+		return err
+	}
+	if err := copyFile("OEBPS/style.css", "style.css"); err != nil {
+		return err
+	}
+	if err := render("OEBPS/package.opf", "package.opf", book); err != nil {
+		return err
+	}
+	if err := render("OEBPS/toc.ncx", "toc.ncx", book); err != nil {
+		return err
+	}
+	if err := render("OEBPS/nav.xhtml", "nav.xhtml", book); err != nil {
+		return err
+	}
+	for _, chapter := range book.Chapters {
+		if err := render("OEBPS/"+chapter.Href, "chapter.xhtml", chapter); err != nil {
+			return err
+		}
+		if chapter.Cover != nil {
+			if err := add("OEBPS/"+chapter.Cover.Href, chapter.Cover.data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return archive.Close()
+}
+
+// recipeFacts describes the servings, times and tags of a recipe on the line below its title
+func recipeFacts(recipe models.Recipe) string {
+	var facts []string
+	if recipe.Servings > 0 {
+		facts = append(facts, fmt.Sprintf("Serves %d", recipe.Servings))
+	}
+	if recipe.PrepTime > 0 {
+		facts = append(facts, "Prep "+FormatMinutes(recipe.PrepTime))
+	}
+	if recipe.CookTime > 0 {
+		facts = append(facts, "Cook "+FormatMinutes(recipe.CookTime))
+	}
+	if len(recipe.Tags) > 0 {
+		facts = append(facts, strings.Join(recipe.Tags, ", "))
+	}
+	return strings.Join(facts, " · ")
+}
+
+// xmlRune drops the control characters XML documents may not contain
+func xmlRune(r rune) rune {
+	switch {
+	case r == '\t', r == '\n', r == '\r':
+		return r
+	case r < 0x20, r == 0xFFFE, r == 0xFFFF:
+		return -1
+	}
+	return r
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"playground/models"
+	"playground/repositories"
+)
+
+// SortByFavorites sorts recipes by how many users have favorited them
+const SortByFavorites SortBy = "favorites"
+
+// FavoriteService handles business logic for favorite recipes
+type FavoriteService struct {
+	repository    repositories.FavoriteRepository
+	recipeService *RecipeService
+}
+
+// NewFavoriteService creates a new favorite service with the given repository
+func NewFavoriteService(repository repositories.FavoriteRepository, recipeService *RecipeService) *FavoriteService {
+	service := &FavoriteService{
+		repository:    repository,
+		recipeService: recipeService,
+	}
+
+	// Favorites of recipes in the trash stay hidden until the recipe is restored or purged
+	recipeService.On(RecipePurged, func(recipe models.Recipe) {
+		repository.DeleteByRecipeID(recipe.ID)
+	})
+	recipeService.RegisterSortKey(SortByFavorites, func(recipe models.Recipe, _ Caller) (float64, bool) {
+		return float64(repository.CountByRecipeID(recipe.ID)), true
+	})
+
+	return service
+}
+
+// AddFavorite favorites a recipe the caller may see; favoriting it again changes nothing
+func (s *FavoriteService) AddFavorite(recipeID string, caller Caller) (models.Favorite, error) {
+	if _, err := s.recipeService.GetVisibleRecipe(recipeID, caller); err != nil {
+		return models.Favorite{}, err
+	}
+	return s.repository.Add(caller.UserID, recipeID), nil
+}
+
+// RemoveFavorite takes a recipe out of the caller's favorites
+func (s *FavoriteService) RemoveFavorite(recipeID string, caller Caller) error {
+	if !s.repository.Remove(caller.UserID, recipeID) {
+		return ErrRecipeNotFound
+	}
+	return nil
+}
+
+// GetFavorites returns the caller's favorite recipes that the caller may still see, most recently favorited first
+func (s *FavoriteService) GetFavorites(caller Caller) []models.Recipe {
+	favorites := s.repository.FindByUserID(caller.UserID)
+	Sort(favorites, func(i, j int) bool {
+		return favorites[i].CreatedAt.After(favorites[j].CreatedAt)
+	})
+
+	recipes := make([]models.Recipe, 0, len(favorites))
+	for _, favorite := range favorites {
+		if recipe, err := s.recipeService.GetVisibleRecipe(favorite.RecipeID, caller); err == nil {
+			recipes = append(recipes, recipe)
+		}
+	}
+	return recipes
+}
+
+// IsFavorite reports whether the caller has favorited a recipe
+func (s *FavoriteService) IsFavorite(recipeID string, caller Caller) bool {
+	return caller.UserID != "" && s.repository.Exists(caller.UserID, recipeID)
+}
+
+// CountFavorites returns how many users have favorited a recipe
+func (s *FavoriteService) CountFavorites(recipeID string) int {
+	return s.repository.CountByRecipeID(recipeID)
+}
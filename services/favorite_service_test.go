@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestFavorites tests favoriting, listing, sorting by favorite count and cleanup of purged recipes
+func TestFavorites(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	favoriteRepo := repositories.NewInMemoryFavoriteRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	service := NewFavoriteService(favoriteRepo, recipeService)
+
+	author := Caller{UserID: "author-1"}
+	alice := Caller{UserID: "alice"}
+	bob := Caller{UserID: "bob"}
+
+	soup := createPublishedRecipe(t, recipeService, author.UserID, "Soup")
+	stew := createPublishedRecipe(t, recipeService, author.UserID, "Stew")
+	draft, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{Title: "Draft"})
+
+	if _, err := service.AddFavorite(draft.ID, alice); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound for a draft, but got %v", err)
+	}
+
+	service.AddFavorite(soup.ID, alice)
+	service.AddFavorite(stew.ID, alice)
+	service.AddFavorite(stew.ID, bob)
+	if _, err := service.AddFavorite(stew.ID, bob); err != nil {
+		t.Errorf("Expected favoriting twice to succeed, but got %v", err)
+	}
+	if count := service.CountFavorites(stew.ID); count != 2 {
+		t.Errorf("Expected 2 favorites, but got %d", count)
+	}
+	if !service.IsFavorite(soup.ID, alice) || service.IsFavorite(soup.ID, bob) || service.IsFavorite(soup.ID, Caller{}) {
+		t.Error("Expected only alice to have favorited the soup")
+	}
+
+	sorted := recipeService.SortRecipes(SortByFavorites, false, alice)
+	if got := titles(sorted); strings.Join(got, ",") != "Stew,Soup" {
+		t.Errorf("Expected Stew before Soup, but got %v", got)
+	}
+
+	// Favorites of recipes in the trash are hidden until the recipe is purged
+	recipeService.DeleteRecipe(soup.ID, author)
+	if favorites := service.GetFavorites(alice); len(favorites) != 1 || favorites[0].ID != stew.ID {
+		t.Errorf("Expected only the stew, but got %v", titles(favorites))
+	}
+	recipeService.PurgeDeletedRecipes(time.Now().Add(time.Minute))
+	if len(favoriteRepo.FindByUserID(alice.UserID)) != 1 {
+		t.Error("Expected the purged soup to be removed from favorites")
+	}
+
+	if err := service.RemoveFavorite(stew.ID, alice); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := service.RemoveFavorite(stew.ID, alice); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound when removing twice, but got %v", err)
+	}
+}
+
+// TestRecipeNotes tests that notes are private to their user and validated
+func TestRecipeNotes(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	noteRepo := repositories.NewInMemoryNoteRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	service := NewNoteService(noteRepo, recipeService)
+
+	alice := Caller{UserID: "alice"}
+	bob := Caller{UserID: "bob"}
+	cake := createPublishedRecipe(t, recipeService, "author-1", "Cake")
+
+	invalid := []string{"   ", strings.Repeat("a", MaxNoteLength+1)}
+	for _, text := range invalid {
+		if _, err := service.SaveNote(cake.ID, alice, models.RecipeNoteInput{Text: text}); !errors.Is(err, ErrInvalidNote) {
+			t.Errorf("Expected ErrInvalidNote, but got %v", err)
+		}
+	}
+
+	note, err := service.SaveNote(cake.ID, alice, models.RecipeNoteInput{Text: " used half the sugar "})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	updated, _ := service.SaveNote(cake.ID, alice, models.RecipeNoteInput{Text: "used a third of the sugar"})
+	if updated.Text != "used a third of the sugar" || !updated.CreatedAt.Equal(note.CreatedAt) {
+		t.Errorf("Expected the note to be replaced in place, but got %+v", updated)
+	}
+
+	if _, err := service.GetNote(cake.ID, bob); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Expected other users not to see the note, but got %v", err)
+	}
+	if _, err := service.GetNote(cake.ID, Caller{}); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Expected anonymous callers not to see the note, but got %v", err)
+	}
+
+	recipeService.DeleteRecipe(cake.ID, Caller{UserID: "author-1"})
+	recipeService.PurgeDeletedRecipes(time.Now().Add(time.Minute))
+	if _, err := service.GetNote(cake.ID, alice); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Expected the note to be purged with its recipe, but got %v", err)
+	}
+}
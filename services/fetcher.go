@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// MaxImportBytes is the largest document, posted or fetched, that will be imported
+const MaxImportBytes = 5 << 20
+
+// fetchTimeout bounds how long fetching a page to import may take
+const fetchTimeout = 10 * time.Second
+
+// ErrImportTooLarge is returned when a document to import exceeds MaxImportBytes
+var ErrImportTooLarge = errors.New("document is too large: at most 5 MB")
+
+// ErrFetchFailed is returned when the page to import cannot be fetched
+var ErrFetchFailed = errors.New("cannot fetch the page to import")
+
+// errPrivateAddress is returned by the public fetcher for hosts that resolve to a non-public address
+var errPrivateAddress = errors.New("address is not public")
+
+// nonPublicPrefixes are the address ranges the public fetcher refuses to connect to: private,
+// shared, loopback, link-local, documentation, benchmarking, multicast and reserved networks, and
+// the IPv6 ranges that translate to or tunnel IPv4 addresses
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// Fetcher retrieves the document at a URL so it can be imported
+type Fetcher interface {
+	Fetch(url string) ([]byte, error)
+}
+
+// HTTPFetcher fetches documents over HTTP with the given client
+type HTTPFetcher struct {
+	client *http.Client
+}
+
+// NewHTTPFetcher creates a fetcher that sends its requests through client
+func NewHTTPFetcher(client *http.Client) *HTTPFetcher {
+	return &HTTPFetcher{
+		client: client,
+	}
+}
+
+// NewPublicHTTPFetcher creates a fetcher that only connects to public addresses, so imports
+// cannot be used to reach the server's own network
+func NewPublicHTTPFetcher() *HTTPFetcher {
+	dialer := &net.Dialer{
+		Timeout: fetchTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", errPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return NewHTTPFetcher(&http.Client{
+		Transport: transport,
+		Timeout:   fetchTimeout,
+	})
+}
+
+// isPublicIP reports whether ip can be reached on the public internet. IPv4-mapped addresses are
+// checked as the IPv4 address they carry.
+func isPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap().WithZone("")
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return ip.IsValid()
+}
+
+// Fetch returns the body of a successful GET request for url
+func (f *HTTPFetcher) Fetch(url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+	req.Header.Set("Accept", "text/html, application/ld+json;q=0.9, application/json;q=0.8")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrFetchFailed, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxImportBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+	if len(data) > MaxImportBytes {
+		return nil, ErrImportTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"net/netip"
+	"testing"
+)
+
+// TestIsPublicIP tests that the public fetcher only connects to public addresses
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"198.18.0.1", false},
+		{"198.19.255.255", false},
+		{"192.0.0.170", false},
+		{"0.0.0.0", false},
+		{"255.255.255.255", false},
+		{"224.0.0.1", false},
+		{"::1", false},
+		{"::", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:93.184.216.34", true},
+		{"64:ff9b::a9fe:a9fe", false},
+		{"2002:7f00:1::", false},
+		{"fd00::1", false},
+		{"fe80::1%eth0", false},
+		{"ff02::1", false},
+	}
+
+	for _, tc := range tests {
+		if got := isPublicIP(netip.MustParseAddr(tc.address)); got != tc.expected {
+			t.Errorf("Expected %s public to be %v, but got %v", tc.address, tc.expected, got)
+		}
+	}
+}
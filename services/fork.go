@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+
+	"playground/models"
+)
+
+// ErrNotAFork is returned when an upstream diff is requested for a recipe that was not forked
+var ErrNotAFork = errors.New("recipe is not a fork")
+
+// ErrUpstreamUnavailable is returned when the recipe a fork was copied from is gone or hidden from the caller
+var ErrUpstreamUnavailable = errors.New("upstream recipe is no longer available")
+
+// ForkRecipe copies a recipe the caller may see into a new draft owned by the caller. Sub-recipes
+// the caller may not see become plain ingredients of the fork, as saving it would otherwise fail.
+func (s *RecipeService) ForkRecipe(id string, caller Caller) (models.Recipe, error) {
+	parent, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	parent = parent.MapIngredients(func(ingredient models.Ingredient) models.Ingredient {
+		if ingredient.IsSubRecipe() {
+			if _, ok := s.findSubRecipe(ingredient.RecipeID, caller); !ok {
+				ingredient.RecipeID = ""
+			}
+		}
+		return ingredient
+	})
+	parent.Diet = s.classify(parent.Input(), caller, "")
+
+	return s.repository.CreateFork(caller.UserID, parent), nil
+}
+
+// GetForks returns the direct forks of a recipe the caller may see that are listed for the caller
+func (s *RecipeService) GetForks(id string, caller Caller) ([]models.Recipe, error) {
+	if _, err := s.GetVisibleRecipe(id, caller); err != nil {
+		return nil, err
+	}
+	return listedFor(s.repository.FindForks(id), caller), nil
+}
+
+// GetLineage returns the ancestors of a recipe the caller may see, nearest first. Ancestors in the
+// trash or hidden from the caller keep their place in the lineage without their content; the walk
+// stops at an ancestor that was purged.
+func (s *RecipeService) GetLineage(id string, caller Caller) ([]models.LineageEntry, error) {
+	recipe, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage := make([]models.LineageEntry, 0)
+	seen := map[string]bool{recipe.ID: true}
+	for reference := recipe.ForkedFrom; reference != nil && !seen[reference.RecipeID]; {
+		seen[reference.RecipeID] = true
+		entry := models.LineageEntry{
+			RecipeID: reference.RecipeID,
+			Revision: reference.Revision,
+		}
+
+		ancestor, err := s.repository.FindByID(reference.RecipeID)
+		if err != nil {
+			// Keep walking through recipes in the trash without showing them
+			ancestor, err = s.repository.FindDeletedByID(reference.RecipeID)
+			if err != nil {
+				lineage = append(lineage, entry)
+				break
+			}
+		} else if canView(ancestor, caller) {
+			entry.Recipe = &ancestor
+		}
+
+		lineage = append(lineage, entry)
+		reference = ancestor.ForkedFrom
+	}
+
+	return lineage, nil
+}
+
+// upstreamIgnoredFields are recipe fields that describe who can see the parent rather than its
+// content, so they are left out of upstream diffs
+var upstreamIgnoredFields = []string{"status", "visibility"}
+
+// DiffUpstream returns the content changes made to a fork's parent since the revision the fork was
+// copied from. An empty list of changes means the upstream recipe has not changed.
+func (s *RecipeService) DiffUpstream(id string, caller Caller) (models.RevisionDiff, error) {
+	fork, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+	if fork.ForkedFrom == nil {
+		return models.RevisionDiff{}, ErrNotAFork
+	}
+
+	parent, err := s.GetVisibleRecipe(fork.ForkedFrom.RecipeID, caller)
+	if err != nil {
+		return models.RevisionDiff{}, ErrUpstreamUnavailable
+	}
+
+	base, err := s.repository.FindRevision(parent.ID, fork.ForkedFrom.Revision)
+	if err != nil {
+		return models.RevisionDiff{}, ErrUpstreamUnavailable
+	}
+
+	changes := Filter(diffRecipes(base.Snapshot, parent), func(change models.FieldChange) bool {
+		return !Contains(upstreamIgnoredFields, change.Field)
+	})
+
+	return models.RevisionDiff{
+		RecipeID: parent.ID,
+		From:     base.Number,
+		To:       parent.Revision,
+		Changes:  changes,
+	}, nil
+}
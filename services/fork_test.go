@@ -0,0 +1,115 @@
+package services
+
+import (
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestForkRecipe tests forking, listing forks, lineage and upstream diffs
+func TestForkRecipe(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the service with the repository
+	service := NewRecipeService(repo)
+
+	author := Caller{UserID: "author-1"}
+	forker := Caller{UserID: "forker-1"}
+	grandForker := Caller{UserID: "forker-2"}
+
+	parent := createPublishedRecipe(t, service, author.UserID, "Chili")
+
+	// Drafts of other users cannot be forked
+	draft, _ := service.CreateRecipe(author.UserID, models.RecipeInput{Title: "Secret Chili"})
+	if _, err := service.ForkRecipe(draft.ID, forker); err != ErrRecipeNotFound {
+		t.Errorf("Expected ErrRecipeNotFound, but got %v", err)
+	}
+
+	fork, err := service.ForkRecipe(parent.ID, forker)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if fork.AuthorID != forker.UserID || fork.Status != models.StatusDraft || fork.Title != parent.Title {
+		t.Errorf("Expected a draft copy owned by the forker, but got %+v", fork)
+	}
+	if fork.ForkedFrom == nil || fork.ForkedFrom.RecipeID != parent.ID || fork.ForkedFrom.Revision != parent.Revision {
+		t.Fatalf("Expected fork to point at %s revision %d, but got %+v", parent.ID, parent.Revision, fork.ForkedFrom)
+	}
+
+	// Editing the fork keeps its origin
+	input := fork.Input()
+	input.Title = "Smoky Chili"
+	fork, err = service.UpdateRecipe(fork.ID, forker, input)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if fork.ForkedFrom == nil {
+		t.Fatal("Expected fork to keep its origin after an update")
+	}
+	if _, err := service.TransitionRecipe(fork.ID, forker, models.StatusPublished); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Published forks are listed for everyone
+	forks, err := service.GetForks(parent.ID, grandForker)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(forks) != 1 || forks[0].ID != fork.ID {
+		t.Errorf("Expected 1 fork, but got %+v", forks)
+	}
+
+	// Lineage walks every ancestor, nearest first
+	grandchild, err := service.ForkRecipe(fork.ID, grandForker)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	lineage, err := service.GetLineage(grandchild.ID, grandForker)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(lineage) != 2 || lineage[0].RecipeID != fork.ID || lineage[1].RecipeID != parent.ID {
+		t.Fatalf("Expected lineage of fork then parent, but got %+v", lineage)
+	}
+
+	// Ancestors in the trash keep their place without their content
+	if err := service.DeleteRecipe(fork.ID, forker); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	lineage, _ = service.GetLineage(grandchild.ID, grandForker)
+	if len(lineage) != 2 || lineage[0].Recipe != nil || lineage[1].Recipe == nil {
+		t.Errorf("Expected hidden fork and visible parent in lineage, but got %+v", lineage)
+	}
+	if _, err := service.RestoreRecipe(fork.ID, forker); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// The upstream diff is empty until the parent changes
+	diff, err := service.DiffUpstream(fork.ID, forker)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(diff.Changes) != 0 {
+		t.Errorf("Expected no upstream changes, but got %+v", diff.Changes)
+	}
+
+	parentInput := parent.Input()
+	parentInput.Servings = 8
+	if _, err := service.UpdateRecipe(parent.ID, author, parentInput); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	diff, err = service.DiffUpstream(fork.ID, forker)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if diff.From != 1 || diff.To != 2 || len(diff.Changes) != 1 || diff.Changes[0].Field != "servings" {
+		t.Errorf("Expected servings to change from revision 1 to 2, but got %+v", diff)
+	}
+
+	if _, err := service.DiffUpstream(parent.ID, author); err != ErrNotAFork {
+		t.Errorf("Expected ErrNotAFork, but got %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"playground/models"
+	"playground/repositories"
+)
+
+// MaxImageBytes is the largest image file that can be uploaded
+const MaxImageBytes = 10 << 20
+
+// MaxImagePixels is the largest image, in pixels, that will be decoded
+const MaxImagePixels = 40_000_000
+
+// imageMaxSides is the longest side of each resized rendition
+var imageMaxSides = map[models.ImageSize]int{
+	models.ImageMedium:    1024,
+	models.ImageThumbnail: 256,
+}
+
+// jpegQuality is the quality every JPEG rendition is encoded with
+const jpegQuality = 85
+
+// ErrImageTooLarge is returned when an upload exceeds the size or pixel limit
+var ErrImageTooLarge = errors.New("image is too large: at most 10 MB and 40 megapixels")
+
+// ErrUnsupportedImage is returned when an upload is not a JPEG, PNG or GIF image
+var ErrUnsupportedImage = errors.New("unsupported image: upload a JPEG, PNG or GIF")
+
+// ErrImageNotFound is returned when a recipe has no image with the given ID or size
+var ErrImageNotFound = errors.New("image not found")
+
+// ErrInvalidImageOrder is returned when a new image order does not list every image exactly once
+var ErrInvalidImageOrder = errors.New("image order must list every image of the recipe exactly once")
+
+// ImageService handles business logic for recipe images
+type ImageService struct {
+	recipeService *RecipeService
+	blobs         repositories.BlobStore
+	// mutex serializes changes to image lists, which are read, changed and written back
+	mutex sync.Mutex
+}
+
+// NewImageService creates a new image service storing images in the given blob store
+func NewImageService(blobs repositories.BlobStore, recipeService *RecipeService) *ImageService {
+	service := &ImageService{
+		recipeService: recipeService,
+		blobs:         blobs,
+	}
+
+	// Image files disappear with their recipe
+	recipeService.On(RecipePurged, func(recipe models.Recipe) {
+		for _, img := range recipe.Images {
+			service.deleteBlobs(img)
+		}
+	})
+
+	return service
+}
+
+// UploadImage stores a new image of a recipe the caller owns in every size and appends it to the
+// recipe's images. The first image becomes the cover. The content type is sniffed from the data,
+// and the image is re-encoded, which drops EXIF and other metadata after applying its orientation.
+func (s *ImageService) UploadImage(recipeID string, caller Caller, content io.Reader, caption string) (models.RecipeImage, error) {
+	if _, err := s.ownedRecipe(recipeID, caller); err != nil {
+		return models.RecipeImage{}, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, MaxImageBytes+1))
+	if err != nil {
+		return models.RecipeImage{}, err
+	}
+	if len(data) > MaxImageBytes {
+		return models.RecipeImage{}, ErrImageTooLarge
+	}
+
+	img, contentType, err := decodeImage(data)
+	if err != nil {
+		return models.RecipeImage{}, err
+	}
+
+	stored := models.RecipeImage{
+		ID:          uuid.New().String(),
+		ContentType: contentType,
+		Caption:     caption,
+		Variants:    make(map[models.ImageSize]models.ImageVariant, len(models.ImageSizes)),
+		UploadedBy:  caller.UserID,
+		CreatedAt:   time.Now(),
+	}
+	for _, size := range models.ImageSizes {
+		rendition := img
+		if maxSide, ok := imageMaxSides[size]; ok {
+			rendition = resizeToFit(img, maxSide)
+		}
+
+		variant, err := s.storeVariant(recipeID, stored.ID, size, rendition, contentType)
+		if err != nil {
+			s.deleteBlobs(stored)
+			return models.RecipeImage{}, err
+		}
+		stored.Variants[size] = variant
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Read the recipe again: it may have been deleted or changed while the image was processed
+	recipe, err := s.ownedRecipe(recipeID, caller)
+	if err == nil {
+		cover := recipe.CoverImageID
+		if cover == "" {
+			cover = stored.ID
+		}
+		images := append(append(make([]models.RecipeImage, 0, len(recipe.Images)+1), recipe.Images...), stored)
+		_, err = s.recipeService.setImages(recipeID, images, cover)
+	}
+	if err != nil {
+		s.deleteBlobs(stored)
+		return models.RecipeImage{}, err
+	}
+	return stored, nil
+}
+
+// OpenImage returns a reader for one size of an image of a recipe the caller may see
+func (s *ImageService) OpenImage(recipeID, imageID string, size models.ImageSize, caller Caller) (io.ReadCloser, models.RecipeImage, error) {
+	recipe, err := s.recipeService.GetVisibleRecipe(recipeID, caller)
+	if err != nil {
+		return nil, models.RecipeImage{}, err
+	}
+
+	img, _, err := findImage(recipe, imageID)
+	if err != nil {
+		return nil, models.RecipeImage{}, err
+	}
+	variant, ok := img.Variants[size]
+	if !ok {
+		return nil, models.RecipeImage{}, ErrImageNotFound
+	}
+
+	reader, err := s.blobs.Open(variant.Key)
+	if errors.Is(err, repositories.ErrBlobNotFound) {
+		return nil, models.RecipeImage{}, ErrImageNotFound
+	}
+	return reader, img, err
+}
+
+// ReorderImages puts the images of a recipe the caller owns in the given order
+func (s *ImageService) ReorderImages(recipeID string, caller Caller, imageIDs []string) (models.Recipe, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	recipe, err := s.ownedRecipe(recipeID, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	if len(imageIDs) != len(recipe.Images) {
+		return models.Recipe{}, ErrInvalidImageOrder
+	}
+
+	images := make([]models.RecipeImage, 0, len(imageIDs))
+	seen := make(map[string]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		img, _, err := findImage(recipe, id)
+		if err != nil || seen[id] {
+			return models.Recipe{}, ErrInvalidImageOrder
+		}
+		seen[id] = true
+		images = append(images, img)
+	}
+
+	return s.recipeService.setImages(recipeID, images, recipe.CoverImageID)
+}
+
+// SetCoverImage makes an image the cover of a recipe the caller owns
+func (s *ImageService) SetCoverImage(recipeID, imageID string, caller Caller) (models.Recipe, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	recipe, err := s.ownedRecipe(recipeID, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	if _, _, err := findImage(recipe, imageID); err != nil {
+		return models.Recipe{}, err
+	}
+
+	return s.recipeService.setImages(recipeID, recipe.Images, imageID)
+}
+
+// DeleteImage removes an image and its files from a recipe the caller owns. When the cover
+// is deleted the first remaining image becomes the cover.
+func (s *ImageService) DeleteImage(recipeID, imageID string, caller Caller) (models.Recipe, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	recipe, err := s.ownedRecipe(recipeID, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	img, index, err := findImage(recipe, imageID)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	images := append(append(make([]models.RecipeImage, 0, len(recipe.Images)-1), recipe.Images[:index]...), recipe.Images[index+1:]...)
+	cover := recipe.CoverImageID
+	if cover == imageID {
+		cover = ""
+		if len(images) > 0 {
+			cover = images[0].ID
+		}
+	}
+
+	updated, err := s.recipeService.setImages(recipeID, images, cover)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	s.deleteBlobs(img)
+	return updated, nil
+}
+
+// ownedRecipe returns a recipe if the caller owns it or is an admin
+func (s *ImageService) ownedRecipe(recipeID string, caller Caller) (models.Recipe, error) {
+	return s.recipeService.modifiableRecipe(recipeID, caller)
+}
+
+// storeVariant encodes one rendition of an image and writes it to the blob store
+func (s *ImageService) storeVariant(recipeID, imageID string, size models.ImageSize, img *image.RGBA, contentType string) (models.ImageVariant, error) {
+	var buf bytes.Buffer
+	extension := ".png"
+	if contentType == "image/jpeg" {
+		extension = ".jpg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return models.ImageVariant{}, err
+		}
+	} else if err := png.Encode(&buf, img); err != nil {
+		return models.ImageVariant{}, err
+	}
+
+	variant := models.ImageVariant{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+		Bytes:  buf.Len(),
+		URL:    fmt.Sprintf("/api/recipes/%s/images/%s/%s", recipeID, imageID, size),
+		Key:    fmt.Sprintf("recipes/%s/%s/%s%s", recipeID, imageID, size, extension),
+	}
+	if err := s.blobs.Put(variant.Key, &buf); err != nil {
+		return models.ImageVariant{}, err
+	}
+	return variant, nil
+}
+
+// deleteBlobs removes the stored files of every size of an image, logging failures
+// since the image is already gone from its recipe
+func (s *ImageService) deleteBlobs(img models.RecipeImage) {
+	for _, variant := range img.Variants {
+		if err := s.blobs.Delete(variant.Key); err != nil {
+			log.Printf("Cannot delete image file %s: %v", variant.Key, err)
+		}
+	}
+}
+
+// decodeImage sniffs and decodes uploaded image data, checking its pixel count before decoding it
+// in full. JPEG images are turned upright; GIF images keep their first frame and are stored as PNG.
+func decodeImage(data []byte) (*image.RGBA, string, error) {
+	var decode func(io.Reader) (image.Image, error)
+	var decodeConfig func(io.Reader) (image.Config, error)
+
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg":
+		decode, decodeConfig = jpeg.Decode, jpeg.DecodeConfig
+	case "image/png":
+		decode, decodeConfig = png.Decode, png.DecodeConfig
+	case "image/gif":
+		decode, decodeConfig = gif.Decode, gif.DecodeConfig
+		contentType = "image/png"
+	default:
+		return nil, "", ErrUnsupportedImage
+	}
+
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	decoded, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+
+	img := toRGBA(decoded)
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	return img, contentType, nil
+}
+
+// findImage returns an image of a recipe and its position in the recipe's images
+func findImage(recipe models.Recipe, imageID string) (models.RecipeImage, int, error) {
+	for i, img := range recipe.Images {
+		if img.ID == imageID {
+			return img, i, nil
+		}
+	}
+	return models.RecipeImage{}, -1, ErrImageNotFound
+}
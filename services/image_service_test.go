@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// testImage returns a w x h image whose top-left quadrant is red and the rest white
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 && y < h/2 {
+				img.Set(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF block with the given orientation right after the JPEG start marker
+func withOrientation(data []byte, orientation byte) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, 0, 0, 0, 0}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, 0, byte(len(payload) + 2)}, payload...)
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+// TestUploadImage tests storing, resizing, ordering, cover selection and deletion of recipe images
+func TestUploadImage(t *testing.T) {
+	store, err := repositories.NewFileSystemBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the services with the repository
+	recipeService := NewRecipeService(repo)
+	service := NewImageService(store, recipeService)
+
+	author := Caller{UserID: "author-1"}
+	other := Caller{UserID: "author-2"}
+	recipe, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{Title: "Pancakes"})
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage(600, 300)); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if _, err := service.UploadImage(recipe.ID, other, bytes.NewReader(encoded.Bytes()), ""); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound for another user's draft, but got %v", err)
+	}
+	if _, err := service.UploadImage(recipe.ID, author, strings.NewReader("<html>not an image</html>"), ""); !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("Expected ErrUnsupportedImage, but got %v", err)
+	}
+	if _, err := service.UploadImage(recipe.ID, author, bytes.NewReader(make([]byte, MaxImageBytes+1)), ""); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected ErrImageTooLarge, but got %v", err)
+	}
+
+	first, err := service.UploadImage(recipe.ID, author, bytes.NewReader(encoded.Bytes()), "Stacked")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if first.ContentType != "image/png" || first.Caption != "Stacked" {
+		t.Errorf("Expected a captioned PNG, but got %+v", first)
+	}
+	sizes := map[models.ImageSize][2]int{
+		models.ImageOriginal:  {600, 300},
+		models.ImageMedium:    {600, 300},
+		models.ImageThumbnail: {256, 128},
+	}
+	for size, want := range sizes {
+		variant := first.Variants[size]
+		if variant.Width != want[0] || variant.Height != want[1] {
+			t.Errorf("Expected %s to be %dx%d, but got %dx%d", size, want[0], want[1], variant.Width, variant.Height)
+		}
+	}
+
+	// The stored thumbnail keeps the picture
+	reader, _, err := service.OpenImage(recipe.ID, first.ID, models.ImageThumbnail, other)
+	if !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected images of drafts to be hidden, but got %v", err)
+	}
+	reader, _, err = service.OpenImage(recipe.ID, first.ID, models.ImageThumbnail, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	thumbnail, err := png.Decode(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if r, g, _, _ := thumbnail.At(10, 10).RGBA(); r>>8 != 255 || g>>8 != 0 {
+		t.Errorf("Expected the top-left of the thumbnail to be red, but got %v", thumbnail.At(10, 10))
+	}
+
+	// JPEG images are turned upright and stored without EXIF
+	var photo bytes.Buffer
+	if err := jpeg.Encode(&photo, testImage(40, 20), &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	second, err := service.UploadImage(recipe.ID, author, bytes.NewReader(withOrientation(photo.Bytes(), 6)), "")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	reader, _, err = service.OpenImage(recipe.ID, second.ID, models.ImageOriginal, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	original, _ := io.ReadAll(reader)
+	reader.Close()
+	if bytes.Contains(original, []byte("Exif")) {
+		t.Error("Expected the stored image to have no EXIF block")
+	}
+	upright, err := jpeg.Decode(bytes.NewReader(original))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if upright.Bounds().Dx() != 20 || upright.Bounds().Dy() != 40 {
+		t.Errorf("Expected a 20x40 portrait, but got %v", upright.Bounds())
+	}
+	if r, g, _, _ := upright.At(15, 5).RGBA(); r>>8 < 200 || g>>8 > 80 {
+		t.Errorf("Expected the red corner to turn to the top right, but got %v", upright.At(15, 5))
+	}
+
+	// The first image is the cover until another is chosen
+	recipe, _ = recipeService.GetRecipeByID(recipe.ID)
+	if len(recipe.Images) != 2 || recipe.CoverImageID != first.ID {
+		t.Fatalf("Expected two images with the first as cover, but got %+v", recipe.Images)
+	}
+	if _, err := service.ReorderImages(recipe.ID, author, []string{second.ID, second.ID}); !errors.Is(err, ErrInvalidImageOrder) {
+		t.Errorf("Expected ErrInvalidImageOrder, but got %v", err)
+	}
+	recipe, err = service.ReorderImages(recipe.ID, author, []string{second.ID, first.ID})
+	if err != nil || recipe.Images[0].ID != second.ID || recipe.CoverImageID != first.ID {
+		t.Errorf("Expected the second image first and the cover unchanged, but got %+v (%v)", recipe.Images, err)
+	}
+
+	// Deleting the cover promotes the first remaining image and removes the files
+	recipe, err = service.DeleteImage(recipe.ID, first.ID, author)
+	if err != nil || len(recipe.Images) != 1 || recipe.CoverImageID != second.ID {
+		t.Errorf("Expected the second image to become the cover, but got %+v (%v)", recipe, err)
+	}
+	if _, err := store.Open(first.Variants[models.ImageOriginal].Key); !errors.Is(err, repositories.ErrBlobNotFound) {
+		t.Errorf("Expected the deleted image's files to be gone, but got %v", err)
+	}
+
+	// Editing the recipe keeps its images
+	input := recipe.Input()
+	input.Title = "Fluffy Pancakes"
+	recipe, _ = recipeService.UpdateRecipe(recipe.ID, author, input)
+	if len(recipe.Images) != 1 || recipe.CoverImageID != second.ID {
+		t.Errorf("Expected an update to keep the images, but got %+v", recipe.Images)
+	}
+
+	// Purging the recipe removes its files
+	if err := recipeService.DeleteRecipe(recipe.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	recipeService.PurgeDeletedRecipes(time.Now().Add(time.Minute))
+	if _, err := store.Open(second.Variants[models.ImageThumbnail].Key); !errors.Is(err, repositories.ErrBlobNotFound) {
+		t.Errorf("Expected the purged recipe's files to be gone, but got %v", err)
+	}
+}
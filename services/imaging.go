@@ -0,0 +1,171 @@
+package services
+
+import (
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// exifOrientationTag is the EXIF tag holding how the camera was held
+const exifOrientationTag = 0x0112
+
+// jpegOrientation returns the EXIF orientation (1-8) of JPEG data, or 1 when there is none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Walk the segments before the image data looking for the EXIF block
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		payload := data[i+4 : end]
+		if marker == 0xE1 && len(payload) > 6 && string(payload[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(payload[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first directory of a TIFF header
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// toRGBA copies an image into an RGBA image with its origin at zero
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	result := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(result, result.Bounds(), img, bounds.Min, draw.Src)
+	return result
+}
+
+// applyOrientation turns an image the way its EXIF orientation asks for, so it displays upright
+// once the metadata is gone
+func applyOrientation(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	result := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			// Find the source pixel that lands on (dx, dy)
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-dx, dy
+			case 3: // upside down
+				sx, sy = w-1-dx, h-1-dy
+			case 4: // mirrored upside down
+				sx, sy = dx, h-1-dy
+			case 5: // mirrored and turned
+				sx, sy = dy, dx
+			case 6: // turned a quarter clockwise
+				sx, sy = dy, h-1-dx
+			case 7: // mirrored and turned the other way
+				sx, sy = w-1-dy, h-1-dx
+			case 8: // turned a quarter counterclockwise
+				sx, sy = w-1-dy, dx
+			}
+			copy(result.Pix[result.PixOffset(dx, dy):][:4], img.Pix[img.PixOffset(sx, sy):][:4])
+		}
+	}
+	return result
+}
+
+// resizeToFit scales an image down so its longest side is at most maxSide, averaging every source
+// pixel that falls into each target pixel. Images that already fit are returned as they are.
+func resizeToFit(img *image.RGBA, maxSide int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+
+	dw, dh := maxSide, maxSide
+	if w > h {
+		dh = maxInt(1, h*maxSide/w)
+	} else {
+		dw = maxInt(1, w*maxSide/h)
+	}
+
+	result := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		sy0, sy1 := dy*h/dh, maxInt((dy+1)*h/dh, dy*h/dh+1)
+		for dx := 0; dx < dw; dx++ {
+			sx0, sx1 := dx*w/dw, maxInt((dx+1)*w/dw, dx*w/dw+1)
+
+			var sum [4]int
+			for sy := sy0; sy < sy1; sy++ {
+				row := img.Pix[img.PixOffset(sx0, sy):img.PixOffset(sx1, sy)]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			n := (sy1 - sy0) * (sx1 - sx0)
+			pixel := result.Pix[result.PixOffset(dx, dy):][:4]
+			for c := range pixel {
+				pixel[c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return result
+}
+
+// maxInt returns the larger of two ints
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+
+	"playground/models"
+)
+
+// ErrInvalidImportURL is returned when the page to import is not an absolute http or https URL
+var ErrInvalidImportURL = errors.New("url must be an absolute http or https URL")
+
+// ImportService creates recipes from schema.org JSON-LD found in documents and web pages
+type ImportService struct {
+	recipeService *RecipeService
+	fetcher       Fetcher
+}
+
+// NewImportService creates a new import service that fetches pages with fetcher
+func NewImportService(recipeService *RecipeService, fetcher Fetcher) *ImportService {
+	return &ImportService{
+		recipeService: recipeService,
+		fetcher:       fetcher,
+	}
+}
+
+// ImportDocument creates a draft recipe owned by the caller from a JSON-LD document or an HTML page,
+// credited to sourceURL when it is not empty
+func (s *ImportService) ImportDocument(caller Caller, data []byte, sourceURL string) (models.Recipe, error) {
+	if len(data) > MaxImportBytes {
+		return models.Recipe{}, ErrImportTooLarge
+	}
+
+	input, err := ParseRecipeDocument(data, sourceURL)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	return s.recipeService.CreateRecipe(caller.UserID, input)
+}
+
+// ImportURL fetches the page at rawURL and creates a draft recipe owned by the caller from it
+func (s *ImportService) ImportURL(caller Caller, rawURL string) (models.Recipe, error) {
+	page, err := url.Parse(rawURL)
+	if err != nil || (page.Scheme != "http" && page.Scheme != "https") || page.Host == "" {
+		return models.Recipe{}, ErrInvalidImportURL
+	}
+
+	data, err := s.fetcher.Fetch(page.String())
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	return s.ImportDocument(caller, data, page.String())
+}
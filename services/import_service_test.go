@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// testRecipePage is a recipe page with its JSON-LD in a @graph, next to unrelated markup and scripts
+const testRecipePage = `<!DOCTYPE html>
+<html>
+<head>
+<title>Weeknight Lasagne</title>
+<script type="application/ld+json">{ broken </script>
+<script type="application/ld+json">
+{
+  "@context": "https://schema.org",
+  "@graph": [
+    {"@type": "WebSite", "name": "Good Food"},
+    {
+      "@type": ["Recipe", "NewsArticle"],
+      "name": "Weeknight Lasagne",
+      "description": "A <b>quick</b> lasagne &amp; salad",
+      "author": [{"@type": "Person", "name": "Ada Cook"}],
+      "publisher": {"@type": "Organization", "name": "Good Food"},
+      "prepTime": "PT20M",
+      "totalTime": "PT1H15M",
+      "recipeYield": ["6", "6 portions"],
+      "keywords": "pasta, Italian, pasta",
+      "recipeIngredient": ["500 g beef mince", "12 lasagne sheets", "2 cups milk"],
+      "recipeInstructions": [
+        {"@type": "HowToSection", "name": "Sauce", "itemListElement": [
+          {"@type": "HowToStep", "text": "Brown the beef mince"},
+          {"@type": "HowToStep", "text": "Simmer for 20 minutes"}
+        ]},
+        {"@type": "HowToSection", "name": "Assembly", "itemListElement": [
+          {"@type": "HowToStep", "text": "Layer the sheets and sauce"},
+          {"@type": "HowToStep", "text": "Bake for 35 minutes"}
+        ]}
+      ]
+    }
+  ]
+}
+</script>
+</head>
+<body><h1>Weeknight Lasagne</h1></body>
+</html>`
+
+// TestImportRecipes tests importing recipes from JSON-LD documents, HTML pages and fetched URLs
+func TestImportRecipes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lasagne":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(testRecipePage))
+		case "/about":
+			w.Write([]byte("<html><body>No recipes here</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the services with the repository and a fetcher for the test server
+	recipeService := NewRecipeService(repo)
+	service := NewImportService(recipeService, NewHTTPFetcher(server.Client()))
+
+	caller := Caller{UserID: "author-1"}
+
+	// A fetched page is mapped field by field and credited to its URL
+	recipe, err := service.ImportURL(caller, server.URL+"/lasagne")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if recipe.Title != "Weeknight Lasagne" || recipe.Description != "A quick lasagne & salad" || recipe.AuthorID != caller.UserID {
+		t.Errorf("Expected the lasagne with a plain description, but got %q: %q", recipe.Title, recipe.Description)
+	}
+	if recipe.Status != models.StatusDraft {
+		t.Errorf("Expected an imported recipe to be a draft, but got %s", recipe.Status)
+	}
+	if recipe.PrepTime != 20 || recipe.CookTime != 55 || recipe.Servings != 6 {
+		t.Errorf("Expected 20 + 55 minutes for 6, but got %d + %d for %d", recipe.PrepTime, recipe.CookTime, recipe.Servings)
+	}
+	if len(recipe.Tags) != 2 || recipe.Tags[0] != "pasta" || recipe.Tags[1] != "Italian" {
+		t.Errorf("Expected the distinct keywords as tags, but got %v", recipe.Tags)
+	}
+	if len(recipe.Ingredients) != 3 || recipe.Ingredients[0].Unit != "g" || recipe.Ingredients[0].Name != "beef mince" {
+		t.Errorf("Expected 3 parsed ingredients, but got %v", recipe.Ingredients)
+	}
+	if len(recipe.InstructionSections) != 2 || recipe.InstructionSections[1].Name != "Assembly" || len(recipe.Instructions) != 4 {
+		t.Errorf("Expected the Sauce and Assembly sections of 4 steps, but got %+v", recipe.InstructionSections)
+	}
+	want := models.RecipeSource{URL: server.URL + "/lasagne", Author: "Ada Cook", Publisher: "Good Food"}
+	if recipe.Source == nil || *recipe.Source != want {
+		t.Errorf("Expected the source %+v, but got %+v", want, recipe.Source)
+	}
+
+	// Raw JSON-LD with plain text instructions and day-long durations
+	document := []byte(`{
+		"@context": "https://schema.org",
+		"@type": "Recipe",
+		"name": "Sourdough",
+		"url": "https://example.com/sourdough",
+		"author": "Bea Baker",
+		"prepTime": "P1DT30M",
+		"cookTime": "PT45M",
+		"recipeYield": 2,
+		"keywords": ["bread", "baking"],
+		"recipeIngredient": ["500 g flour", "350 g water"],
+		"recipeInstructions": "Mix and rest overnight\nBake in a hot oven"
+	}`)
+	bread, err := service.ImportDocument(caller, document, "")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if bread.PrepTime != 1470 || bread.CookTime != 45 || bread.Servings != 2 || len(bread.Tags) != 2 || len(bread.Instructions) != 2 {
+		t.Errorf("Expected 1470 + 45 minutes for 2 with 2 tags and steps, but got %+v", bread)
+	}
+	if bread.Source == nil || bread.Source.URL != "https://example.com/sourdough" || bread.Source.Author != "Bea Baker" {
+		t.Errorf("Expected the recipe's own url and author, but got %+v", bread.Source)
+	}
+
+	// Documents without a recipe, unreachable pages and bad URLs are rejected
+	if _, err := service.ImportURL(caller, server.URL+"/about"); !errors.Is(err, ErrNoRecipeFound) {
+		t.Errorf("Expected ErrNoRecipeFound, but got %v", err)
+	}
+	if _, err := service.ImportURL(caller, server.URL+"/missing"); !errors.Is(err, ErrFetchFailed) {
+		t.Errorf("Expected ErrFetchFailed, but got %v", err)
+	}
+	if _, err := service.ImportURL(caller, "file:///etc/passwd"); !errors.Is(err, ErrInvalidImportURL) {
+		t.Errorf("Expected ErrInvalidImportURL, but got %v", err)
+	}
+	if _, err := service.ImportDocument(caller, []byte(`{"@type": "Recipe"}`), ""); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("Expected ErrInvalidImport for a recipe without a name, but got %v", err)
+	}
+
+	// The public fetcher refuses to reach the server's own network
+	if _, err := NewPublicHTTPFetcher().Fetch(server.URL + "/lasagne"); !errors.Is(err, ErrFetchFailed) {
+		t.Errorf("Expected ErrFetchFailed for a loopback address, but got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// normalizeText lowercases text and replaces punctuation with spaces so that
+// ingredient names can be compared word by word
+func normalizeText(text string) string {
+	mapped := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, text)
+	return strings.Join(strings.Fields(mapped), " ")
+}
+
+// containsPhrase reports whether text contains phrase as whole words,
+// so "flour" matches "plain flour" but not "cauliflower"
+func containsPhrase(text, phrase string) bool {
+	phrase = normalizeText(phrase)
+	if phrase == "" {
+		return false
+	}
+	return strings.Contains(" "+normalizeText(text)+" ", " "+phrase+" ")
+}
+
+// sortLongestFirst orders lookup keys so the most specific phrase is tried first,
+// e.g. "brown sugar" before "sugar"
+func sortLongestFirst(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// singularize turns each plural word of normalized text into its singular form,
+// e.g. "cherry tomatoes" into "cherry tomato"; it is only a fallback for lookups
+func singularize(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		switch {
+		case len(word) > 4 && strings.HasSuffix(word, "ies"):
+			words[i] = strings.TrimSuffix(word, "ies") + "y"
+		case len(word) > 3 && strings.HasSuffix(word, "oes"):
+			words[i] = strings.TrimSuffix(word, "es")
+		case len(word) > 2 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+			words[i] = strings.TrimSuffix(word, "s")
+		}
+	}
+	return strings.Join(words, " ")
+}
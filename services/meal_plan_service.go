@@ -0,0 +1,335 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"playground/models"
+	"playground/repositories"
+)
+
+// daysPerWeek is the length of the span copied and repeated by week operations
+const daysPerWeek = 7
+
+// MaxWeekRepeats is how many weeks ahead a week can be repeated at once
+const MaxWeekRepeats = 52
+
+// ErrMealPlanNotFound is returned when a meal plan does not exist or belongs to another user
+var ErrMealPlanNotFound = errors.New("meal plan not found")
+
+// ErrMealPlanEntryNotFound is returned when a meal plan has no entry with the given ID
+var ErrMealPlanEntryNotFound = errors.New("meal plan entry not found")
+
+// ErrInvalidMealPlan is returned when a meal plan has no name
+var ErrInvalidMealPlan = errors.New("meal plan needs a name")
+
+// ErrInvalidMealPlanEntry is returned when an entry lacks a date or recipe, has an unknown slot or negative servings
+var ErrInvalidMealPlanEntry = errors.New("meal plan entry needs a date, a recipe, a slot of breakfast, lunch, dinner or snack, and servings of zero or more")
+
+// ErrInvalidWeekCopy is returned when a week would be copied onto itself or repeated an invalid number of times
+var ErrInvalidWeekCopy = errors.New("weeks must not overlap and can be repeated 1 to 52 times")
+
+// MealPlanService handles business logic for meal plans
+type MealPlanService struct {
+	repository    repositories.MealPlanRepository
+	recipeService *RecipeService
+	// mutex serializes changes to entry lists, which are read, changed and written back
+	mutex sync.Mutex
+}
+
+// NewMealPlanService creates a new meal plan service with the given repository
+func NewMealPlanService(repository repositories.MealPlanRepository, recipeService *RecipeService) *MealPlanService {
+	service := &MealPlanService{
+		repository:    repository,
+		recipeService: recipeService,
+	}
+
+	// Meals of recipes in the trash stay planned, and show as unavailable, until the recipe is purged
+	recipeService.On(RecipePurged, func(recipe models.Recipe) {
+		repository.RemoveRecipe(recipe.ID)
+	})
+
+	return service
+}
+
+// GetMealPlans returns the caller's meal plans, oldest first
+func (s *MealPlanService) GetMealPlans(caller Caller) []models.MealPlan {
+	plans := s.repository.FindByOwnerID(caller.UserID)
+	Sort(plans, func(i, j int) bool {
+		return plans[i].CreatedAt.Before(plans[j].CreatedAt)
+	})
+	return plans
+}
+
+// GetMealPlan returns a meal plan the caller owns with its entries between from and to, inclusive.
+// A zero from or to leaves that end of the range open.
+func (s *MealPlanService) GetMealPlan(id string, caller Caller, from, to models.Date) (models.MealPlan, error) {
+	plan, err := s.ownedMealPlan(id, caller)
+	if err != nil {
+		return models.MealPlan{}, err
+	}
+
+	plan.Entries = Filter(plan.Entries, func(entry models.MealPlanEntry) bool {
+		return inRange(entry.Date, from, to)
+	})
+	return plan, nil
+}
+
+// CreateMealPlan adds a new empty meal plan owned by the caller
+func (s *MealPlanService) CreateMealPlan(caller Caller, input models.MealPlanInput) (models.MealPlan, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return models.MealPlan{}, ErrInvalidMealPlan
+	}
+	return s.repository.Create(caller.UserID, input), nil
+}
+
+// UpdateMealPlan renames a meal plan the caller owns
+func (s *MealPlanService) UpdateMealPlan(id string, caller Caller, input models.MealPlanInput) (models.MealPlan, error) {
+	if _, err := s.ownedMealPlan(id, caller); err != nil {
+		return models.MealPlan{}, err
+	}
+	if strings.TrimSpace(input.Name) == "" {
+		return models.MealPlan{}, ErrInvalidMealPlan
+	}
+	return s.repository.Update(id, input)
+}
+
+// DeleteMealPlan removes a meal plan the caller owns
+func (s *MealPlanService) DeleteMealPlan(id string, caller Caller) error {
+	if _, err := s.ownedMealPlan(id, caller); err != nil {
+		return err
+	}
+	return s.repository.Delete(id)
+}
+
+// AddEntry plans a recipe the caller may see for a meal in a meal plan the caller owns
+func (s *MealPlanService) AddEntry(id string, caller Caller, input models.MealPlanEntryInput) (models.MealPlanEntry, error) {
+	if err := s.validateEntry(input, caller); err != nil {
+		return models.MealPlanEntry{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	plan, err := s.ownedMealPlan(id, caller)
+	if err != nil {
+		return models.MealPlanEntry{}, err
+	}
+
+	entry := newEntry(input)
+	if _, err := s.repository.SetEntries(id, sortEntries(append(copyEntries(plan.Entries), entry))); err != nil {
+		return models.MealPlanEntry{}, err
+	}
+	return entry, nil
+}
+
+// UpdateEntry changes the day, meal, recipe or servings of an entry in a meal plan the caller owns
+func (s *MealPlanService) UpdateEntry(id string, entryID string, caller Caller, input models.MealPlanEntryInput) (models.MealPlanEntry, error) {
+	if err := s.validateEntry(input, caller); err != nil {
+		return models.MealPlanEntry{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	plan, err := s.ownedMealPlan(id, caller)
+	if err != nil {
+		return models.MealPlanEntry{}, err
+	}
+
+	entries := copyEntries(plan.Entries)
+	for i, entry := range entries {
+		if entry.ID != entryID {
+			continue
+		}
+
+		updated := newEntry(input)
+		updated.ID = entry.ID
+		entries[i] = updated
+		if _, err := s.repository.SetEntries(id, sortEntries(entries)); err != nil {
+			return models.MealPlanEntry{}, err
+		}
+		return updated, nil
+	}
+	return models.MealPlanEntry{}, ErrMealPlanEntryNotFound
+}
+
+// DeleteEntry removes an entry from a meal plan the caller owns
+func (s *MealPlanService) DeleteEntry(id string, entryID string, caller Caller) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	plan, err := s.ownedMealPlan(id, caller)
+	if err != nil {
+		return err
+	}
+
+	entries := Filter(plan.Entries, func(entry models.MealPlanEntry) bool {
+		return entry.ID != entryID
+	})
+	if len(entries) == len(plan.Entries) {
+		return ErrMealPlanEntryNotFound
+	}
+	_, err = s.repository.SetEntries(id, entries)
+	return err
+}
+
+// CopyWeek copies the meals of the seven days starting at input.From to the seven days starting
+// at input.To, adding to the meals already planned there unless input.Replace is set
+func (s *MealPlanService) CopyWeek(id string, caller Caller, input models.CopyWeekInput) (models.MealPlan, error) {
+	if input.From.IsZero() || input.To.IsZero() || abs(input.To.DaysSince(input.From)) < daysPerWeek {
+		return models.MealPlan{}, ErrInvalidWeekCopy
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	plan, err := s.ownedMealPlan(id, caller)
+	if err != nil {
+		return models.MealPlan{}, err
+	}
+
+	entries := copyWeek(plan.Entries, input.From, []models.Date{input.To}, input.Replace)
+	return s.repository.SetEntries(id, entries)
+}
+
+// RepeatWeek copies the meals of the seven days starting at input.Week into each of the
+// input.Times weeks that follow it
+func (s *MealPlanService) RepeatWeek(id string, caller Caller, input models.RepeatWeekInput) (models.MealPlan, error) {
+	if input.Week.IsZero() || input.Times < 1 || input.Times > MaxWeekRepeats {
+		return models.MealPlan{}, ErrInvalidWeekCopy
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	plan, err := s.ownedMealPlan(id, caller)
+	if err != nil {
+		return models.MealPlan{}, err
+	}
+
+	targets := make([]models.Date, 0, input.Times)
+	for i := 1; i <= input.Times; i++ {
+		targets = append(targets, input.Week.AddDays(i*daysPerWeek))
+	}
+	entries := copyWeek(plan.Entries, input.Week, targets, input.Replace)
+	return s.repository.SetEntries(id, entries)
+}
+
+// GetSummary adds up the prep and cook time of the meals planned on each day between from and to,
+// inclusive, for a meal plan the caller owns. Only days with meals are listed.
+func (s *MealPlanService) GetSummary(id string, caller Caller, from, to models.Date) ([]models.DaySummary, error) {
+	plan, err := s.GetMealPlan(id, caller, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]models.DaySummary, 0)
+	for _, entry := range plan.Entries {
+		if len(summaries) == 0 || !summaries[len(summaries)-1].Date.Equal(entry.Date.Time) {
+			summaries = append(summaries, models.DaySummary{Date: entry.Date})
+		}
+		day := &summaries[len(summaries)-1]
+		day.Meals++
+
+		recipe, err := s.recipeService.GetVisibleRecipe(entry.RecipeID, caller)
+		if err != nil {
+			day.Unavailable++
+			continue
+		}
+		day.PrepTime += recipe.PrepTime
+		day.CookTime += recipe.CookTime
+		day.TotalTime = day.PrepTime + day.CookTime
+	}
+	return summaries, nil
+}
+
+// ownedMealPlan returns a meal plan if the caller owns it or is an admin. Meal plans are private,
+// so those of other users are reported as missing rather than forbidden.
+func (s *MealPlanService) ownedMealPlan(id string, caller Caller) (models.MealPlan, error) {
+	plan, err := s.repository.FindByID(id)
+	if err != nil || !caller.CanModify(plan.OwnerID) {
+		return models.MealPlan{}, ErrMealPlanNotFound
+	}
+	return plan, nil
+}
+
+// validateEntry checks an entry and that the caller may see its recipe
+func (s *MealPlanService) validateEntry(input models.MealPlanEntryInput, caller Caller) error {
+	if input.Date.IsZero() || input.RecipeID == "" || !input.Slot.IsValid() || input.Servings < 0 {
+		return ErrInvalidMealPlanEntry
+	}
+	_, err := s.recipeService.GetVisibleRecipe(input.RecipeID, caller)
+	return err
+}
+
+// newEntry creates an entry with a generated ID from input
+func newEntry(input models.MealPlanEntryInput) models.MealPlanEntry {
+	return models.MealPlanEntry{
+		ID:       uuid.New().String(),
+		Date:     input.Date,
+		Slot:     input.Slot,
+		RecipeID: input.RecipeID,
+		Servings: input.Servings,
+	}
+}
+
+// copyWeek returns entries with the week starting at from copied to each week starting at a target.
+// With replace, the meals planned in the target weeks are dropped first.
+func copyWeek(entries []models.MealPlanEntry, from models.Date, targets []models.Date, replace bool) []models.MealPlanEntry {
+	week := Filter(entries, func(entry models.MealPlanEntry) bool {
+		return inRange(entry.Date, from, from.AddDays(daysPerWeek-1))
+	})
+
+	result := copyEntries(entries)
+	if replace {
+		result = Filter(result, func(entry models.MealPlanEntry) bool {
+			for _, target := range targets {
+				if inRange(entry.Date, target, target.AddDays(daysPerWeek-1)) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	for _, target := range targets {
+		offset := target.DaysSince(from)
+		for _, entry := range week {
+			copied := entry
+			copied.ID = uuid.New().String()
+			copied.Date = entry.Date.AddDays(offset)
+			result = append(result, copied)
+		}
+	}
+	return sortEntries(result)
+}
+
+// copyEntries returns a copy of entries that can be changed without touching the stored plan
+func copyEntries(entries []models.MealPlanEntry) []models.MealPlanEntry {
+	return append(make([]models.MealPlanEntry, 0, len(entries)+1), entries...)
+}
+
+// sortEntries orders entries by date and meal slot, keeping meals in the same slot in the order they were added
+func sortEntries(entries []models.MealPlanEntry) []models.MealPlanEntry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Before(entries[j])
+	})
+	return entries
+}
+
+// inRange reports whether date is between from and to, inclusive; zero bounds are open
+func inRange(date, from, to models.Date) bool {
+	return (from.IsZero() || !date.Before(from.Time)) && (to.IsZero() || !date.After(to.Time))
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
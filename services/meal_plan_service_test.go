@@ -0,0 +1,195 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestMealPlans tests planning meals, copying and repeating weeks, daily summaries and cleanup of purged recipes
+func TestMealPlans(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	mealPlanRepo := repositories.NewInMemoryMealPlanRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	service := NewMealPlanService(mealPlanRepo, recipeService)
+
+	author := Caller{UserID: "author-1"}
+	alice := Caller{UserID: "alice"}
+	bob := Caller{UserID: "bob"}
+
+	oats := createTimedRecipe(t, recipeService, author.UserID, "Oats", 5, 10)
+	stew := createTimedRecipe(t, recipeService, author.UserID, "Stew", 20, 90)
+	draft, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{Title: "Draft"})
+
+	if _, err := service.CreateMealPlan(alice, models.MealPlanInput{Name: "  "}); !errors.Is(err, ErrInvalidMealPlan) {
+		t.Errorf("Expected ErrInvalidMealPlan for a blank name, but got %v", err)
+	}
+	plan, err := service.CreateMealPlan(alice, models.MealPlanInput{Name: "Family"})
+	if err != nil {
+		t.Fatalf("Expected to create a meal plan, but got error: %v", err)
+	}
+
+	monday := date(t, "2024-03-04")
+	add := func(day models.Date, slot models.MealSlot, recipeID string) models.MealPlanEntry {
+		t.Helper()
+		entry, err := service.AddEntry(plan.ID, alice, models.MealPlanEntryInput{Date: day, Slot: slot, RecipeID: recipeID})
+		if err != nil {
+			t.Fatalf("Expected to plan %s on %s, but got error: %v", slot, day, err)
+		}
+		return entry
+	}
+
+	// Entries are kept in date and meal order regardless of the order they were added in
+	add(monday.AddDays(1), models.SlotDinner, stew.ID)
+	add(monday, models.SlotDinner, stew.ID)
+	breakfast := add(monday, models.SlotBreakfast, oats.ID)
+
+	invalid := []models.MealPlanEntryInput{
+		{Slot: models.SlotLunch, RecipeID: oats.ID},
+		{Date: monday, Slot: "brunch", RecipeID: oats.ID},
+		{Date: monday, Slot: models.SlotLunch, RecipeID: oats.ID, Servings: -1},
+	}
+	for _, input := range invalid {
+		if _, err := service.AddEntry(plan.ID, alice, input); !errors.Is(err, ErrInvalidMealPlanEntry) {
+			t.Errorf("Expected ErrInvalidMealPlanEntry for %+v, but got %v", input, err)
+		}
+	}
+	if _, err := service.AddEntry(plan.ID, alice, models.MealPlanEntryInput{Date: monday, Slot: models.SlotLunch, RecipeID: draft.ID}); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound for someone else's draft, but got %v", err)
+	}
+
+	// Meal plans are private to their owner
+	if _, err := service.GetMealPlan(plan.ID, bob, models.Date{}, models.Date{}); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound for another user, but got %v", err)
+	}
+	if _, err := service.AddEntry(plan.ID, bob, models.MealPlanEntryInput{Date: monday, Slot: models.SlotLunch, RecipeID: oats.ID}); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound when another user adds a meal, but got %v", err)
+	}
+
+	plan, _ = service.GetMealPlan(plan.ID, alice, models.Date{}, models.Date{})
+	if got := describeEntries(plan.Entries); got != "2024-03-04 breakfast,2024-03-04 dinner,2024-03-05 dinner" {
+		t.Errorf("Expected entries in date and meal order, but got %s", got)
+	}
+
+	updated, err := service.UpdateEntry(plan.ID, breakfast.ID, alice, models.MealPlanEntryInput{Date: monday, Slot: models.SlotBreakfast, RecipeID: oats.ID, Servings: 2})
+	if err != nil || updated.ID != breakfast.ID || updated.Servings != 2 {
+		t.Errorf("Expected the breakfast to be updated to 2 servings, but got %+v, %v", updated, err)
+	}
+	if err := service.DeleteEntry(plan.ID, "missing", alice); !errors.Is(err, ErrMealPlanEntryNotFound) {
+		t.Errorf("Expected ErrMealPlanEntryNotFound, but got %v", err)
+	}
+
+	// Copying a week adds to the target week unless replace is set
+	nextMonday := monday.AddDays(7)
+	add(nextMonday, models.SlotLunch, oats.ID)
+	if _, err := service.CopyWeek(plan.ID, alice, models.CopyWeekInput{From: monday, To: monday.AddDays(3)}); !errors.Is(err, ErrInvalidWeekCopy) {
+		t.Errorf("Expected ErrInvalidWeekCopy for overlapping weeks, but got %v", err)
+	}
+	plan, err = service.CopyWeek(plan.ID, alice, models.CopyWeekInput{From: monday, To: nextMonday})
+	if err != nil {
+		t.Fatalf("Expected to copy the week, but got error: %v", err)
+	}
+	if len(plan.Entries) != 7 {
+		t.Errorf("Expected 7 entries after copying, but got %s", describeEntries(plan.Entries))
+	}
+	if plan.Entries[3].Servings != 2 || plan.Entries[3].ID == breakfast.ID {
+		t.Errorf("Expected the copied breakfast to keep its servings with a new ID, but got %+v", plan.Entries[3])
+	}
+
+	plan, _ = service.CopyWeek(plan.ID, alice, models.CopyWeekInput{From: monday, To: nextMonday, Replace: true})
+	if got := describeEntries(plan.Entries[3:]); got != "2024-03-11 breakfast,2024-03-11 dinner,2024-03-12 dinner" {
+		t.Errorf("Expected the next week to be replaced, but got %s", got)
+	}
+
+	if _, err := service.RepeatWeek(plan.ID, alice, models.RepeatWeekInput{Week: monday, Times: MaxWeekRepeats + 1}); !errors.Is(err, ErrInvalidWeekCopy) {
+		t.Errorf("Expected ErrInvalidWeekCopy for too many repeats, but got %v", err)
+	}
+	plan, _ = service.RepeatWeek(plan.ID, alice, models.RepeatWeekInput{Week: monday, Times: 3, Replace: true})
+	if len(plan.Entries) != 12 || plan.Entries[11].Date.String() != "2024-03-26" {
+		t.Errorf("Expected 4 weeks of 3 meals, but got %s", describeEntries(plan.Entries))
+	}
+
+	// Reading a range only returns the meals between from and to, inclusive
+	week, _ := service.GetMealPlan(plan.ID, alice, nextMonday, nextMonday.AddDays(6))
+	if len(week.Entries) != 3 {
+		t.Errorf("Expected 3 entries in the second week, but got %s", describeEntries(week.Entries))
+	}
+
+	summary, err := service.GetSummary(plan.ID, alice, monday, monday.AddDays(6))
+	if err != nil {
+		t.Fatalf("Expected a summary, but got error: %v", err)
+	}
+	if len(summary) != 2 {
+		t.Fatalf("Expected 2 days with meals, but got %+v", summary)
+	}
+	if day := summary[0]; day.Meals != 2 || day.PrepTime != 25 || day.CookTime != 100 || day.TotalTime != 125 {
+		t.Errorf("Expected Monday to take 25 + 100 minutes, but got %+v", day)
+	}
+
+	// Recipes in the trash stay planned but no longer count towards the summary until they are purged
+	recipeService.DeleteRecipe(stew.ID, author)
+	summary, _ = service.GetSummary(plan.ID, alice, monday, monday)
+	if day := summary[0]; day.Meals != 2 || day.Unavailable != 1 || day.TotalTime != 15 {
+		t.Errorf("Expected the trashed stew to be unavailable, but got %+v", day)
+	}
+
+	recipeService.PurgeDeletedRecipes(time.Now().Add(time.Minute))
+	plan, _ = service.GetMealPlan(plan.ID, alice, models.Date{}, models.Date{})
+	if len(plan.Entries) != 4 {
+		t.Errorf("Expected only the breakfasts to remain after the purge, but got %s", describeEntries(plan.Entries))
+	}
+
+	if plans := service.GetMealPlans(bob); len(plans) != 0 {
+		t.Errorf("Expected bob to have no meal plans, but got %d", len(plans))
+	}
+	if err := service.DeleteMealPlan(plan.ID, alice); err != nil {
+		t.Errorf("Expected to delete the meal plan, but got %v", err)
+	}
+}
+
+// Helper function to create a published recipe with the given prep and cook time
+func createTimedRecipe(t *testing.T, service *RecipeService, authorID string, title string, prepTime, cookTime int) models.Recipe {
+	t.Helper()
+
+	recipe := createPublishedRecipe(t, service, authorID, title)
+	updated, err := service.UpdateRecipe(recipe.ID, Caller{UserID: authorID}, models.RecipeInput{
+		Title:        title,
+		Ingredients:  recipe.Ingredients,
+		Instructions: []string{"Cook it"},
+		PrepTime:     prepTime,
+		CookTime:     cookTime,
+	})
+	if err != nil {
+		t.Fatalf("Expected to update '%s', but got error: %v", title, err)
+	}
+	return updated
+}
+
+// Helper function to parse a date or fail the test
+func date(t *testing.T, value string) models.Date {
+	t.Helper()
+
+	d, err := models.ParseDate(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// Helper function to describe entries as comma separated "date slot" pairs
+func describeEntries(entries []models.MealPlanEntry) string {
+	result := ""
+	for i, entry := range entries {
+		if i > 0 {
+			result += ","
+		}
+		result += entry.Date.String() + " " + string(entry.Slot)
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// MaxNoteLength is the longest recipe note, in characters
+const MaxNoteLength = 2000
+
+// ErrInvalidNote is returned when a note is empty or too long
+var ErrInvalidNote = errors.New("note must have between 1 and 2000 characters")
+
+// ErrNoteNotFound is returned when the caller has no note on a recipe
+var ErrNoteNotFound = errors.New("note not found")
+
+// NoteService handles business logic for private recipe notes
+type NoteService struct {
+	repository    repositories.NoteRepository
+	recipeService *RecipeService
+}
+
+// NewNoteService creates a new note service with the given repository
+func NewNoteService(repository repositories.NoteRepository, recipeService *RecipeService) *NoteService {
+	service := &NoteService{
+		repository:    repository,
+		recipeService: recipeService,
+	}
+
+	// Notes on recipes in the trash are kept until the recipe is purged
+	recipeService.On(RecipePurged, func(recipe models.Recipe) {
+		repository.DeleteByRecipeID(recipe.ID)
+	})
+
+	return service
+}
+
+// GetNote returns the caller's note on a recipe; notes are never shown to anyone else
+func (s *NoteService) GetNote(recipeID string, caller Caller) (models.RecipeNote, error) {
+	if caller.UserID == "" {
+		return models.RecipeNote{}, ErrNoteNotFound
+	}
+
+	note, err := s.repository.Find(caller.UserID, recipeID)
+	if err != nil {
+		return models.RecipeNote{}, ErrNoteNotFound
+	}
+	return note, nil
+}
+
+// SaveNote creates or replaces the caller's note on a recipe the caller may see
+func (s *NoteService) SaveNote(recipeID string, caller Caller, input models.RecipeNoteInput) (models.RecipeNote, error) {
+	input.Text = strings.TrimSpace(input.Text)
+	if input.Text == "" || utf8.RuneCountInString(input.Text) > MaxNoteLength {
+		return models.RecipeNote{}, ErrInvalidNote
+	}
+
+	if _, err := s.recipeService.GetVisibleRecipe(recipeID, caller); err != nil {
+		return models.RecipeNote{}, err
+	}
+	return s.repository.Save(caller.UserID, recipeID, input), nil
+}
+
+// DeleteNote removes the caller's note on a recipe
+func (s *NoteService) DeleteNote(recipeID string, caller Caller) error {
+	if err := s.repository.Delete(caller.UserID, recipeID); err != nil {
+		return ErrNoteNotFound
+	}
+	return nil
+}
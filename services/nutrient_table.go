@@ -0,0 +1,102 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"playground/models"
+)
+
+// bundledNutrients is a small USDA-derived nutrient table with values per 100 grams
+//
+//go:embed data/nutrients.csv
+var bundledNutrients []byte
+
+// ErrInvalidNutrientTable is returned when a nutrient table CSV cannot be read
+var ErrInvalidNutrientTable = errors.New("invalid nutrient table")
+
+// nutrientColumns maps the CSV header names to the nutrient they fill in
+var nutrientColumns = map[string]func(food *models.Food, value float64){
+	"calories":      func(food *models.Food, value float64) { food.Per100g.Calories = value },
+	"protein":       func(food *models.Food, value float64) { food.Per100g.Protein = value },
+	"fat":           func(food *models.Food, value float64) { food.Per100g.Fat = value },
+	"carbohydrates": func(food *models.Food, value float64) { food.Per100g.Carbohydrates = value },
+	"fiber":         func(food *models.Food, value float64) { food.Per100g.Fiber = value },
+	"sodium":        func(food *models.Food, value float64) { food.Per100g.Sodium = value },
+	"piece_grams":   func(food *models.Food, value float64) { food.PieceGrams = value },
+	"density":       func(food *models.Food, value float64) { food.Density = value },
+}
+
+// BundledNutrientTable returns the foods of the nutrient table shipped with the application
+func BundledNutrientTable() []models.Food {
+	foods, err := LoadNutrientTable(bytes.NewReader(bundledNutrients))
+	if err != nil {
+		panic(fmt.Sprintf("bundled nutrient table: %v", err))
+	}
+	return foods
+}
+
+// LoadNutrientTable reads foods from a CSV file with a header row. The name and calories columns
+// are required; protein, fat, carbohydrates, fiber, sodium, piece_grams and density are optional
+// and may be left empty. Nutrients are per 100 grams, sodium in milligrams.
+func LoadNutrientTable(r io.Reader) ([]models.Food, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header: %v", ErrInvalidNutrientTable, err)
+	}
+
+	nameColumn := -1
+	hasCalories := false
+	columns := make(map[int]string, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "name" {
+			nameColumn = i
+		} else if _, known := nutrientColumns[name]; known {
+			columns[i] = name
+			hasCalories = hasCalories || name == "calories"
+		}
+	}
+	if nameColumn < 0 || !hasCalories {
+		return nil, fmt.Errorf("%w: the name and calories columns are required", ErrInvalidNutrientTable)
+	}
+
+	foods := make([]models.Food, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidNutrientTable, err)
+		}
+
+		food := models.Food{Name: strings.TrimSpace(record[nameColumn])}
+		if food.Name == "" {
+			continue
+		}
+		for i, column := range columns {
+			text := strings.TrimSpace(record[i])
+			if text == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil || value < 0 {
+				return nil, fmt.Errorf("%w: %s of %q is not a valid amount", ErrInvalidNutrientTable, column, food.Name)
+			}
+			nutrientColumns[column](&food, value)
+		}
+		foods = append(foods, food)
+	}
+
+	return foods, nil
+}
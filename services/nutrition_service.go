@@ -0,0 +1,172 @@
+package services
+
+import (
+	"playground/models"
+)
+
+// SortByCalories sorts recipes by calories per serving
+const SortByCalories SortBy = "calories"
+
+// countUnits are units that count pieces of an ingredient rather than measure it
+var countUnits = []string{"", "piece", "clove", "slice"}
+
+// NutritionService computes recipe nutrition from a nutrient table
+type NutritionService struct {
+	recipeService     *RecipeService
+	conversionService *ConversionService
+	foods             map[string]models.Food
+	// foodKeys are the nutrient table keys, longest first so "brown sugar" wins over "sugar"
+	foodKeys []string
+}
+
+// NewNutritionService creates a new nutrition service with the given nutrient table
+func NewNutritionService(foods []models.Food, recipeService *RecipeService, conversionService *ConversionService) *NutritionService {
+	table := make(map[string]models.Food, len(foods))
+	keys := make([]string, 0, len(foods))
+	for _, food := range foods {
+		key := normalizeText(food.Name)
+		if _, exists := table[key]; !exists {
+			keys = append(keys, key)
+		}
+		table[key] = food
+	}
+	sortLongestFirst(keys)
+
+	service := &NutritionService{
+		recipeService:     recipeService,
+		conversionService: conversionService,
+		foods:             table,
+		foodKeys:          keys,
+	}
+
+	recipeService.RegisterSortKey(SortByCalories, service.CaloriesPerServing)
+
+	return service
+}
+
+// GetRecipeNutrition returns the nutrition of a recipe the caller may see
+func (s *NutritionService) GetRecipeNutrition(id string, caller Caller) (models.NutritionFacts, error) {
+	recipe, err := s.recipeService.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.NutritionFacts{}, err
+	}
+	return s.CalculateNutrition(recipe, caller), nil
+}
+
+// CalculateNutrition adds up the nutrients of every ingredient that can be matched against the
+// nutrient table and weighed, with the sub-recipes the caller may see expanded into their own
+// ingredients. Per-serving values are only given when the recipe has servings.
+func (s *NutritionService) CalculateNutrition(recipe models.Recipe, caller Caller) models.NutritionFacts {
+	facts := models.NutritionFacts{
+		RecipeID:  recipe.ID,
+		Servings:  recipe.Servings,
+		Unmatched: make([]models.UnmatchedIngredient, 0),
+	}
+
+	ingredients := s.recipeService.ExpandIngredients(recipe, caller)
+	var total models.Nutrients
+	matched := 0
+	for _, ingredient := range ingredients {
+		nutrients, reason := s.ingredientNutrients(ingredient)
+		if reason != "" {
+			facts.Unmatched = append(facts.Unmatched, models.UnmatchedIngredient{
+				Ingredient: ingredient.String(),
+				Reason:     reason,
+			})
+			continue
+		}
+		total = total.Add(nutrients)
+		matched++
+	}
+
+	facts.Total = total.Round()
+	if recipe.Servings > 0 {
+		perServing := total.Scale(1 / float64(recipe.Servings)).Round()
+		facts.PerServing = &perServing
+	}
+	if len(ingredients) > 0 {
+		facts.Coverage = float64(matched) / float64(len(ingredients))
+	}
+	return facts
+}
+
+// CaloriesPerServing returns the calories in one serving of a recipe; false means the recipe has
+// no servings or none of its ingredients could be matched
+func (s *NutritionService) CaloriesPerServing(recipe models.Recipe, caller Caller) (float64, bool) {
+	facts := s.CalculateNutrition(recipe, caller)
+	if facts.PerServing == nil || facts.Coverage == 0 {
+		return 0, false
+	}
+	return facts.PerServing.Calories, true
+}
+
+// FindFood returns the nutrient table entry that best matches an ingredient name,
+// trying the singular form when the name as written has no match
+func (s *NutritionService) FindFood(ingredient string) (models.Food, bool) {
+	name := normalizeText(ingredient)
+	for _, candidate := range []string{name, singularize(name)} {
+		if food, ok := s.foods[candidate]; ok {
+			return food, true
+		}
+		for _, key := range s.foodKeys {
+			if containsPhrase(candidate, key) {
+				return s.foods[key], true
+			}
+		}
+	}
+	return models.Food{}, false
+}
+
+// ingredientNutrients returns the nutrients in an ingredient, or the reason they cannot be computed
+func (s *NutritionService) ingredientNutrients(ingredient models.Ingredient) (models.Nutrients, string) {
+	// Sub-recipes that are still here after expansion could not be opened
+	if ingredient.IsSubRecipe() {
+		return models.Nutrients{}, "sub-recipe unavailable"
+	}
+	if ingredient.Quantity == nil {
+		return models.Nutrients{}, "no quantity"
+	}
+
+	food, ok := s.FindFood(ingredient.Name)
+	if !ok {
+		return models.Nutrients{}, "not in nutrient table"
+	}
+
+	grams, reason := s.ingredientGrams(ingredient, food)
+	if reason != "" {
+		return models.Nutrients{}, reason
+	}
+	return food.Per100g.Scale(grams / 100), ""
+}
+
+// ingredientGrams returns the weight of an ingredient in grams, or the reason it cannot be weighed
+func (s *NutritionService) ingredientGrams(ingredient models.Ingredient, food models.Food) (float64, string) {
+	amount := ingredient.Quantity.Float64()
+
+	if Contains(countUnits, ingredient.Unit) {
+		if food.PieceGrams == 0 {
+			return 0, "no weight known per piece"
+		}
+		return amount * food.PieceGrams, ""
+	}
+
+	dimension, ok := s.conversionService.UnitDimension(ingredient.Unit)
+	switch {
+	case !ok || dimension == Temperature:
+		return 0, "unit cannot be weighed"
+	case dimension == Weight:
+		grams, _ := s.conversionService.Convert(amount, ingredient.Unit, models.UnitGram)
+		return grams, ""
+	}
+
+	// The nutrient table's own density wins over the conversion service's
+	if food.Density > 0 {
+		milliliters, _ := s.conversionService.Convert(amount, ingredient.Unit, models.UnitMilliliter)
+		return milliliters * food.Density, ""
+	}
+	grams, err := s.conversionService.ConvertIngredientAmount(amount, ingredient.Unit, models.UnitGram, ingredient.Name)
+	if err != nil {
+		return 0, "no density known"
+	}
+	return grams, ""
+}
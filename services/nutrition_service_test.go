@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// testNutrientTable is a small nutrient table with values per 100 grams
+const testNutrientTable = `name,calories,protein,fat,carbohydrates,fiber,sodium,piece_grams,density
+egg,143,12.6,9.5,0.7,0,142,50,
+sugar,387,0,0,100,0,1,,
+olive oil,884,0,100,0,0,2,,
+salt,0,0,0,0,0,38758,,
+`
+
+// TestLoadNutrientTable tests reading foods from CSV
+func TestLoadNutrientTable(t *testing.T) {
+	foods, err := LoadNutrientTable(strings.NewReader(testNutrientTable))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(foods) != 4 {
+		t.Fatalf("Expected 4 foods, but got %d", len(foods))
+	}
+	if foods[0].Name != "egg" || foods[0].PieceGrams != 50 || foods[0].Per100g.Protein != 12.6 {
+		t.Errorf("Expected egg with a 50 g piece weight, but got %+v", foods[0])
+	}
+
+	// The bundled table must always load
+	if bundled := BundledNutrientTable(); len(bundled) == 0 {
+		t.Error("Expected the bundled nutrient table to contain foods")
+	}
+
+	invalid := []string{
+		"name,protein\negg,12.6\n",
+		"name,calories\negg,lots\n",
+		"",
+	}
+	for _, table := range invalid {
+		if _, err := LoadNutrientTable(strings.NewReader(table)); !errors.Is(err, ErrInvalidNutrientTable) {
+			t.Errorf("Expected ErrInvalidNutrientTable for %q, but got %v", table, err)
+		}
+	}
+}
+
+// TestCalculateNutrition tests nutrition totals, coverage and sorting and searching by calories
+func TestCalculateNutrition(t *testing.T) {
+	foods, err := LoadNutrientTable(strings.NewReader(testNutrientTable))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the services with the repository
+	recipeService := NewRecipeService(repo)
+	service := NewNutritionService(foods, recipeService, NewConversionService())
+	searchService := NewSearchService(recipeService, service, nil)
+
+	author := Caller{UserID: "author-1"}
+	custard, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:    "Custard",
+		Servings: 2,
+		Ingredients: models.ParseIngredients([]string{
+			"2 eggs",
+			"100 g sugar",
+			"1 tbsp olive oil",
+			"1 pinch salt",
+			"1 dragonfruit",
+		}),
+	})
+	light, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:       "Boiled Egg",
+		Servings:    1,
+		Ingredients: models.ParseIngredients([]string{"1 egg"}),
+	})
+	recipeService.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:       "Sugar Syrup",
+		Ingredients: models.ParseIngredients([]string{"200 g sugar"}),
+	})
+
+	facts, err := service.GetRecipeNutrition(custard.ID, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// 100 g egg + 100 g sugar + 13.6 g olive oil
+	if facts.Total.Calories != 650.3 {
+		t.Errorf("Expected 650.3 calories, but got %v", facts.Total.Calories)
+	}
+	if facts.Total.Fat != 23.1 {
+		t.Errorf("Expected 23.1 g fat, but got %v", facts.Total.Fat)
+	}
+	if facts.PerServing == nil || facts.PerServing.Calories != 325.1 {
+		t.Errorf("Expected 325.1 calories per serving, but got %+v", facts.PerServing)
+	}
+	if math.Abs(facts.Coverage-0.6) > 1e-9 {
+		t.Errorf("Expected coverage of 0.6, but got %v", facts.Coverage)
+	}
+	if len(facts.Unmatched) != 2 {
+		t.Errorf("Expected 2 unmatched ingredients, but got %+v", facts.Unmatched)
+	}
+
+	// Recipes without servings sort last
+	sorted := recipeService.SortRecipes(SortByCalories, true, author)
+	if len(sorted) != 3 || sorted[0].ID != light.ID || sorted[1].ID != custard.ID {
+		t.Errorf("Expected Boiled Egg, Custard, Sugar Syrup, but got %v", titles(sorted))
+	}
+	sorted = recipeService.SortRecipes(SortByCalories, false, author)
+	if len(sorted) != 3 || sorted[0].ID != custard.ID || sorted[2].Title != "Sugar Syrup" {
+		t.Errorf("Expected Custard, Boiled Egg, Sugar Syrup, but got %v", titles(sorted))
+	}
+
+	results := searchService.SearchByCalories(0, 100, author)
+	if len(results) != 1 || results[0].ID != light.ID {
+		t.Errorf("Expected only Boiled Egg under 100 calories, but got %v", titles(results))
+	}
+}
+
+// titles returns the titles of recipes for test failure messages
+func titles(recipes []models.Recipe) []string {
+	result := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		result = append(result, recipe.Title)
+	}
+	return result
+}
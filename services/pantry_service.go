@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// ExpiryWindowDays is how many days ahead a pantry item counts as nearing expiry
+const ExpiryWindowDays = 3
+
+// ExpiryBoost is added to the score of a pantry search result for each item nearing expiry it uses up
+const ExpiryBoost = 0.1
+
+// ErrPantryItemNotFound is returned when a pantry item does not exist or belongs to another user
+var ErrPantryItemNotFound = errors.New("pantry item not found")
+
+// ErrInvalidPantryItem is returned when a pantry item has no name or a negative quantity
+var ErrInvalidPantryItem = errors.New("pantry item needs a name and a quantity of zero or more")
+
+// PantryService handles business logic for the ingredients users have at home
+type PantryService struct {
+	repository        repositories.PantryRepository
+	conversionService *ConversionService
+}
+
+// NewPantryService creates a new pantry service with the given repository
+func NewPantryService(repository repositories.PantryRepository, conversionService *ConversionService) *PantryService {
+	return &PantryService{
+		repository:        repository,
+		conversionService: conversionService,
+	}
+}
+
+// GetPantry returns the caller's pantry items, those expiring soonest first
+func (s *PantryService) GetPantry(caller Caller) []models.PantryItem {
+	items := s.repository.FindByOwnerID(caller.UserID)
+	Sort(items, func(i, j int) bool {
+		a, b := items[i].ExpiresOn, items[j].ExpiresOn
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		if a != nil && !a.Equal(b.Time) {
+			return a.Before(b.Time)
+		}
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+	return items
+}
+
+// GetPantryItem returns a pantry item the caller owns
+func (s *PantryService) GetPantryItem(id string, caller Caller) (models.PantryItem, error) {
+	item, err := s.repository.FindByID(id)
+	if err != nil || !caller.CanModify(item.OwnerID) {
+		return models.PantryItem{}, ErrPantryItemNotFound
+	}
+	return item, nil
+}
+
+// AddPantryItem adds an ingredient to the caller's pantry
+func (s *PantryService) AddPantryItem(caller Caller, input models.PantryItemInput) (models.PantryItem, error) {
+	input, err := normalizePantryItem(input)
+	if err != nil {
+		return models.PantryItem{}, err
+	}
+	return s.repository.Create(caller.UserID, input), nil
+}
+
+// UpdatePantryItem changes a pantry item the caller owns
+func (s *PantryService) UpdatePantryItem(id string, caller Caller, input models.PantryItemInput) (models.PantryItem, error) {
+	if _, err := s.GetPantryItem(id, caller); err != nil {
+		return models.PantryItem{}, err
+	}
+
+	input, err := normalizePantryItem(input)
+	if err != nil {
+		return models.PantryItem{}, err
+	}
+	return s.repository.Update(id, input)
+}
+
+// DeletePantryItem removes a pantry item the caller owns
+func (s *PantryService) DeletePantryItem(id string, caller Caller) error {
+	if _, err := s.GetPantryItem(id, caller); err != nil {
+		return err
+	}
+	return s.repository.Delete(id)
+}
+
+// matchRecipe works out how much of a recipe's ingredients, with sub-recipes expanded, the pantry
+// covers as of today. Optional ingredients never count as missing. Expired items are ignored.
+func (s *PantryService) matchRecipe(recipe models.Recipe, ingredients []models.Ingredient, pantry []models.PantryItem, today models.Date) models.PantryMatch {
+	match := models.PantryMatch{
+		Recipe:   recipe,
+		Missing:  make([]models.Ingredient, 0),
+		Expiring: make([]string, 0),
+	}
+
+	required, covered := 0, 0
+	for _, ingredient := range ingredients {
+		items := Filter(pantry, func(item models.PantryItem) bool {
+			return !item.IsExpired(today) && sameIngredient(item.Name, ingredient.Name)
+		})
+		for _, item := range items {
+			if item.ExpiresWithin(today, ExpiryWindowDays) && !Contains(match.Expiring, item.Name) {
+				match.Expiring = append(match.Expiring, item.Name)
+			}
+		}
+
+		if ingredient.Optional {
+			continue
+		}
+		required++
+		if len(items) > 0 && s.enough(items, ingredient) {
+			covered++
+		} else {
+			match.Missing = append(match.Missing, ingredient)
+		}
+	}
+
+	if required > 0 {
+		match.Coverage = math.Round(float64(covered)/float64(required)*100) / 100
+	}
+	match.Score = match.Coverage + ExpiryBoost*float64(len(match.Expiring))
+	return match
+}
+
+// enough reports whether pantry items of an ingredient add up to the amount a recipe calls for.
+// Amounts that cannot be compared, such as 2 onions against 1 cup of onion, are taken to be enough.
+func (s *PantryService) enough(items []models.PantryItem, ingredient models.Ingredient) bool {
+	if ingredient.Quantity == nil {
+		return true
+	}
+
+	have, comparable := 0.0, false
+	for _, item := range items {
+		if item.Quantity == nil {
+			return true
+		}
+
+		amount := item.Quantity.Float64()
+		if item.Unit != ingredient.Unit {
+			converted, err := s.conversionService.ConvertIngredientAmount(amount, item.Unit, ingredient.Unit, ingredient.Name)
+			if err != nil {
+				continue
+			}
+			amount = converted
+		}
+		have += amount
+		comparable = true
+	}
+
+	// Allow for rounding in unit conversions
+	return !comparable || have >= ingredient.Quantity.Float64()*0.99
+}
+
+// sameIngredient reports whether a pantry item covers an ingredient: the names are the same or the
+// ingredient is a more specific kind of the item, so "flour" in the pantry covers "all-purpose flour".
+// Only words in front narrow a name, so "garlic" does not cover "garlic powder", and a more specific
+// item never covers a general ingredient, so "peanut butter" does not cover "butter".
+func sameIngredient(pantryName, ingredientName string) bool {
+	item, ingredient := ingredientKey(pantryName), ingredientKey(ingredientName)
+	return item != "" && (item == ingredient || strings.HasSuffix(ingredient, " "+item))
+}
+
+// normalizePantryItem trims a pantry item's name, puts its unit in canonical form and validates it
+func normalizePantryItem(input models.PantryItemInput) (models.PantryItemInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" || (input.Quantity != nil && input.Quantity.Float64() < 0) {
+		return input, ErrInvalidPantryItem
+	}
+
+	input.Unit = strings.TrimSpace(input.Unit)
+	if unit, ok := models.NormalizeUnit(input.Unit); ok {
+		input.Unit = unit
+	}
+	return input, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestPantrySearch tests pantry items and ranking recipes by how much of them the pantry covers
+func TestPantrySearch(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	pantryRepo := repositories.NewInMemoryPantryRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	pantryService := NewPantryService(pantryRepo, NewConversionService())
+	service := NewSearchService(recipeService, nil, pantryService)
+
+	alice := Caller{UserID: "alice"}
+	bob := Caller{UserID: "bob"}
+
+	today := date(t, "2024-03-04")
+	tomorrow, yesterday := today.AddDays(1), today.AddDays(-1)
+	amount := func(n int64) *models.Quantity {
+		q := models.WholeQuantity(n)
+		return &q
+	}
+
+	invalid := []models.PantryItemInput{
+		{Name: "  "},
+		{Name: "flour", Quantity: amount(-1)},
+	}
+	for _, input := range invalid {
+		if _, err := pantryService.AddPantryItem(alice, input); !errors.Is(err, ErrInvalidPantryItem) {
+			t.Errorf("Expected ErrInvalidPantryItem for %+v, but got %v", input, err)
+		}
+	}
+
+	flour, _ := pantryService.AddPantryItem(alice, models.PantryItemInput{Name: "flour", Quantity: amount(500), Unit: "grams"})
+	if flour.Unit != models.UnitGram {
+		t.Errorf("Expected the unit to be normalized to g, but got '%s'", flour.Unit)
+	}
+	pantryService.AddPantryItem(alice, models.PantryItemInput{Name: "Eggs", Quantity: amount(6)})
+	pantryService.AddPantryItem(alice, models.PantryItemInput{Name: "milk", Quantity: amount(1), Unit: "cup", ExpiresOn: &tomorrow})
+	pantryService.AddPantryItem(alice, models.PantryItemInput{Name: "butter", Quantity: amount(250), Unit: "g", ExpiresOn: &yesterday})
+	pantryService.AddPantryItem(alice, models.PantryItemInput{Name: "salt"})
+
+	pantry := pantryService.GetPantry(alice)
+	names := make([]string, 0, len(pantry))
+	for _, item := range pantry {
+		names = append(names, item.Name)
+	}
+	if got := strings.Join(names, ","); got != "butter,milk,Eggs,flour,salt" {
+		t.Errorf("Expected items expiring soonest first, but got %s", got)
+	}
+
+	if _, err := pantryService.UpdatePantryItem(flour.ID, bob, models.PantryItemInput{Name: "flour"}); !errors.Is(err, ErrPantryItemNotFound) {
+		t.Errorf("Expected ErrPantryItemNotFound for another user, but got %v", err)
+	}
+
+	create := func(title string, lines ...string) {
+		recipeService.CreateRecipe(alice.UserID, models.RecipeInput{Title: title, Ingredients: models.ParseIngredients(lines)})
+	}
+	create("Pancakes", "1 cup flour", "2 eggs", "1 cup milk", "1 tbsp sugar", "salt")
+	create("Omelette", "3 eggs", "2 tbsp butter", "1 pinch salt")
+	create("Bread", "5 cups flour", "1 tsp salt", "2 cups water")
+	create("Fruit Salad", "2 apples", "1 orange")
+	create("Custard", "2 eggs", "1 cup milk", "1 tsp vanilla (optional)")
+
+	matches := service.SearchByPantry(alice, -1, today)
+	got := make([]string, 0, len(matches))
+	for _, match := range matches {
+		got = append(got, match.Title)
+	}
+	if strings.Join(got, ",") != "Custard,Pancakes,Omelette,Bread" {
+		t.Fatalf("Expected recipes ranked by coverage and expiring milk, but got %v", got)
+	}
+
+	custard, pancakes, omelette, bread := matches[0], matches[1], matches[2], matches[3]
+	if custard.Coverage != 1 || len(custard.Missing) != 0 || strings.Join(custard.Expiring, ",") != "milk" {
+		t.Errorf("Expected the custard to be covered apart from the optional vanilla, but got %+v", custard)
+	}
+	if pancakes.Coverage != 0.8 || len(pancakes.Missing) != 1 || pancakes.Missing[0].Name != "sugar" {
+		t.Errorf("Expected the pancakes to only miss sugar, but got %+v", pancakes.Missing)
+	}
+	if len(omelette.Missing) != 1 || omelette.Missing[0].Name != "butter" {
+		t.Errorf("Expected the expired butter to be missing, but got %+v", omelette.Missing)
+	}
+	if bread.Coverage != 0.33 || bread.Missing[0].Name != "flour" {
+		t.Errorf("Expected 500 g of flour to fall short of 5 cups, but got %+v", bread.Missing)
+	}
+
+	if matches := service.SearchByPantry(alice, 0, today); len(matches) != 1 || matches[0].Title != "Custard" {
+		t.Errorf("Expected only the custard with nothing missing, but got %d results", len(matches))
+	}
+	if matches := service.SearchByPantry(bob, -1, today); len(matches) != 0 {
+		t.Errorf("Expected no results for an empty pantry, but got %d", len(matches))
+	}
+}
+
+// TestSameIngredient tests that a pantry item only covers the same or a more specific ingredient
+func TestSameIngredient(t *testing.T) {
+	tests := []struct {
+		pantry     string
+		ingredient string
+		expected   bool
+	}{
+		{"flour", "all-purpose flour", true},
+		{"Eggs", "egg", true},
+		{"salt", "sea salt", true},
+		{"all-purpose flour", "flour", false},
+		{"peanut butter", "butter", false},
+		{"butter", "butter beans", false},
+		{"garlic", "garlic powder", false},
+		{"garlic powder", "garlic", false},
+	}
+
+	for _, tc := range tests {
+		if got := sameIngredient(tc.pantry, tc.ingredient); got != tc.expected {
+			t.Errorf("Expected %q covering %q to be %v, but got %v", tc.pantry, tc.ingredient, tc.expected, got)
+		}
+	}
+}
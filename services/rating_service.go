@@ -2,22 +2,37 @@ package services
 
 import (
 	"errors"
+	"time"
+
 	"playground/models"
 	"playground/repositories"
 )
 
 // RatingService handles business logic for recipe ratings
 type RatingService struct {
-	repository repositories.RatingRepository
+	repository    repositories.RatingRepository
 	recipeService *RecipeService
 }
 
 // NewRatingService creates a new rating service with the given repositories
 func NewRatingService(repository repositories.RatingRepository, recipeService *RecipeService) *RatingService {
-	return &RatingService{
+	service := &RatingService{
 		repository:    repository,
 		recipeService: recipeService,
 	}
+
+	// Ratings follow their recipe into and out of the trash
+	recipeService.On(RecipeDeleted, func(recipe models.Recipe) {
+		repository.DeleteByRecipeID(recipe.ID, *recipe.DeletedAt)
+	})
+	recipeService.On(RecipeRestored, func(recipe models.Recipe) {
+		repository.RestoreByRecipeID(recipe.ID, *recipe.DeletedAt)
+	})
+	recipeService.On(RecipePurged, func(recipe models.Recipe) {
+		repository.PurgeByRecipeID(recipe.ID)
+	})
+
+	return service
 }
 
 // GetAllRatings returns all ratings
@@ -42,12 +57,12 @@ func (s *RatingService) GetRatingsByUserID(userID string) []models.Rating {
 
 // CreateRating adds a new rating
 func (s *RatingService) CreateRating(recipeID string, userID string, input models.RatingInput) (models.Rating, error) {
-	// Verify that the recipe exists
-	_, err := s.recipeService.GetRecipeByID(recipeID)
+	// Verify that the recipe exists and the user may see it
+	_, err := s.recipeService.GetVisibleRecipe(recipeID, Caller{UserID: userID})
 	if err != nil {
 		return models.Rating{}, err
 	}
-	
+
 	// Create the rating
 	rating := s.repository.Create(recipeID, userID, input)
 	return rating, nil
@@ -60,44 +75,77 @@ func (s *RatingService) UpdateRating(id string, userID string, input models.Rati
 	if err != nil {
 		return models.Rating{}, err
 	}
-	
+
 	// Check if the rating belongs to the user
 	if rating.UserID != userID {
 		return models.Rating{}, errors.New("unauthorized: rating belongs to another user")
 	}
-	
+
 	// Update the rating
 	return s.repository.Update(id, input)
 }
 
-// DeleteRating removes a rating
+// DeleteRating moves a rating to the trash
 func (s *RatingService) DeleteRating(id string, userID string) error {
 	// Verify that the rating exists and belongs to the user
 	rating, err := s.repository.FindByID(id)
 	if err != nil {
 		return err
 	}
-	
+
 	// Check if the rating belongs to the user
 	if rating.UserID != userID {
 		return errors.New("unauthorized: rating belongs to another user")
 	}
-	
+
 	// Delete the rating
 	return s.repository.Delete(id)
 }
 
+// GetDeletedRatingsByUserID returns the ratings a user has in the trash
+func (s *RatingService) GetDeletedRatingsByUserID(userID string) []models.Rating {
+	return s.repository.FindDeletedByUserID(userID)
+}
+
+// RestoreRating takes a rating out of the trash if it belongs to the user and its recipe still exists
+func (s *RatingService) RestoreRating(id string, userID string) (models.Rating, error) {
+	// Verify that the rating is in the user's trash
+	var deleted *models.Rating
+	for _, rating := range s.repository.FindDeletedByUserID(userID) {
+		if rating.ID == id {
+			deleted = &rating
+			break
+		}
+	}
+	if deleted == nil {
+		return models.Rating{}, errors.New("rating not found in trash")
+	}
+
+	// A rating cannot outlive its recipe
+	if _, err := s.recipeService.GetRecipeByID(deleted.RecipeID); err != nil {
+		return models.Rating{}, err
+	}
+
+	return s.repository.Restore(id)
+}
+
+// PurgeDeletedRatings permanently removes ratings that were moved to the trash before the
+// given time and returns how many were removed
+func (s *RatingService) PurgeDeletedRatings(deletedBefore time.Time) int {
+	return s.repository.Purge(deletedBefore)
+}
+
 // GetAverageRatingForRecipe calculates the average rating score for a recipe
 func (s *RatingService) GetAverageRatingForRecipe(recipeID string) float64 {
 	ratings := s.repository.FindByRecipeID(recipeID)
 	if len(ratings) == 0 {
 		return 0
 	}
-	
+
 	total := 0
 	for _, rating := range ratings {
 		total += rating.Score
 	}
-	
+
 	return float64(total) / float64(len(ratings))
-}
\ No newline at end of file
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+
+	"playground/models"
+)
+
+// ErrRecipeNotFound is returned when a recipe does not exist or the caller may not see it
+var ErrRecipeNotFound = errors.New("recipe not found")
+
+// canView reports whether the caller may open a recipe directly by ID.
+// Drafts and private recipes are only visible to their author and admins;
+// unlisted recipes can be opened by anyone who knows the ID.
+func canView(recipe models.Recipe, caller Caller) bool {
+	if caller.CanModify(recipe.AuthorID) {
+		return true
+	}
+	if recipe.Visibility == models.VisibilityPrivate {
+		return false
+	}
+	return recipe.Status == models.StatusPublished || recipe.Status == models.StatusArchived
+}
+
+// isListed reports whether a recipe appears in the caller's lists, searches and sorts.
+// Everyone sees published public recipes; authors also see all of their own recipes.
+func isListed(recipe models.Recipe, caller Caller) bool {
+	if caller.UserID != "" && caller.UserID == recipe.AuthorID {
+		return true
+	}
+	return recipe.Status == models.StatusPublished && recipe.Visibility == models.VisibilityPublic
+}
+
+// listedFor filters recipes down to the ones listed for the caller
+func listedFor(recipes []models.Recipe, caller Caller) []models.Recipe {
+	return Filter(recipes, func(recipe models.Recipe) bool {
+		return isListed(recipe, caller)
+	})
+}
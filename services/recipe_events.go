@@ -0,0 +1,38 @@
+package services
+
+import (
+	"playground/models"
+)
+
+// RecipeEvent identifies a change in a recipe's lifecycle that other services can react to
+type RecipeEvent string
+
+// Recipe lifecycle events
+const (
+	// RecipeUpdated runs after a recipe's content, visibility or status changes; the hook receives
+	// the recipe as it is now
+	RecipeUpdated RecipeEvent = "updated"
+	// RecipeDeleted runs after a recipe is moved to the trash; the hook receives the deleted recipe
+	RecipeDeleted RecipeEvent = "deleted"
+	// RecipeRestored runs after a recipe leaves the trash; the hook receives the recipe as it was
+	// in the trash, so its DeletedAt identifies what was deleted along with it
+	RecipeRestored RecipeEvent = "restored"
+	// RecipePurged runs after a recipe is permanently removed
+	RecipePurged RecipeEvent = "purged"
+)
+
+// RecipeHook is a function run after a recipe lifecycle event
+type RecipeHook func(recipe models.Recipe)
+
+// On registers a hook to run after the given recipe event. Hooks are expected to be
+// registered while services are wired together, before requests are served.
+func (s *RecipeService) On(event RecipeEvent, hook RecipeHook) {
+	s.hooks[event] = append(s.hooks[event], hook)
+}
+
+// emit runs every hook registered for an event in registration order
+func (s *RecipeService) emit(event RecipeEvent, recipe models.Recipe) {
+	for _, hook := range s.hooks[event] {
+		hook(recipe)
+	}
+}
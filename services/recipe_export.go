@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"playground/models"
+)
+
+// recipeTemplate is the print-friendly HTML page of a recipe
+//
+//go:embed templates/recipe.html
+var recipeTemplate string
+
+// recipePage is rendered with the recipe template
+var recipePage = template.Must(template.New("recipe").Funcs(template.FuncMap{
+	"minutes": FormatMinutes,
+	"join":    joinValues,
+}).Parse(recipeTemplate))
+
+// schemaDiets maps the dietary labels that have a schema.org RestrictedDiet to it
+var schemaDiets = map[models.DietaryLabel]string{
+	models.LabelVegan:      "https://schema.org/VeganDiet",
+	models.LabelVegetarian: "https://schema.org/VegetarianDiet",
+	models.LabelGlutenFree: "https://schema.org/GlutenFreeDiet",
+}
+
+// SchemaRecipe is a recipe as a schema.org Recipe in JSON-LD
+type SchemaRecipe struct {
+	Context            string        `json:"@context"`
+	Type               string        `json:"@type"`
+	Name               string        `json:"name"`
+	Description        string        `json:"description,omitempty"`
+	Image              []string      `json:"image,omitempty"`
+	Author             *SchemaThing  `json:"author,omitempty"`
+	Publisher          *SchemaThing  `json:"publisher,omitempty"`
+	IsBasedOn          string        `json:"isBasedOn,omitempty"`
+	DateCreated        string        `json:"dateCreated"`
+	DateModified       string        `json:"dateModified"`
+	Keywords           string        `json:"keywords,omitempty"`
+	RecipeYield        string        `json:"recipeYield,omitempty"`
+	PrepTime           string        `json:"prepTime,omitempty"`
+	CookTime           string        `json:"cookTime,omitempty"`
+	TotalTime          string        `json:"totalTime,omitempty"`
+	SuitableForDiet    []string      `json:"suitableForDiet,omitempty"`
+	RecipeIngredient   []string      `json:"recipeIngredient"`
+	RecipeInstructions []interface{} `json:"recipeInstructions"`
+}
+
+// SchemaThing is a named schema.org node such as a Person or an Organization
+type SchemaThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// SchemaStep is a schema.org HowToStep
+type SchemaStep struct {
+	Type string `json:"@type"`
+	Text string `json:"text"`
+}
+
+// SchemaSection is a schema.org HowToSection of steps
+type SchemaSection struct {
+	Type            string       `json:"@type"`
+	Name            string       `json:"name,omitempty"`
+	ItemListElement []SchemaStep `json:"itemListElement"`
+}
+
+// RecipeJSONLD maps a recipe to a schema.org Recipe. Instructions are HowToSteps, grouped in
+// HowToSections when the recipe has named sections; the source is credited as author and publisher.
+func RecipeJSONLD(recipe models.Recipe) SchemaRecipe {
+	schema := SchemaRecipe{
+		Context:            "https://schema.org",
+		Type:               "Recipe",
+		Name:               recipe.Title,
+		Description:        recipe.Description,
+		DateCreated:        recipe.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		DateModified:       recipe.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		Keywords:           strings.Join(recipe.Tags, ", "),
+		PrepTime:           isoDuration(recipe.PrepTime),
+		CookTime:           isoDuration(recipe.CookTime),
+		TotalTime:          isoDuration(recipe.PrepTime + recipe.CookTime),
+		RecipeIngredient:   make([]string, 0, len(recipe.Ingredients)),
+		RecipeInstructions: make([]interface{}, 0, len(recipe.Instructions)),
+	}
+	if recipe.Servings > 0 {
+		schema.RecipeYield = strconv.Itoa(recipe.Servings)
+	}
+	if cover, ok := recipe.CoverImage(); ok {
+		schema.Image = append(schema.Image, cover.Variants[models.ImageOriginal].URL)
+	}
+	for _, label := range recipe.Diet.Labels {
+		if diet, ok := schemaDiets[label]; ok {
+			schema.SuitableForDiet = append(schema.SuitableForDiet, diet)
+		}
+	}
+	if source := recipe.Source; source != nil {
+		schema.IsBasedOn = source.URL
+		if source.Author != "" {
+			schema.Author = &SchemaThing{Type: "Person", Name: source.Author}
+		}
+		if source.Publisher != "" {
+			schema.Publisher = &SchemaThing{Type: "Organization", Name: source.Publisher}
+		}
+	}
+
+	for _, ingredient := range recipe.Ingredients {
+		schema.RecipeIngredient = append(schema.RecipeIngredient, ingredient.String())
+	}
+
+	// A single unnamed section is a plain list of steps
+	named := len(recipe.InstructionSections) > 1 ||
+		(len(recipe.InstructionSections) == 1 && recipe.InstructionSections[0].Name != "")
+	for _, section := range recipe.InstructionSections {
+		steps := make([]SchemaStep, 0, len(section.Steps))
+		for _, step := range section.Steps {
+			steps = append(steps, SchemaStep{Type: "HowToStep", Text: step.Text})
+		}
+		if !named {
+			for _, step := range steps {
+				schema.RecipeInstructions = append(schema.RecipeInstructions, step)
+			}
+			continue
+		}
+		schema.RecipeInstructions = append(schema.RecipeInstructions, SchemaSection{
+			Type:            "HowToSection",
+			Name:            section.Name,
+			ItemListElement: steps,
+		})
+	}
+
+	return schema
+}
+
+// WriteRecipeMarkdown writes a recipe as Markdown with its facts, ingredient list and numbered steps
+func WriteRecipeMarkdown(w io.Writer, recipe models.Recipe) error {
+	buffered := bufio.NewWriter(w)
+	fmt.Fprintf(buffered, "# %s\n", markdownText(recipe.Title))
+	if recipe.Description != "" {
+		fmt.Fprintf(buffered, "\n%s\n", markdownText(recipe.Description))
+	}
+
+	facts := make([]string, 0, 6)
+	if recipe.Servings > 0 {
+		facts = append(facts, fmt.Sprintf("**Servings:** %d", recipe.Servings))
+	}
+	if recipe.PrepTime > 0 {
+		facts = append(facts, "**Prep time:** "+FormatMinutes(recipe.PrepTime))
+	}
+	if recipe.CookTime > 0 {
+		facts = append(facts, "**Cook time:** "+FormatMinutes(recipe.CookTime))
+	}
+	if len(recipe.Tags) > 0 {
+		facts = append(facts, "**Tags:** "+markdownText(strings.Join(recipe.Tags, ", ")))
+	}
+	if len(recipe.Diet.Labels) > 0 {
+		facts = append(facts, "**Suitable for:** "+joinValues(recipe.Diet.Labels))
+	}
+	if len(recipe.Diet.Allergens) > 0 {
+		facts = append(facts, "**Contains:** "+joinValues(recipe.Diet.Allergens))
+	}
+	if len(facts) > 0 {
+		fmt.Fprintln(buffered)
+		for _, fact := range facts {
+			fmt.Fprintf(buffered, "- %s\n", fact)
+		}
+	}
+
+	fmt.Fprint(buffered, "\n## Ingredients\n")
+	for _, section := range recipe.IngredientSections {
+		if section.Name != "" {
+			fmt.Fprintf(buffered, "\n### %s\n", markdownText(section.Name))
+		}
+		fmt.Fprintln(buffered)
+		for _, ingredient := range section.Ingredients {
+			fmt.Fprintf(buffered, "- %s\n", markdownText(ingredient.String()))
+		}
+	}
+
+	fmt.Fprint(buffered, "\n## Instructions\n")
+	for _, section := range recipe.InstructionSections {
+		if section.Name != "" {
+			fmt.Fprintf(buffered, "\n### %s\n", markdownText(section.Name))
+		}
+		fmt.Fprintln(buffered)
+		for i, step := range section.Steps {
+			fmt.Fprintf(buffered, "%d. %s\n", i+1, markdownText(step.Text))
+		}
+	}
+
+	if source := recipe.Source; source != nil {
+		credit := make([]string, 0, 2)
+		for _, name := range []string{source.Author, source.Publisher} {
+			if name != "" {
+				credit = append(credit, markdownText(name))
+			}
+		}
+		line := strings.Join(credit, ", ")
+		switch {
+		case source.URL != "" && line != "":
+			line = fmt.Sprintf("[%s](<%s>)", line, strings.NewReplacer("<", "%3C", ">", "%3E").Replace(source.URL))
+		case source.URL != "":
+			line = fmt.Sprintf("<%s>", strings.NewReplacer("<", "%3C", ">", "%3E").Replace(source.URL))
+		}
+		fmt.Fprintf(buffered, "\nSource: %s\n", line)
+	}
+
+	return buffered.Flush()
+}
+
+// WriteRecipeHTML writes a recipe as a standalone, print-friendly HTML page
+func WriteRecipeHTML(w io.Writer, recipe models.Recipe) error {
+	page := struct {
+		models.Recipe
+		Cover *models.ImageVariant
+	}{Recipe: recipe}
+	if cover, ok := recipe.CoverImage(); ok {
+		variant := cover.Variants[models.ImageMedium]
+		page.Cover = &variant
+	}
+
+	return recipePage.Execute(w, page)
+}
+
+// FormatMinutes writes a duration in minutes as "45 min", "1 h" or "1 h 30 min"
+func FormatMinutes(minutes int) string {
+	hours, rest := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%d min", rest)
+	case rest == 0:
+		return fmt.Sprintf("%d h", hours)
+	default:
+		return fmt.Sprintf("%d h %d min", hours, rest)
+	}
+}
+
+// isoDuration writes a duration in minutes in ISO-8601, such as PT1H30M; zero is left empty
+func isoDuration(minutes int) string {
+	if minutes <= 0 {
+		return ""
+	}
+
+	duration := "PT"
+	if hours := minutes / 60; hours > 0 {
+		duration += strconv.Itoa(hours) + "H"
+	}
+	if rest := minutes % 60; rest > 0 {
+		duration += strconv.Itoa(rest) + "M"
+	}
+	return duration
+}
+
+// markdownEscaper escapes the characters that Markdown would read as formatting
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+)
+
+// markdownText escapes text for a single line of Markdown
+func markdownText(text string) string {
+	return markdownEscaper.Replace(strings.Join(strings.Fields(text), " "))
+}
+
+// joinValues joins the elements of a slice of strings or string types with commas
+func joinValues(values interface{}) string {
+	slice := reflect.ValueOf(values)
+	if slice.Kind() != reflect.Slice {
+		return fmt.Sprint(values)
+	}
+
+	parts := make([]string, 0, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		parts = append(parts, fmt.Sprint(slice.Index(i).Interface()))
+	}
+	return strings.Join(parts, ", ")
+}
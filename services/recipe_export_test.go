@@ -0,0 +1,96 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestRecipeExport tests rendering recipes as schema.org JSON-LD, Markdown and HTML
+func TestRecipeExport(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the service with the repository
+	service := NewRecipeService(repo)
+
+	recipe, err := service.CreateRecipe("author-1", models.RecipeInput{
+		Title:       "Lemon *Tart*",
+		Description: "Sharp & sweet <tart>",
+		IngredientSections: []models.IngredientSection{
+			{Name: "Pastry", Ingredients: models.ParseIngredients([]string{"200 g flour", "100 g butter"})},
+			{Name: "Filling", Ingredients: models.ParseIngredients([]string{"3 eggs", "2 lemons"})},
+		},
+		InstructionSections: []models.InstructionSection{
+			{Name: "Pastry", Steps: models.ParseSteps([]string{"Rub the butter into the flour", "Bake blind for 15 minutes"})},
+			{Name: "Filling", Steps: models.ParseSteps([]string{"Whisk the eggs with the lemons", "Bake for 25 minutes"})},
+		},
+		PrepTime: 30,
+		CookTime: 40,
+		Servings: 8,
+		Tags:     []string{"dessert", "baking"},
+		Source:   &models.RecipeSource{URL: "https://example.com/tart", Author: "Ada Cook"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// JSON-LD maps the times to ISO-8601 and the sections to HowToSections
+	schema := RecipeJSONLD(recipe)
+	if schema.Type != "Recipe" || schema.PrepTime != "PT30M" || schema.CookTime != "PT40M" || schema.TotalTime != "PT1H10M" {
+		t.Errorf("Expected a Recipe taking PT30M + PT40M, but got %+v", schema)
+	}
+	if schema.RecipeYield != "8" || schema.Keywords != "dessert, baking" || len(schema.RecipeIngredient) != 4 {
+		t.Errorf("Expected the yield, keywords and 4 ingredients, but got %+v", schema)
+	}
+	if len(schema.RecipeInstructions) != 2 || schema.Author == nil || schema.IsBasedOn != "https://example.com/tart" {
+		t.Errorf("Expected 2 sections credited to the source, but got %+v", schema)
+	}
+
+	// The JSON-LD imports back into the same recipe
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	input, err := ParseRecipeDocument(data, "")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if input.Title != recipe.Title || input.PrepTime != 30 || input.CookTime != 40 || input.Servings != 8 ||
+		len(input.Ingredients) != 4 || len(input.InstructionSections) != 2 || input.InstructionSections[1].Name != "Filling" {
+		t.Errorf("Expected the exported recipe to import unchanged, but got %+v", input)
+	}
+
+	// Markdown escapes formatting characters and numbers the steps of each section
+	var markdown bytes.Buffer
+	if err := WriteRecipeMarkdown(&markdown, recipe); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, want := range []string{
+		"# Lemon \\*Tart\\*\n", "Sharp & sweet \\<tart\\>", "- **Prep time:** 30 min", "- **Cook time:** 40 min",
+		"### Filling\n\n- 3 eggs\n", "### Filling\n\n1. Whisk the eggs with the lemons\n2. Bake for 25 minutes\n",
+		"Source: [Ada Cook](<https://example.com/tart>)",
+	} {
+		if !strings.Contains(markdown.String(), want) {
+			t.Errorf("Expected the Markdown to contain %q, but got:\n%s", want, markdown.String())
+		}
+	}
+
+	// HTML escapes the recipe's text
+	var page bytes.Buffer
+	if err := WriteRecipeHTML(&page, recipe); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, want := range []string{
+		"<h1>Lemon *Tart*</h1>", "Sharp &amp; sweet &lt;tart&gt;", "<dd>40 min</dd>", "<h3>Filling</h3>",
+		"<li>Bake for 25 minutes</li>", `<a href="https://example.com/tart">`,
+	} {
+		if !strings.Contains(page.String(), want) {
+			t.Errorf("Expected the HTML to contain %q, but got:\n%s", want, page.String())
+		}
+	}
+}
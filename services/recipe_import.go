@@ -0,0 +1,324 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"playground/models"
+)
+
+// ErrNoRecipeFound is returned when an imported document holds no schema.org Recipe
+var ErrNoRecipeFound = errors.New("no schema.org Recipe found in the document")
+
+// ErrInvalidImport is returned when an imported document is not JSON-LD or HTML, or its recipe has no name
+var ErrInvalidImport = errors.New("document must be JSON-LD or an HTML page with a named schema.org Recipe")
+
+// ldScriptPattern matches the JSON-LD script blocks of an HTML page
+var ldScriptPattern = regexp.MustCompile(`(?is)<script\b[^>]*\btype\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script\s*>`)
+
+// isoDurationPattern matches ISO-8601 durations such as PT1H30M or P0DT0H45M
+var isoDurationPattern = regexp.MustCompile(`(?i)^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// tagPattern matches the HTML tags some sites leave in JSON-LD text
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// integerPattern finds the first whole number in a yield such as "4-6 servings"
+var integerPattern = regexp.MustCompile(`\d+`)
+
+// ParseRecipeDocument extracts the schema.org Recipe from a JSON-LD document, or from the JSON-LD
+// scripts of an HTML page, and maps it to a RecipeInput credited to sourceURL
+func ParseRecipeDocument(data []byte, sourceURL string) (models.RecipeInput, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(data) == 0 {
+		return models.RecipeInput{}, ErrInvalidImport
+	}
+
+	// A bare JSON-LD document must parse; broken script blocks on a page are skipped
+	if data[0] == '{' || data[0] == '[' {
+		var document interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return models.RecipeInput{}, ErrInvalidImport
+		}
+		if recipe := findRecipe(document); recipe != nil {
+			return mapRecipe(recipe, sourceURL)
+		}
+		return models.RecipeInput{}, ErrNoRecipeFound
+	}
+	if data[0] != '<' {
+		return models.RecipeInput{}, ErrInvalidImport
+	}
+
+	for _, match := range ldScriptPattern.FindAllSubmatch(data, -1) {
+		block := bytes.TrimSpace(match[1])
+		block = bytes.TrimSpace(bytes.TrimSuffix(bytes.TrimPrefix(block, []byte("<!--")), []byte("-->")))
+
+		var document interface{}
+		if err := json.Unmarshal(block, &document); err != nil {
+			continue
+		}
+		if recipe := findRecipe(document); recipe != nil {
+			return mapRecipe(recipe, sourceURL)
+		}
+	}
+	return models.RecipeInput{}, ErrNoRecipeFound
+}
+
+// findRecipe searches a JSON-LD value depth first for the first node typed as a Recipe
+func findRecipe(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if recipe := findRecipe(item); recipe != nil {
+				return recipe
+			}
+		}
+	case map[string]interface{}:
+		if isRecipeType(v["@type"]) {
+			return v
+		}
+		// @graph and mainEntity hold the recipe on most pages; other keys are searched in a stable order
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if key != "@graph" && key != "mainEntity" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range append([]string{"@graph", "mainEntity"}, keys...) {
+			if recipe := findRecipe(v[key]); recipe != nil {
+				return recipe
+			}
+		}
+	}
+	return nil
+}
+
+// isRecipeType reports whether a JSON-LD @type names a Recipe, with or without a vocabulary prefix
+func isRecipeType(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		name := v[strings.LastIndexAny(v, "/:#")+1:]
+		return name == "Recipe"
+	case []interface{}:
+		for _, item := range v {
+			if isRecipeType(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapRecipe maps the properties of a schema.org Recipe to a RecipeInput
+func mapRecipe(recipe map[string]interface{}, sourceURL string) (models.RecipeInput, error) {
+	title := ldText(recipe["name"])
+	if title == "" {
+		title = ldText(recipe["headline"])
+	}
+	if title == "" {
+		return models.RecipeInput{}, ErrInvalidImport
+	}
+
+	input := models.RecipeInput{
+		Title:               title,
+		Description:         ldText(recipe["description"]),
+		InstructionSections: ldInstructions(recipe["recipeInstructions"]),
+		Servings:            ldYield(recipe["recipeYield"]),
+		Tags:                ldKeywords(recipe["keywords"]),
+	}
+
+	lines := ldTexts(recipe["recipeIngredient"])
+	if len(lines) == 0 {
+		lines = ldTexts(recipe["ingredients"])
+	}
+	input.Ingredients = models.ParseIngredients(lines)
+
+	prep, _ := ldDuration(recipe["prepTime"])
+	cook, hasCook := ldDuration(recipe["cookTime"])
+	if total, ok := ldDuration(recipe["totalTime"]); ok && !hasCook && total > prep {
+		cook = total - prep
+	}
+	input.PrepTime = prep
+	input.CookTime = cook
+
+	source := models.RecipeSource{
+		URL:       sourceURL,
+		Author:    ldNames(recipe["author"]),
+		Publisher: ldNames(recipe["publisher"]),
+	}
+	if source.URL == "" {
+		source.URL = ldText(recipe["url"])
+	}
+	if source != (models.RecipeSource{}) {
+		input.Source = &source
+	}
+
+	return input.NormalizeSections(), nil
+}
+
+// ldText returns a JSON-LD value as plain text, without markup, entities or repeated whitespace
+func ldText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		text := html.UnescapeString(tagPattern.ReplaceAllString(v, " "))
+		return strings.Join(strings.Fields(text), " ")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		if len(v) > 0 {
+			return ldText(v[0])
+		}
+	case map[string]interface{}:
+		if text := ldText(v["text"]); text != "" {
+			return text
+		}
+		return ldText(v["name"])
+	}
+	return ""
+}
+
+// ldTexts returns the non-empty texts of a JSON-LD value that may be a single item or a list
+func ldTexts(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	var texts []string
+	for _, item := range items {
+		if text := ldText(item); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// ldInstructions maps recipeInstructions, given as text, a list of strings or HowToSteps,
+// or HowToSections of steps, to instruction sections
+func ldInstructions(value interface{}) []models.InstructionSection {
+	var sections []models.InstructionSection
+	var unnamed []string
+	flush := func() {
+		if len(unnamed) > 0 {
+			sections = append(sections, models.InstructionSection{Steps: models.ParseSteps(unnamed)})
+			unnamed = nil
+		}
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		// A single block of text has one step per line
+		if text, isText := value.(string); isText {
+			for _, line := range strings.Split(text, "\n") {
+				if line = ldText(line); line != "" {
+					unnamed = append(unnamed, line)
+				}
+			}
+			flush()
+			return sections
+		}
+		items = []interface{}{value}
+	}
+
+	for _, item := range items {
+		node, isNode := item.(map[string]interface{})
+		if isNode && node["itemListElement"] != nil {
+			flush()
+			if steps := ldSteps(node["itemListElement"]); len(steps) > 0 {
+				sections = append(sections, models.InstructionSection{
+					Name:  ldText(node["name"]),
+					Steps: models.ParseSteps(steps),
+				})
+			}
+			continue
+		}
+		unnamed = append(unnamed, ldSteps(item)...)
+	}
+	flush()
+	return sections
+}
+
+// ldSteps flattens the texts of HowToSteps, strings and nested lists of them
+func ldSteps(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		var steps []string
+		for _, item := range v {
+			steps = append(steps, ldSteps(item)...)
+		}
+		return steps
+	case map[string]interface{}:
+		if v["itemListElement"] != nil {
+			return ldSteps(v["itemListElement"])
+		}
+	}
+	if text := ldText(value); text != "" {
+		return []string{text}
+	}
+	return nil
+}
+
+// ldDuration converts an ISO-8601 duration to whole minutes
+func ldDuration(value interface{}) (int, bool) {
+	text := strings.TrimSpace(ldText(value))
+	match := isoDurationPattern.FindStringSubmatch(text)
+	if match == nil || strings.EqualFold(text, "P") || strings.EqualFold(text, "PT") || strings.HasSuffix(strings.ToUpper(text), "T") {
+		return 0, false
+	}
+
+	minutes := 0.0
+	for i, perUnit := range []float64{24 * 60, 60, 1, 1.0 / 60} {
+		if match[i+1] != "" {
+			amount, _ := strconv.ParseFloat(match[i+1], 64)
+			minutes += amount * perUnit
+		}
+	}
+	return int(math.Round(minutes)), true
+}
+
+// ldYield returns the first whole number of a recipeYield such as 4, "4" or "4-6 servings"
+func ldYield(value interface{}) int {
+	for _, text := range ldTexts(value) {
+		if number := integerPattern.FindString(text); number != "" {
+			if servings, err := strconv.Atoi(number); err == nil && servings > 0 {
+				return servings
+			}
+		}
+	}
+	return 0
+}
+
+// ldKeywords splits keywords, given as a comma separated string or a list, into distinct tags
+func ldKeywords(value interface{}) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, text := range ldTexts(value) {
+		for _, keyword := range strings.Split(text, ",") {
+			keyword = strings.TrimSpace(keyword)
+			key := strings.ToLower(keyword)
+			if keyword != "" && !seen[key] {
+				seen[key] = true
+				tags = append(tags, keyword)
+			}
+		}
+	}
+	return tags
+}
+
+// ldNames joins the names of a Person or Organization, or a list of them
+func ldNames(value interface{}) string {
+	var names []string
+	for _, name := range ldTexts(value) {
+		if !Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
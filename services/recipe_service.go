@@ -1,51 +1,348 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
 	"playground/models"
 	"playground/repositories"
 )
 
+// ErrNotRecipeOwner is returned when a caller tries to change a recipe they do not own
+var ErrNotRecipeOwner = errors.New("unauthorized: recipe belongs to another user")
+
+// ErrInvalidScale is returned when a recipe cannot be scaled as requested
+var ErrInvalidScale = errors.New("invalid scale: factor and servings must be positive")
+
+// ErrInvalidTransition is returned when a recipe cannot move to the requested status
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// ErrNotPublishable is returned when a recipe lacks the content required to publish it
+var ErrNotPublishable = errors.New("recipe needs a title, ingredients and instructions to be published")
+
+// statusTransitions lists the statuses each recipe status may move to
+var statusTransitions = map[models.RecipeStatus][]models.RecipeStatus{
+	models.StatusDraft:     {models.StatusPublished},
+	models.StatusPublished: {models.StatusDraft, models.StatusArchived},
+	models.StatusArchived:  {models.StatusPublished, models.StatusDraft},
+}
+
 // RecipeService handles business logic for recipes
 type RecipeService struct {
 	repository repositories.RecipeRepository
+	hooks      map[RecipeEvent][]RecipeHook
+	sortKeys   map[SortBy]SortKey
+	classifier *IngredientClassifier
 }
 
 // NewRecipeService creates a new recipe service with the given repository
 func NewRecipeService(repository repositories.RecipeRepository) *RecipeService {
-	return &RecipeService{
+	service := &RecipeService{
 		repository: repository,
+		hooks:      make(map[RecipeEvent][]RecipeHook),
+		sortKeys:   make(map[SortBy]SortKey),
+		classifier: NewIngredientClassifier(),
 	}
+
+	// The diet of a recipe includes its sub-recipes, so it follows their changes
+	service.On(RecipeUpdated, service.refreshDependentDiets)
+	service.On(RecipeDeleted, service.refreshDependentDiets)
+	service.On(RecipeRestored, func(recipe models.Recipe) {
+		if restored, err := repository.FindByID(recipe.ID); err == nil {
+			service.refreshDiet(restored)
+		}
+		service.refreshDependentDiets(recipe)
+	})
+
+	return service
 }
 
-// GetAllRecipes returns all recipes
-func (s *RecipeService) GetAllRecipes() []models.Recipe {
-	return s.repository.FindAll()
+// GetAllRecipes returns all recipes listed for the caller
+func (s *RecipeService) GetAllRecipes(caller Caller) []models.Recipe {
+	return listedFor(s.repository.FindAll(), caller)
 }
 
-// GetRecipeByID returns a recipe by ID
+// GetRecipeByID returns a recipe by ID regardless of who may see it
 func (s *RecipeService) GetRecipeByID(id string) (models.Recipe, error) {
 	return s.repository.FindByID(id)
 }
 
-// CreateRecipe adds a new recipe
-func (s *RecipeService) CreateRecipe(input models.RecipeInput) models.Recipe {
-	return s.repository.Create(input)
+// GetVisibleRecipe returns a recipe by ID if the caller may see it
+func (s *RecipeService) GetVisibleRecipe(id string, caller Caller) (models.Recipe, error) {
+	recipe, err := s.repository.FindByID(id)
+	if err != nil || !canView(recipe, caller) {
+		return models.Recipe{}, ErrRecipeNotFound
+	}
+	return recipe, nil
+}
+
+// modifiableRecipe returns a recipe the caller owns or is an admin for. Recipes the caller cannot
+// see are reported as not found, so only visible recipes answer with ErrNotRecipeOwner.
+func (s *RecipeService) modifiableRecipe(id string, caller Caller) (models.Recipe, error) {
+	recipe, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	if !caller.CanModify(recipe.AuthorID) {
+		return models.Recipe{}, ErrNotRecipeOwner
+	}
+	return recipe, nil
+}
+
+// GetRecipesByAuthor returns the recipes created by a specific user that are listed for the caller
+func (s *RecipeService) GetRecipesByAuthor(authorID string, caller Caller) []models.Recipe {
+	return listedFor(s.repository.FindByAuthorID(authorID), caller)
+}
+
+// CreateRecipe adds a new recipe owned by the given author
+func (s *RecipeService) CreateRecipe(authorID string, input models.RecipeInput) (models.Recipe, error) {
+	input = input.NormalizeSections()
+	if err := s.ValidateRecipe(authorID, input); err != nil {
+		return models.Recipe{}, err
+	}
+
+	input.Diet = s.classify(input, Caller{UserID: authorID}, "")
+	return s.repository.Create(authorID, input), nil
+}
+
+// ValidateRecipe runs the checks CreateRecipe makes before saving a new recipe, without saving it
+func (s *RecipeService) ValidateRecipe(authorID string, input models.RecipeInput) error {
+	input = input.NormalizeSections()
+	return s.checkSubRecipes("", input.Ingredients, Caller{UserID: authorID})
+}
+
+// UpdateRecipe modifies an existing recipe if the caller owns it or is an admin
+func (s *RecipeService) UpdateRecipe(id string, caller Caller, input models.RecipeInput) (models.Recipe, error) {
+	// Verify that the recipe exists and belongs to the caller
+	recipe, err := s.modifiableRecipe(id, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	// Published recipes must stay complete
+	input = input.NormalizeSectionsFor(recipe)
+	if recipe.Status == models.StatusPublished && !isPublishable(input) {
+		return models.Recipe{}, ErrNotPublishable
+	}
+
+	author := Caller{UserID: recipe.AuthorID}
+	if err := s.checkSubRecipes(id, input.Ingredients, author); err != nil {
+		return models.Recipe{}, err
+	}
+
+	input.Diet = s.classify(input, author, id)
+	updated, err := s.repository.Update(id, caller.UserID, input)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	s.emit(RecipeUpdated, updated)
+	return updated, nil
+}
+
+// classify derives the diet of a recipe from its ingredients, including those of its sub-recipes
+func (s *RecipeService) classify(input models.RecipeInput, author Caller, id string) models.DietInfo {
+	input.Ingredients, _ = s.expandIngredients(input.Ingredients, author, author, id)
+	return s.classifier.Classify(input)
+}
+
+// TransitionRecipe moves a recipe to another lifecycle status if the caller owns it or is an admin
+func (s *RecipeService) TransitionRecipe(id string, caller Caller, status models.RecipeStatus) (models.Recipe, error) {
+	// Verify that the recipe exists and belongs to the caller
+	recipe, err := s.modifiableRecipe(id, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	if !Contains(statusTransitions[recipe.Status], status) {
+		return models.Recipe{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, recipe.Status, status)
+	}
+
+	if status == models.StatusPublished && !isPublishable(recipe.Input()) {
+		return models.Recipe{}, ErrNotPublishable
+	}
+
+	updated, err := s.repository.SetStatus(id, status)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	s.emit(RecipeUpdated, updated)
+	return updated, nil
+}
+
+// setImages replaces the images of a recipe; callers check ownership first
+func (s *RecipeService) setImages(id string, images []models.RecipeImage, coverImageID string) (models.Recipe, error) {
+	return s.repository.SetImages(id, images, coverImageID)
+}
+
+// isPublishable reports whether a recipe has the content required to publish it
+func isPublishable(input models.RecipeInput) bool {
+	return strings.TrimSpace(input.Title) != "" && len(input.Ingredients) > 0 && len(input.Instructions) > 0
+}
+
+// DeleteRecipe moves a recipe to the trash if the caller owns it or is an admin
+func (s *RecipeService) DeleteRecipe(id string, caller Caller) error {
+	// Verify that the recipe exists and belongs to the caller
+	if _, err := s.modifiableRecipe(id, caller); err != nil {
+		return err
+	}
+
+	if err := s.repository.Delete(id); err != nil {
+		return err
+	}
+
+	deleted, err := s.repository.FindDeletedByID(id)
+	if err != nil {
+		return err
+	}
+	s.emit(RecipeDeleted, deleted)
+	return nil
+}
+
+// GetDeletedRecipesByAuthor returns the recipes a user has moved to the trash
+func (s *RecipeService) GetDeletedRecipesByAuthor(authorID string) []models.Recipe {
+	return s.repository.FindDeletedByAuthorID(authorID)
+}
+
+// RestoreRecipe takes a recipe out of the trash if the caller owns it or is an admin
+func (s *RecipeService) RestoreRecipe(id string, caller Caller) (models.Recipe, error) {
+	// Verify that the recipe is in the trash and belongs to the caller; only its owner can see the trash
+	deleted, err := s.repository.FindDeletedByID(id)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	if !caller.CanModify(deleted.AuthorID) {
+		return models.Recipe{}, ErrRecipeNotFound
+	}
+
+	restored, err := s.repository.Restore(id)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	s.emit(RecipeRestored, deleted)
+	return restored, nil
+}
+
+// PurgeDeletedRecipes permanently removes recipes that were moved to the trash before the
+// given time and returns how many were removed
+func (s *RecipeService) PurgeDeletedRecipes(deletedBefore time.Time) int {
+	purged := s.repository.Purge(deletedBefore)
+	for _, recipe := range purged {
+		s.emit(RecipePurged, recipe)
+	}
+	return len(purged)
+}
+
+// GetRevisions returns every revision of a recipe the caller may see, oldest first
+func (s *RecipeService) GetRevisions(id string, caller Caller) ([]models.RecipeRevision, error) {
+	if _, err := s.GetVisibleRecipe(id, caller); err != nil {
+		return nil, err
+	}
+	return s.repository.FindRevisions(id)
+}
+
+// GetRevision returns a single revision of a recipe the caller may see
+func (s *RecipeService) GetRevision(id string, number int, caller Caller) (models.RecipeRevision, error) {
+	if _, err := s.GetVisibleRecipe(id, caller); err != nil {
+		return models.RecipeRevision{}, err
+	}
+	return s.repository.FindRevision(id, number)
 }
 
-// UpdateRecipe modifies an existing recipe
-func (s *RecipeService) UpdateRecipe(id string, input models.RecipeInput) (models.Recipe, error) {
-	return s.repository.Update(id, input)
+// DiffRevisions returns the field-level changes between two revisions of a recipe the caller may see
+func (s *RecipeService) DiffRevisions(id string, from, to int, caller Caller) (models.RevisionDiff, error) {
+	if _, err := s.GetVisibleRecipe(id, caller); err != nil {
+		return models.RevisionDiff{}, err
+	}
+
+	fromRevision, err := s.repository.FindRevision(id, from)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+	toRevision, err := s.repository.FindRevision(id, to)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+
+	return models.RevisionDiff{
+		RecipeID: id,
+		From:     from,
+		To:       to,
+		Changes:  diffRecipes(fromRevision.Snapshot, toRevision.Snapshot),
+	}, nil
+}
+
+// RevertRecipe restores the content of an earlier revision by saving it as a new revision. Ownership
+// is checked first, so other users cannot tell which revisions exist.
+func (s *RecipeService) RevertRecipe(id string, number int, caller Caller) (models.Recipe, error) {
+	if _, err := s.modifiableRecipe(id, caller); err != nil {
+		return models.Recipe{}, err
+	}
+
+	revision, err := s.repository.FindRevision(id, number)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	return s.UpdateRecipe(id, caller, revision.Snapshot.Input())
+}
+
+// GetRecipeTiming returns the active and passive time and the step timers of a recipe the caller may see
+func (s *RecipeService) GetRecipeTiming(id string, caller Caller) (models.RecipeTiming, error) {
+	recipe, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.RecipeTiming{}, err
+	}
+	return recipe.Timing(), nil
+}
+
+// ScaleRecipe returns a copy of a recipe the caller may see with every ingredient quantity
+// multiplied by factor. The stored recipe is never modified.
+func (s *RecipeService) ScaleRecipe(id string, factor models.Quantity, caller Caller) (models.Recipe, error) {
+	if factor.Float64() <= 0 {
+		return models.Recipe{}, ErrInvalidScale
+	}
+
+	recipe, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	scaled := recipe.MapIngredients(func(ingredient models.Ingredient) models.Ingredient {
+		return scaleIngredient(ingredient, factor)
+	})
+	if recipe.Servings > 0 {
+		scaled.Servings = int(math.Round(float64(recipe.Servings) * factor.Float64()))
+	}
+	return scaled, nil
 }
 
-// DeleteRecipe removes a recipe
-func (s *RecipeService) DeleteRecipe(id string) error {
-	return s.repository.Delete(id)
+// ScaleRecipeToServings returns a copy of a recipe the caller may see scaled to make the given number of servings
+func (s *RecipeService) ScaleRecipeToServings(id string, servings int, caller Caller) (models.Recipe, error) {
+	recipe, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+
+	if servings <= 0 || recipe.Servings <= 0 {
+		return models.Recipe{}, ErrInvalidScale
+	}
+
+	scaled, err := s.ScaleRecipe(id, models.NewQuantity(int64(servings), int64(recipe.Servings)), caller)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	scaled.Servings = servings
+	return scaled, nil
 }
 
-// FilterRecipesByTag returns recipes that have the specified tag
+// FilterRecipesByTag returns recipes listed for the caller that have the specified tag
 // This demonstrates a higher-order function that takes a predicate function
-func (s *RecipeService) FilterRecipesByTag(tag string) []models.Recipe {
-	allRecipes := s.repository.FindAll()
+func (s *RecipeService) FilterRecipesByTag(tag string, caller Caller) []models.Recipe {
+	allRecipes := s.GetAllRecipes(caller)
 	return Filter(allRecipes, func(recipe models.Recipe) bool {
 		return Contains(recipe.Tags, tag)
 	})
@@ -84,59 +381,106 @@ const (
 	SortByServings  SortBy = "servings"
 )
 
-// SortRecipes returns recipes sorted by the specified criteria
-func (s *RecipeService) SortRecipes(criteria SortBy, ascending bool) []models.Recipe {
-	allRecipes := s.repository.FindAll()
+// SortKey computes the value a recipe is sorted by for the caller; false means the recipe has
+// no value and is sorted after every recipe that has one
+type SortKey func(recipe models.Recipe, caller Caller) (float64, bool)
+
+// RegisterSortKey adds a sort criteria computed outside the recipe itself, such as nutrition.
+// Sort keys are expected to be registered while services are wired together, before requests are served.
+func (s *RecipeService) RegisterSortKey(criteria SortBy, key SortKey) {
+	s.sortKeys[criteria] = key
+}
+
+// SortRecipes returns the recipes listed for the caller sorted by the specified criteria
+func (s *RecipeService) SortRecipes(criteria SortBy, ascending bool, caller Caller) []models.Recipe {
+	allRecipes := s.GetAllRecipes(caller)
+	if key, ok := s.sortKeys[criteria]; ok {
+		return sortByKey(allRecipes, key, ascending, caller)
+	}
+
+	// Create a copy of the slice to avoid modifying the original
+	result := make([]models.Recipe, len(allRecipes))
+	copy(result, allRecipes)
 
-	// Define a less function based on the sorting criteria
+	// Define a less function based on the sorting criteria; it indexes the
+	// copy being sorted so comparisons follow the swaps made by Sort
 	less := func(i, j int) bool {
 		switch criteria {
 		case SortByPrepTime:
 			if ascending {
-				return allRecipes[i].PrepTime < allRecipes[j].PrepTime
+				return result[i].PrepTime < result[j].PrepTime
 			}
-			return allRecipes[i].PrepTime > allRecipes[j].PrepTime
+			return result[i].PrepTime > result[j].PrepTime
 		case SortByCookTime:
 			if ascending {
-				return allRecipes[i].CookTime < allRecipes[j].CookTime
+				return result[i].CookTime < result[j].CookTime
 			}
-			return allRecipes[i].CookTime > allRecipes[j].CookTime
+			return result[i].CookTime > result[j].CookTime
 		case SortByTotalTime:
-			totalTimeI := allRecipes[i].PrepTime + allRecipes[i].CookTime
-			totalTimeJ := allRecipes[j].PrepTime + allRecipes[j].CookTime
+			totalTimeI := result[i].PrepTime + result[i].CookTime
+			totalTimeJ := result[j].PrepTime + result[j].CookTime
 			if ascending {
 				return totalTimeI < totalTimeJ
 			}
 			return totalTimeI > totalTimeJ
 		case SortByTitle:
 			if ascending {
-				return allRecipes[i].Title < allRecipes[j].Title
+				return result[i].Title < result[j].Title
 			}
-			return allRecipes[i].Title > allRecipes[j].Title
+			return result[i].Title > result[j].Title
 		case SortByServings:
 			if ascending {
-				return allRecipes[i].Servings < allRecipes[j].Servings
+				return result[i].Servings < result[j].Servings
 			}
-			return allRecipes[i].Servings > allRecipes[j].Servings
+			return result[i].Servings > result[j].Servings
 		default:
 			// Default to sorting by title
 			if ascending {
-				return allRecipes[i].Title < allRecipes[j].Title
+				return result[i].Title < result[j].Title
 			}
-			return allRecipes[i].Title > allRecipes[j].Title
+			return result[i].Title > result[j].Title
 		}
 	}
 
-	// Create a copy of the slice to avoid modifying the original
-	result := make([]models.Recipe, len(allRecipes))
-	copy(result, allRecipes)
-
 	// Sort the copy using the less function
 	Sort(result, less)
 
 	return result
 }
 
+// sortByKey returns a copy of recipes sorted by a registered sort key, with recipes
+// that have no value last in either order
+func sortByKey(recipes []models.Recipe, key SortKey, ascending bool, caller Caller) []models.Recipe {
+	type keyedRecipe struct {
+		recipe models.Recipe
+		value  float64
+		ok     bool
+	}
+
+	// Compute each key once rather than on every comparison
+	keyed := make([]keyedRecipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		value, ok := key(recipe, caller)
+		keyed = append(keyed, keyedRecipe{recipe, value, ok})
+	}
+
+	Sort(keyed, func(i, j int) bool {
+		if keyed[i].ok != keyed[j].ok {
+			return keyed[i].ok
+		}
+		if ascending {
+			return keyed[i].value < keyed[j].value
+		}
+		return keyed[i].value > keyed[j].value
+	})
+
+	result := make([]models.Recipe, 0, len(keyed))
+	for _, item := range keyed {
+		result = append(result, item.recipe)
+	}
+	return result
+}
+
 // Sort is a generic function that sorts a slice using the provided less function
 func Sort[T any](items []T, less func(i, j int) bool) {
 	n := len(items)
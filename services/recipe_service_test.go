@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"testing"
 
 	"playground/models"
@@ -19,7 +20,7 @@ func TestCreateRecipe(t *testing.T) {
 	recipeInput := models.RecipeInput{
 		Title:        "Test Recipe",
 		Description:  "A test recipe for unit testing",
-		Ingredients:  []string{"ingredient1", "ingredient2"},
+		Ingredients:  models.ParseIngredients([]string{"ingredient1", "ingredient2"}),
 		Instructions: []string{"step1", "step2"},
 		PrepTime:     10,
 		CookTime:     20,
@@ -27,7 +28,10 @@ func TestCreateRecipe(t *testing.T) {
 		Tags:         []string{"test", "unit-test"},
 	}
 
-	recipe := service.CreateRecipe(recipeInput)
+	recipe, err := service.CreateRecipe("author-1", recipeInput)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
 
 	// Check recipe properties
 	if recipe.Title != recipeInput.Title {
@@ -47,6 +51,364 @@ func TestCreateRecipe(t *testing.T) {
 	}
 }
 
+// createPublishedRecipe creates a complete recipe and publishes it so that every user can see it
+func createPublishedRecipe(t *testing.T, service *RecipeService, authorID string, title string) models.Recipe {
+	t.Helper()
+
+	recipe, _ := service.CreateRecipe(authorID, models.RecipeInput{
+		Title:        title,
+		Ingredients:  models.ParseIngredients([]string{"1 cup water"}),
+		Instructions: []string{"Cook it"},
+	})
+
+	published, err := service.TransitionRecipe(recipe.ID, Caller{UserID: authorID}, models.StatusPublished)
+	if err != nil {
+		t.Fatalf("Expected to publish '%s', but got error: %v", title, err)
+	}
+	return published
+}
+
+// TestRecipeStatus tests the draft, published and archived lifecycle of RecipeService
+func TestRecipeStatus(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the service with the repository
+	service := NewRecipeService(repo)
+
+	author := Caller{UserID: "author-1"}
+	reader := Caller{UserID: "reader-1"}
+	anonymous := Caller{}
+
+	recipe, _ := service.CreateRecipe(author.UserID, models.RecipeInput{Title: "Stew"})
+	if recipe.Status != models.StatusDraft {
+		t.Fatalf("Expected new recipe to be a draft, but got %s", recipe.Status)
+	}
+
+	// Drafts are visible to their author only
+	if _, err := service.GetVisibleRecipe(recipe.ID, author); err != nil {
+		t.Errorf("Expected author to see the draft, but got error: %v", err)
+	}
+	if _, err := service.GetVisibleRecipe(recipe.ID, reader); err != ErrRecipeNotFound {
+		t.Errorf("Expected ErrRecipeNotFound for another user, but got %v", err)
+	}
+	if got := len(service.GetAllRecipes(anonymous)); got != 0 {
+		t.Errorf("Expected no listed recipes for anonymous callers, but got %d", got)
+	}
+	if got := len(service.SortRecipes(SortByTitle, true, author)); got != 1 {
+		t.Errorf("Expected author to list their draft, but got %d recipes", got)
+	}
+
+	// Publishing requires ingredients and instructions
+	if _, err := service.TransitionRecipe(recipe.ID, author, models.StatusPublished); err != ErrNotPublishable {
+		t.Errorf("Expected ErrNotPublishable, but got %v", err)
+	}
+	complete := models.RecipeInput{
+		Title:        "Stew",
+		Ingredients:  models.ParseIngredients([]string{"500 g beef"}),
+		Instructions: []string{"Simmer for two hours"},
+	}
+	if _, err := service.UpdateRecipe(recipe.ID, author, complete); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Only the author can publish; other users cannot see the draft at all
+	if _, err := service.TransitionRecipe(recipe.ID, reader, models.StatusPublished); err != ErrRecipeNotFound {
+		t.Errorf("Expected ErrRecipeNotFound, but got %v", err)
+	}
+	if _, err := service.TransitionRecipe(recipe.ID, author, models.StatusPublished); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if got := len(service.GetAllRecipes(anonymous)); got != 1 {
+		t.Errorf("Expected the published recipe to be listed, but got %d recipes", got)
+	}
+
+	// Published recipes must stay complete
+	if _, err := service.UpdateRecipe(recipe.ID, author, models.RecipeInput{Title: "Stew"}); err != ErrNotPublishable {
+		t.Errorf("Expected ErrNotPublishable, but got %v", err)
+	}
+
+	// Archived recipes leave lists but can still be opened
+	if _, err := service.TransitionRecipe(recipe.ID, author, models.StatusArchived); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if got := len(service.GetAllRecipes(reader)); got != 0 {
+		t.Errorf("Expected archived recipe to be unlisted, but got %d recipes", got)
+	}
+	if _, err := service.GetVisibleRecipe(recipe.ID, reader); err != nil {
+		t.Errorf("Expected archived recipe to be visible by ID, but got error: %v", err)
+	}
+
+	// Drafts cannot be archived directly
+	draft, _ := service.CreateRecipe(author.UserID, complete)
+	if _, err := service.TransitionRecipe(draft.ID, author, models.StatusArchived); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, but got %v", err)
+	}
+}
+
+// TestRecipeOwnership tests that only the author or an admin can modify a recipe
+func TestRecipeOwnership(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the service with the repository
+	service := NewRecipeService(repo)
+
+	recipe, _ := service.CreateRecipe("author-1", models.RecipeInput{Title: "Owned Recipe"})
+
+	if recipe.AuthorID != "author-1" {
+		t.Fatalf("Expected author ID author-1, but got %s", recipe.AuthorID)
+	}
+
+	// Test cases
+	tests := []struct {
+		name          string
+		caller        Caller
+		shouldSucceed bool
+	}{
+		{"Author", Caller{UserID: "author-1", Role: "user"}, true},
+		{"Other user", Caller{UserID: "author-2", Role: "user"}, false},
+		{"Admin", Caller{UserID: "admin-1", Role: AdminRole}, true},
+		{"Anonymous", Caller{}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			updated, err := service.UpdateRecipe(recipe.ID, tc.caller, models.RecipeInput{Title: "Edited by " + tc.name})
+
+			if tc.shouldSucceed {
+				if err != nil {
+					t.Fatalf("Expected update to succeed, but got error: %v", err)
+				}
+				if updated.AuthorID != "author-1" {
+					t.Errorf("Expected update to keep author author-1, but got %s", updated.AuthorID)
+				}
+			} else if err != ErrRecipeNotFound {
+				// The recipe is a draft, so other users cannot tell it exists
+				t.Errorf("Expected ErrRecipeNotFound, but got %v", err)
+			}
+		})
+	}
+
+	// Deleting as another user should fail and keep the recipe
+	if err := service.DeleteRecipe(recipe.ID, Caller{UserID: "author-2"}); err != ErrRecipeNotFound {
+		t.Errorf("Expected ErrRecipeNotFound, but got %v", err)
+	}
+	if _, err := service.GetRecipeByID(recipe.ID); err != nil {
+		t.Errorf("Expected recipe to still exist, but got error: %v", err)
+	}
+
+	// Deleting as the author should succeed
+	if err := service.DeleteRecipe(recipe.ID, Caller{UserID: "author-1"}); err != nil {
+		t.Errorf("Expected delete to succeed, but got error: %v", err)
+	}
+
+	// A published recipe is visible to everyone but still only modifiable by its author
+	published := createPublishedRecipe(t, service, "author-1", "Published Recipe")
+	other := Caller{UserID: "author-2"}
+	if _, err := service.UpdateRecipe(published.ID, other, published.Input()); err != ErrNotRecipeOwner {
+		t.Errorf("Expected ErrNotRecipeOwner for an update, but got %v", err)
+	}
+	if _, err := service.TransitionRecipe(published.ID, other, models.StatusArchived); err != ErrNotRecipeOwner {
+		t.Errorf("Expected ErrNotRecipeOwner for a transition, but got %v", err)
+	}
+	if _, err := service.RevertRecipe(published.ID, 1, other); err != ErrNotRecipeOwner {
+		t.Errorf("Expected ErrNotRecipeOwner for a revert, but got %v", err)
+	}
+	if err := service.DeleteRecipe(published.ID, other); err != ErrNotRecipeOwner {
+		t.Errorf("Expected ErrNotRecipeOwner for a delete, but got %v", err)
+	}
+}
+
+// TestGetRecipesByAuthor tests the GetRecipesByAuthor function of RecipeService
+func TestGetRecipesByAuthor(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the service with the repository
+	service := NewRecipeService(repo)
+
+	service.CreateRecipe("author-1", models.RecipeInput{Title: "Recipe 1"})
+	service.CreateRecipe("author-1", models.RecipeInput{Title: "Recipe 2"})
+	service.CreateRecipe("author-2", models.RecipeInput{Title: "Recipe 3"})
+
+	tests := []struct {
+		authorID      string
+		expectedCount int
+	}{
+		{"author-1", 2},
+		{"author-2", 1},
+		{"author-3", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.authorID, func(t *testing.T) {
+			results := service.GetRecipesByAuthor(tc.authorID, Caller{UserID: tc.authorID})
+
+			if len(results) != tc.expectedCount {
+				t.Errorf("Expected %d recipes by '%s', but got %d",
+					tc.expectedCount, tc.authorID, len(results))
+			}
+		})
+	}
+}
+
+// TestRecipeRevisions tests revision history, diffs and reverts in RecipeService
+func TestRecipeRevisions(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the service with the repository
+	service := NewRecipeService(repo)
+
+	author := Caller{UserID: "author-1"}
+	recipe, _ := service.CreateRecipe(author.UserID, models.RecipeInput{Title: "Soup", Servings: 2})
+	if _, err := service.UpdateRecipe(recipe.ID, author, models.RecipeInput{Title: "Tomato Soup", Servings: 2}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := service.UpdateRecipe(recipe.ID, author, models.RecipeInput{Title: "Tomato Soup", Servings: 4}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	revisions, err := service.GetRevisions(recipe.ID, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("Expected 3 revisions, but got %d", len(revisions))
+	}
+	for i, revision := range revisions {
+		if revision.Number != i+1 || revision.EditorID != author.UserID {
+			t.Errorf("Expected revision %d by %s, but got revision %d by %s",
+				i+1, author.UserID, revision.Number, revision.EditorID)
+		}
+	}
+
+	// Diff between the first and last revision
+	diff, err := service.DiffRevisions(recipe.ID, 1, 3, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(diff.Changes) != 2 || diff.Changes[0].Field != "title" || diff.Changes[1].Field != "servings" {
+		t.Errorf("Expected changes to title and servings, but got %+v", diff.Changes)
+	}
+
+	// Reverting as another user should fail without telling whether the draft or revision exists
+	if _, err := service.RevertRecipe(recipe.ID, 1, Caller{UserID: "author-2"}); err != ErrRecipeNotFound {
+		t.Errorf("Expected ErrRecipeNotFound, but got %v", err)
+	}
+	if _, err := service.RevertRecipe(recipe.ID, 99, Caller{UserID: "author-2"}); err != ErrRecipeNotFound {
+		t.Errorf("Expected ErrRecipeNotFound for a missing revision, but got %v", err)
+	}
+
+	// Reverting creates a new revision with the old content
+	reverted, err := service.RevertRecipe(recipe.ID, 1, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if reverted.Revision != 4 || reverted.Title != "Soup" || reverted.Servings != 2 {
+		t.Errorf("Expected revision 4 titled 'Soup' for 2 servings, but got revision %d titled '%s' for %d servings",
+			reverted.Revision, reverted.Title, reverted.Servings)
+	}
+
+	// Older revisions stay untouched
+	third, err := service.GetRevision(recipe.ID, 3, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if third.Snapshot.Title != "Tomato Soup" {
+		t.Errorf("Expected revision 3 to keep title 'Tomato Soup', but got '%s'", third.Snapshot.Title)
+	}
+
+	if _, err := service.GetRevision(recipe.ID, 9, author); err == nil {
+		t.Error("Expected error for missing revision, but got none")
+	}
+}
+
+// TestScaleRecipe tests the ScaleRecipe and ScaleRecipeToServings functions of RecipeService
+func TestScaleRecipe(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the service with the repository
+	service := NewRecipeService(repo)
+
+	author := Caller{UserID: "author-1"}
+	recipe, _ := service.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:    "Cookies",
+		Servings: 4,
+		Ingredients: models.ParseIngredients([]string{
+			"1 cup sugar",
+			"16 tsp butter",
+			"1 tbsp vanilla",
+			"750 g flour",
+			"2 eggs",
+			"salt to taste",
+		}),
+	})
+
+	tests := []struct {
+		name     string
+		scale    func() (models.Recipe, error)
+		servings int
+		expected []string
+	}{
+		{
+			"Double by servings",
+			func() (models.Recipe, error) { return service.ScaleRecipeToServings(recipe.ID, 8, author) },
+			8,
+			[]string{"2 cups sugar", "2/3 cup butter", "2 tbsp vanilla", "1 1/2 kg flour", "4 eggs", "salt to taste"},
+		},
+		{
+			"Triple by factor",
+			func() (models.Recipe, error) { return service.ScaleRecipe(recipe.ID, models.WholeQuantity(3), author) },
+			12,
+			[]string{"3 cups sugar", "1 cup butter", "3 tbsp vanilla", "2 1/4 kg flour", "6 eggs", "salt to taste"},
+		},
+		{
+			"Third by decimal factor",
+			func() (models.Recipe, error) {
+				return service.ScaleRecipe(recipe.ID, models.QuantityFromFloat(0.333), author)
+			},
+			1,
+			[]string{"1/3 cup sugar", "1 3/4 tbsp butter", "1 tsp vanilla", "250 g flour", "2/3 eggs", "salt to taste"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scaled, err := tc.scale()
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if scaled.Servings != tc.servings {
+				t.Errorf("Expected %d servings, but got %d", tc.servings, scaled.Servings)
+			}
+
+			for i, line := range tc.expected {
+				if got := scaled.Ingredients[i].String(); got != line {
+					t.Errorf("Expected ingredient %d to be '%s', but got '%s'", i, line, got)
+				}
+				if got := scaled.IngredientSections[0].Ingredients[i].String(); got != line {
+					t.Errorf("Expected sectioned ingredient %d to be '%s', but got '%s'", i, line, got)
+				}
+			}
+		})
+	}
+
+	// The stored recipe must not change
+	stored, _ := service.GetRecipeByID(recipe.ID)
+	if got := stored.Ingredients[0].String(); got != "1 cup sugar" || stored.Servings != 4 {
+		t.Errorf("Expected stored recipe to be unchanged, but got '%s' for %d servings", got, stored.Servings)
+	}
+
+	// Invalid scales should fail
+	if _, err := service.ScaleRecipeToServings(recipe.ID, 0, author); err != ErrInvalidScale {
+		t.Errorf("Expected ErrInvalidScale, but got %v", err)
+	}
+}
+
 // TestFilterRecipesByTag tests the FilterRecipesByTag function of RecipeService
 func TestFilterRecipesByTag(t *testing.T) {
 	// Create a repository
@@ -72,9 +434,9 @@ func TestFilterRecipesByTag(t *testing.T) {
 	}
 
 	// Add recipes to repository
-	service.CreateRecipe(recipe1)
-	service.CreateRecipe(recipe2)
-	service.CreateRecipe(recipe3)
+	service.CreateRecipe("author-1", recipe1)
+	service.CreateRecipe("author-1", recipe2)
+	service.CreateRecipe("author-1", recipe3)
 
 	// Test filtering by tag
 	tests := []struct {
@@ -90,7 +452,7 @@ func TestFilterRecipesByTag(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.tag, func(t *testing.T) {
-			results := service.FilterRecipesByTag(tc.tag)
+			results := service.FilterRecipesByTag(tc.tag, Caller{UserID: "author-1"})
 
 			if len(results) != tc.expectedCount {
 				t.Errorf("Expected %d recipes with tag '%s', but got %d",
@@ -163,9 +525,9 @@ func TestSortRecipes(t *testing.T) {
 	}
 
 	// Add recipes to repository
-	service.CreateRecipe(recipe1)
-	service.CreateRecipe(recipe2)
-	service.CreateRecipe(recipe3)
+	service.CreateRecipe("author-1", recipe1)
+	service.CreateRecipe("author-1", recipe2)
+	service.CreateRecipe("author-1", recipe3)
 
 	// Test sorting by different criteria
 	tests := []struct {
@@ -188,7 +550,7 @@ func TestSortRecipes(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			results := service.SortRecipes(tc.criteria, tc.ascending)
+			results := service.SortRecipes(tc.criteria, tc.ascending, Caller{UserID: "author-1"})
 
 			if len(results) != 3 {
 				t.Fatalf("Expected 3 recipes, but got %d", len(results))
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"math"
+
+	"playground/models"
+)
+
+// ladderStep is a unit in a family of related units, with its size in the family's
+// smallest unit and the smallest amount that still reads naturally in that unit
+type ladderStep struct {
+	unit    string
+	size    models.Quantity
+	minimum float64
+}
+
+// unitLadders lists the families of units that scaling may promote or demote between,
+// ordered from the smallest unit to the largest
+var unitLadders = [][]ladderStep{
+	{
+		{models.UnitTeaspoon, models.WholeQuantity(1), 0},
+		{models.UnitTablespoon, models.WholeQuantity(3), 1},
+		{models.UnitCup, models.WholeQuantity(48), 0.25},
+	},
+	{
+		{models.UnitMilliliter, models.WholeQuantity(1), 0},
+		{models.UnitLiter, models.WholeQuantity(1000), 1},
+	},
+	{
+		{models.UnitGram, models.WholeQuantity(1), 0},
+		{models.UnitKilogram, models.WholeQuantity(1000), 1},
+	},
+	{
+		{models.UnitOunce, models.WholeQuantity(1), 0},
+		{models.UnitPound, models.WholeQuantity(16), 1},
+	},
+}
+
+// metricUnits are rounded to whole numbers once amounts get large
+var metricUnits = map[string]bool{
+	models.UnitMilliliter: true,
+	models.UnitGram:       true,
+}
+
+// niceDenominators are the fractions cooks expect to read, in order of preference
+var niceDenominators = []int64{1, 2, 3, 4, 8}
+
+// scaleIngredient multiplies the ingredient quantity by factor, then picks a readable unit and amount
+func scaleIngredient(ingredient models.Ingredient, factor models.Quantity) models.Ingredient {
+	if ingredient.Quantity == nil {
+		return ingredient
+	}
+
+	amount, unit := rebalanceUnit(ingredient.Quantity.Mul(factor), ingredient.Unit)
+	amount = roundQuantity(amount, unit)
+	ingredient.Quantity = &amount
+	ingredient.Unit = unit
+	return ingredient
+}
+
+// rebalanceUnit moves an amount to the largest unit of its family in which it still reads naturally,
+// e.g. 48 tsp becomes 1 cup and 1/2 tbsp becomes 1 1/2 tsp
+func rebalanceUnit(amount models.Quantity, unit string) (models.Quantity, string) {
+	for _, ladder := range unitLadders {
+		var base models.Quantity
+		found := false
+		for _, step := range ladder {
+			if step.unit == unit {
+				base = amount.Mul(step.size)
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		for i := len(ladder) - 1; i >= 0; i-- {
+			step := ladder[i]
+			converted := base.Mul(models.NewQuantity(step.size.Den, step.size.Num))
+			if converted.Float64() >= step.minimum {
+				return converted, step.unit
+			}
+		}
+	}
+	return amount, unit
+}
+
+// roundQuantity snaps an amount to a fraction a cook can measure, such as 0.333 to 1/3
+func roundQuantity(amount models.Quantity, unit string) models.Quantity {
+	value := amount.Float64()
+	if value <= 0 {
+		return amount
+	}
+
+	if metricUnits[unit] && value >= 10 {
+		return models.WholeQuantity(int64(math.Round(value)))
+	}
+
+	// Prefer the simplest fraction within 2% of the exact value, else the closest one
+	var best models.Quantity
+	bestErr := math.Inf(1)
+	for _, den := range niceDenominators {
+		num := int64(math.Round(value * float64(den)))
+		if num == 0 {
+			continue
+		}
+		candidate := models.NewQuantity(num, den)
+		err := math.Abs(candidate.Float64() - value)
+		if err <= value*0.02 {
+			return candidate
+		}
+		if err < bestErr {
+			best, bestErr = candidate, err
+		}
+	}
+
+	if bestErr == math.Inf(1) {
+		// Too small for any nice fraction; keep the exact amount
+		return models.QuantityFromFloat(value)
+	}
+	return best
+}
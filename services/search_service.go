@@ -2,27 +2,32 @@ package services
 
 import (
 	"playground/models"
+	"sort"
 	"strings"
 )
 
 // SearchService handles search functionality for recipes
 type SearchService struct {
-	recipeService *RecipeService
+	recipeService    *RecipeService
+	nutritionService *NutritionService
+	pantryService    *PantryService
 }
 
-// NewSearchService creates a new search service with the given recipe service
-func NewSearchService(recipeService *RecipeService) *SearchService {
+// NewSearchService creates a new search service with the given recipe, nutrition and pantry services
+func NewSearchService(recipeService *RecipeService, nutritionService *NutritionService, pantryService *PantryService) *SearchService {
 	return &SearchService{
-		recipeService: recipeService,
+		recipeService:    recipeService,
+		nutritionService: nutritionService,
+		pantryService:    pantryService,
 	}
 }
 
-// SearchByIngredient returns recipes that contain the specified ingredient
-func (s *SearchService) SearchByIngredient(ingredient string) []models.Recipe {
-	allRecipes := s.recipeService.GetAllRecipes()
+// SearchByIngredient returns recipes with an ingredient whose name contains the specified text
+func (s *SearchService) SearchByIngredient(ingredient string, caller Caller) []models.Recipe {
+	allRecipes := s.recipeService.GetAllRecipes(caller)
 	return Filter(allRecipes, func(recipe models.Recipe) bool {
 		for _, ing := range recipe.Ingredients {
-			if strings.Contains(strings.ToLower(ing), strings.ToLower(ingredient)) {
+			if strings.Contains(strings.ToLower(ing.Name), strings.ToLower(ingredient)) {
 				return true
 			}
 		}
@@ -31,26 +36,83 @@ func (s *SearchService) SearchByIngredient(ingredient string) []models.Recipe {
 }
 
 // SearchByTag returns recipes that have the specified tag
-func (s *SearchService) SearchByTag(tag string) []models.Recipe {
-	return s.recipeService.FilterRecipesByTag(tag)
+func (s *SearchService) SearchByTag(tag string, caller Caller) []models.Recipe {
+	return s.recipeService.FilterRecipesByTag(tag, caller)
 }
 
 // SearchByTitle returns recipes that contain the specified title
-func (s *SearchService) SearchByTitle(title string) []models.Recipe {
-	allRecipes := s.recipeService.GetAllRecipes()
+func (s *SearchService) SearchByTitle(title string, caller Caller) []models.Recipe {
+	allRecipes := s.recipeService.GetAllRecipes(caller)
 	return Filter(allRecipes, func(recipe models.Recipe) bool {
 		return strings.Contains(strings.ToLower(recipe.Title), strings.ToLower(title))
 	})
 }
 
+// SearchByCalories returns recipes with between min and max calories per serving, inclusive.
+// Recipes whose calories cannot be computed are left out.
+func (s *SearchService) SearchByCalories(min, max float64, caller Caller) []models.Recipe {
+	allRecipes := s.recipeService.GetAllRecipes(caller)
+	return Filter(allRecipes, func(recipe models.Recipe) bool {
+		calories, ok := s.nutritionService.CaloriesPerServing(recipe, caller)
+		return ok && calories >= min && calories <= max
+	})
+}
+
+// SearchByDiet returns recipes that carry every given dietary label and contain none of the
+// excluded allergens, as derived from their ingredients
+func (s *SearchService) SearchByDiet(labels []models.DietaryLabel, excluded []models.Allergen, caller Caller) []models.Recipe {
+	allRecipes := s.recipeService.GetAllRecipes(caller)
+	return Filter(allRecipes, func(recipe models.Recipe) bool {
+		for _, label := range labels {
+			if !recipe.Diet.HasLabel(label) {
+				return false
+			}
+		}
+		for _, allergen := range excluded {
+			if recipe.Diet.HasAllergen(allergen) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// SearchByPantry ranks the recipes the caller may see by how much of their ingredient list the caller's
+// pantry covers as of today, boosting recipes that use up items nearing expiry. Recipes that use nothing
+// from the pantry are left out, as are recipes missing more than maxMissing ingredients unless
+// maxMissing is negative.
+func (s *SearchService) SearchByPantry(caller Caller, maxMissing int, today models.Date) []models.PantryMatch {
+	pantry := s.pantryService.GetPantry(caller)
+	matches := make([]models.PantryMatch, 0)
+	for _, recipe := range s.recipeService.GetAllRecipes(caller) {
+		ingredients := s.recipeService.ExpandIngredients(recipe, caller)
+		match := s.pantryService.matchRecipe(recipe, ingredients, pantry, today)
+		if (match.Coverage == 0 && len(match.Expiring) == 0) || (maxMissing >= 0 && len(match.Missing) > maxMissing) {
+			continue
+		}
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if len(matches[i].Missing) != len(matches[j].Missing) {
+			return len(matches[i].Missing) < len(matches[j].Missing)
+		}
+		return matches[i].Title < matches[j].Title
+	})
+	return matches
+}
+
 // GetPaginatedRecipes returns a paginated list of recipes
-func (s *SearchService) GetPaginatedRecipes(page, pageSize int) []models.Recipe {
-	allRecipes := s.recipeService.GetAllRecipes()
-	
+func (s *SearchService) GetPaginatedRecipes(page, pageSize int, caller Caller) []models.Recipe {
+	allRecipes := s.recipeService.GetAllRecipes(caller)
+
 	// Calculate start and end indices
 	start := (page - 1) * pageSize
 	end := start + pageSize
-	
+
 	// Check bounds
 	if start >= len(allRecipes) {
 		return []models.Recipe{}
@@ -58,6 +120,6 @@ func (s *SearchService) GetPaginatedRecipes(page, pageSize int) []models.Recipe
 	if end > len(allRecipes) {
 		end = len(allRecipes)
 	}
-	
+
 	return allRecipes[start:end]
-}
\ No newline at end of file
+}
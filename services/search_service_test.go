@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestSearchByIngredient tests the SearchByIngredient function of SearchService
+func TestSearchByIngredient(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the services with the repository
+	recipeService := NewRecipeService(repo)
+	service := NewSearchService(recipeService, nil, nil)
+
+	// Create test recipes with free-text ingredient lines
+	recipeService.CreateRecipe("author-1", models.RecipeInput{
+		Title:       "Bread",
+		Ingredients: models.ParseIngredients([]string{"3 cups flour, sifted", "1 tsp salt"}),
+	})
+	recipeService.CreateRecipe("author-1", models.RecipeInput{
+		Title:       "Omelette",
+		Ingredients: models.ParseIngredients([]string{"3 eggs", "1 pinch salt", "2 tbsp milk"}),
+	})
+
+	// Test searching by ingredient name
+	tests := []struct {
+		ingredient    string
+		expectedCount int
+	}{
+		{"flour", 1},
+		{"SALT", 2},
+		{"egg", 1},
+		{"sifted", 0}, // preparation notes are not part of the name
+		{"cups", 0},   // units are not part of the name
+		{"tomato", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ingredient, func(t *testing.T) {
+			results := service.SearchByIngredient(tc.ingredient, Caller{UserID: "author-1"})
+
+			if len(results) != tc.expectedCount {
+				t.Errorf("Expected %d recipes with ingredient '%s', but got %d",
+					tc.expectedCount, tc.ingredient, len(results))
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"playground/models"
+	"playground/repositories"
+)
+
+// DefaultShareLinkLifetime is how long a share link stays valid when no lifetime is requested
+const DefaultShareLinkLifetime = 7 * 24 * time.Hour
+
+// MaxShareLinkLifetime is the longest lifetime a share link can be created with
+const MaxShareLinkLifetime = 90 * 24 * time.Hour
+
+// ErrInvalidShareLifetime is returned when a share link lifetime is not positive or too long
+var ErrInvalidShareLifetime = errors.New("invalid share link lifetime: must be positive and at most 90 days")
+
+// ErrInvalidShareLink is returned when a share token is malformed, expired, revoked or its recipe is gone
+var ErrInvalidShareLink = errors.New("share link is invalid or has expired")
+
+// ShareClaims represents the claims of a share link token. The token ID is the share link ID
+// and the subject is the shared recipe ID.
+type ShareClaims struct {
+	jwt.RegisteredClaims
+}
+
+// ShareService handles business logic for recipe share links
+type ShareService struct {
+	repository    repositories.ShareLinkRepository
+	recipeService *RecipeService
+	// secret signs share tokens; it is kept apart from jwtSecret so a share token can never pass as a login token
+	secret []byte
+}
+
+// NewShareService creates a new share service with the given repository, signing share links with secret
+func NewShareService(repository repositories.ShareLinkRepository, recipeService *RecipeService, secret []byte) *ShareService {
+	service := &ShareService{
+		repository:    repository,
+		recipeService: recipeService,
+		secret:        secret,
+	}
+
+	// Share links disappear with their recipe
+	recipeService.On(RecipePurged, func(recipe models.Recipe) {
+		repository.DeleteByRecipeID(recipe.ID)
+	})
+
+	return service
+}
+
+// CreateShareLink mints a signed, expiring share link for a recipe the caller owns
+func (s *ShareService) CreateShareLink(recipeID string, caller Caller, lifetime time.Duration) (models.ShareLink, error) {
+	if lifetime == 0 {
+		lifetime = DefaultShareLinkLifetime
+	}
+	if lifetime < 0 || lifetime > MaxShareLinkLifetime {
+		return models.ShareLink{}, ErrInvalidShareLifetime
+	}
+
+	// Verify that the recipe exists and belongs to the caller
+	recipe, err := s.recipeService.modifiableRecipe(recipeID, caller)
+	if err != nil {
+		return models.ShareLink{}, err
+	}
+
+	link := s.repository.Create(recipe.ID, caller.UserID, time.Now().Add(lifetime))
+
+	// Create token claims
+	claims := ShareClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        link.ID,
+			Subject:   link.RecipeID,
+			ExpiresAt: jwt.NewNumericDate(link.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(link.CreatedAt),
+		},
+	}
+
+	// Create and sign token
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	link.Token, err = token.SignedString(s.secret)
+	if err != nil {
+		return models.ShareLink{}, err
+	}
+
+	return link, nil
+}
+
+// GetShareLinks returns every share link of a recipe the caller owns
+func (s *ShareService) GetShareLinks(recipeID string, caller Caller) ([]models.ShareLink, error) {
+	// Verify that the recipe exists and belongs to the caller
+	recipe, err := s.recipeService.modifiableRecipe(recipeID, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.FindByRecipeID(recipe.ID), nil
+}
+
+// RevokeShareLink stops a share link of a recipe the caller owns from granting access
+func (s *ShareService) RevokeShareLink(recipeID string, linkID string, caller Caller) (models.ShareLink, error) {
+	// Verify that the recipe exists and belongs to the caller
+	recipe, err := s.recipeService.modifiableRecipe(recipeID, caller)
+	if err != nil {
+		return models.ShareLink{}, err
+	}
+
+	link, err := s.repository.FindByID(linkID)
+	if err != nil || link.RecipeID != recipe.ID {
+		return models.ShareLink{}, errors.New("share link not found")
+	}
+
+	return s.repository.Revoke(link.ID)
+}
+
+// ResolveShareLink returns the recipe a share token grants access to. The recipe is returned
+// whatever its status or visibility, as long as the link is active and the recipe is not in the trash.
+func (s *ShareService) ResolveShareLink(tokenString string) (models.Recipe, error) {
+	// Parse token
+	token, err := jwt.ParseWithClaims(tokenString, &ShareClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return models.Recipe{}, ErrInvalidShareLink
+	}
+
+	claims, ok := token.Claims.(*ShareClaims)
+	if !ok {
+		return models.Recipe{}, ErrInvalidShareLink
+	}
+
+	// The stored link decides whether the token was revoked
+	link, err := s.repository.FindByID(claims.ID)
+	if err != nil || link.RecipeID != claims.Subject || !link.IsActive(time.Now()) {
+		return models.Recipe{}, ErrInvalidShareLink
+	}
+
+	recipe, err := s.recipeService.GetRecipeByID(link.RecipeID)
+	if err != nil {
+		return models.Recipe{}, ErrInvalidShareLink
+	}
+
+	return recipe, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestRecipeVisibility tests that private and unlisted recipes stay out of lists and searches
+func TestRecipeVisibility(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the services with the repository
+	recipeService := NewRecipeService(repo)
+	searchService := NewSearchService(recipeService, nil, nil)
+
+	author := Caller{UserID: "author-1"}
+	reader := Caller{UserID: "reader-1"}
+
+	public := createPublishedRecipe(t, recipeService, author.UserID, "Public Pie")
+	if public.Visibility != models.VisibilityPublic {
+		t.Fatalf("Expected new recipes to be public, but got %s", public.Visibility)
+	}
+
+	tests := []struct {
+		visibility models.RecipeVisibility
+		viewable   bool
+	}{
+		{models.VisibilityUnlisted, true},
+		{models.VisibilityPrivate, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.visibility), func(t *testing.T) {
+			recipe := createPublishedRecipe(t, recipeService, author.UserID, "Hidden Pie")
+			input := recipe.Input()
+			input.Visibility = tc.visibility
+			if _, err := recipeService.UpdateRecipe(recipe.ID, author, input); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			_, err := recipeService.GetVisibleRecipe(recipe.ID, reader)
+			if viewable := err == nil; viewable != tc.viewable {
+				t.Errorf("Expected viewable to be %v for another user, but got error: %v", tc.viewable, err)
+			}
+			if _, err := recipeService.GetVisibleRecipe(recipe.ID, author); err != nil {
+				t.Errorf("Expected author to see the recipe, but got error: %v", err)
+			}
+		})
+	}
+
+	// Only the public recipe is listed or found by other users
+	if got := len(recipeService.GetAllRecipes(reader)); got != 1 {
+		t.Errorf("Expected 1 listed recipe, but got %d", got)
+	}
+	if got := len(searchService.SearchByTitle("pie", reader)); got != 1 {
+		t.Errorf("Expected 1 search result, but got %d", got)
+	}
+	if got := len(searchService.SearchByTitle("pie", author)); got != 3 {
+		t.Errorf("Expected author to find all 3 recipes, but got %d", got)
+	}
+
+	// Reverting keeps the current visibility
+	reverted, err := recipeService.RevertRecipe(public.ID, 1, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if reverted.Visibility != models.VisibilityPublic {
+		t.Errorf("Expected visibility to survive a revert, but got %s", reverted.Visibility)
+	}
+}
+
+// TestShareLinks tests minting, resolving and revoking share links for a private recipe
+func TestShareLinks(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	shareRepo := repositories.NewInMemoryShareLinkRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	service := NewShareService(shareRepo, recipeService, []byte("test-share-link-secret"))
+
+	author := Caller{UserID: "author-1"}
+	recipe, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:      "Secret Sauce",
+		Visibility: models.VisibilityPrivate,
+	})
+
+	// Only the author can share, and to others the private recipe does not exist
+	if _, err := service.CreateShareLink(recipe.ID, Caller{UserID: "reader-1"}, 0); err != ErrRecipeNotFound {
+		t.Errorf("Expected ErrRecipeNotFound, but got %v", err)
+	}
+	if _, err := service.CreateShareLink(recipe.ID, author, 365*24*time.Hour); err != ErrInvalidShareLifetime {
+		t.Errorf("Expected ErrInvalidShareLifetime, but got %v", err)
+	}
+
+	link, err := service.CreateShareLink(recipe.ID, author, time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if link.Token == "" {
+		t.Fatal("Expected the new link to carry a token")
+	}
+
+	// The token opens the private recipe without an account
+	shared, err := service.ResolveShareLink(link.Token)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if shared.ID != recipe.ID {
+		t.Errorf("Expected recipe %s, but got %s", recipe.ID, shared.ID)
+	}
+
+	// Tampered tokens are rejected
+	if _, err := service.ResolveShareLink(link.Token + "x"); err != ErrInvalidShareLink {
+		t.Errorf("Expected ErrInvalidShareLink for a tampered token, but got %v", err)
+	}
+	otherServer := NewShareService(shareRepo, recipeService, []byte("another-share-link-secret"))
+	if _, err := otherServer.ResolveShareLink(link.Token); err != ErrInvalidShareLink {
+		t.Errorf("Expected ErrInvalidShareLink for a token signed with another secret, but got %v", err)
+	}
+
+	// Stored links never expose their token
+	links, err := service.GetShareLinks(recipe.ID, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(links) != 1 || links[0].Token != "" {
+		t.Errorf("Expected 1 stored link without a token, but got %+v", links)
+	}
+
+	// Revoked links stop working
+	if _, err := service.RevokeShareLink(recipe.ID, link.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := service.ResolveShareLink(link.Token); err != ErrInvalidShareLink {
+		t.Errorf("Expected ErrInvalidShareLink for a revoked link, but got %v", err)
+	}
+
+	// Links stop working while their recipe is in the trash
+	other, _ := service.CreateShareLink(recipe.ID, author, time.Hour)
+	if err := recipeService.DeleteRecipe(recipe.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := service.ResolveShareLink(other.Token); err != ErrInvalidShareLink {
+		t.Errorf("Expected ErrInvalidShareLink for a deleted recipe, but got %v", err)
+	}
+}
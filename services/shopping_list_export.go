@@ -0,0 +1,49 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"playground/models"
+)
+
+// WriteShoppingListText writes a shopping list as plain text, one line per item under a heading for
+// each aisle, with checked items marked [x]
+func WriteShoppingListText(w io.Writer, list models.ShoppingList) error {
+	buffered := bufio.NewWriter(w)
+	fmt.Fprintln(buffered, list.Name)
+
+	var category models.AisleCategory
+	for i, item := range list.Items {
+		if i == 0 || item.Category != category {
+			category = item.Category
+			fmt.Fprintf(buffered, "\n%s\n", category.Label())
+		}
+
+		mark := " "
+		if item.Checked {
+			mark = "x"
+		}
+		fmt.Fprintf(buffered, "[%s] %s\n", mark, item.Ingredient())
+	}
+	return buffered.Flush()
+}
+
+// WriteShoppingListCSV writes a shopping list as CSV with a header row and one row per item
+func WriteShoppingListCSV(w io.Writer, list models.ShoppingList) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"category", "quantity", "unit", "name", "checked"})
+	for _, item := range list.Items {
+		quantity := ""
+		if item.Quantity != nil {
+			quantity = item.Quantity.String()
+		}
+		writer.Write([]string{string(item.Category), quantity, item.Unit, item.Name, strconv.FormatBool(item.Checked)})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"playground/models"
+	"playground/repositories"
+)
+
+// defaultShoppingListName names shopping lists created without a name
+const defaultShoppingListName = "Shopping list"
+
+// ErrShoppingListNotFound is returned when a shopping list does not exist or belongs to another user
+var ErrShoppingListNotFound = errors.New("shopping list not found")
+
+// ErrShoppingListItemNotFound is returned when a shopping list has no item with the given ID
+var ErrShoppingListItemNotFound = errors.New("shopping list item not found")
+
+// ErrInvalidShoppingList is returned when a shopping list has no recipes to shop for or negative servings
+var ErrInvalidShoppingList = errors.New("shopping list needs at least one recipe or planned meal, with servings of zero or more")
+
+// ShoppingListService handles business logic for shopping lists
+type ShoppingListService struct {
+	repository        repositories.ShoppingListRepository
+	recipeService     *RecipeService
+	mealPlanService   *MealPlanService
+	conversionService *ConversionService
+	// mutex serializes changes to item lists, which are read, changed and written back
+	mutex sync.Mutex
+}
+
+// NewShoppingListService creates a new shopping list service with the given repository
+func NewShoppingListService(repository repositories.ShoppingListRepository, recipeService *RecipeService, mealPlanService *MealPlanService, conversionService *ConversionService) *ShoppingListService {
+	return &ShoppingListService{
+		repository:        repository,
+		recipeService:     recipeService,
+		mealPlanService:   mealPlanService,
+		conversionService: conversionService,
+	}
+}
+
+// shoppingRecipe is a recipe to shop for, multiplied by factor
+type shoppingRecipe struct {
+	recipe models.Recipe
+	factor models.Quantity
+}
+
+// GetShoppingLists returns the caller's shopping lists, newest first
+func (s *ShoppingListService) GetShoppingLists(caller Caller) []models.ShoppingList {
+	lists := s.repository.FindByOwnerID(caller.UserID)
+	Sort(lists, func(i, j int) bool {
+		return lists[i].CreatedAt.After(lists[j].CreatedAt)
+	})
+	return lists
+}
+
+// GetShoppingList returns a shopping list the caller owns
+func (s *ShoppingListService) GetShoppingList(id string, caller Caller) (models.ShoppingList, error) {
+	list, err := s.repository.FindByID(id)
+	if err != nil || !caller.CanModify(list.OwnerID) {
+		return models.ShoppingList{}, ErrShoppingListNotFound
+	}
+	return list, nil
+}
+
+// CreateShoppingList builds a shopping list owned by the caller from recipes the caller may see and
+// the meals planned in one of the caller's meal plans. The ingredients of every recipe, including its
+// sub-recipes, are scaled to the servings asked for and merged into one item per ingredient.
+func (s *ShoppingListService) CreateShoppingList(caller Caller, input models.ShoppingListInput) (models.ShoppingList, error) {
+	recipes, err := s.shoppingRecipes(caller, input)
+	if err != nil {
+		return models.ShoppingList{}, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = defaultShoppingListName
+	}
+	return s.repository.Create(caller.UserID, name, s.buildItems(recipes, caller)), nil
+}
+
+// CheckItem checks an item off a shopping list the caller owns, or unchecks it
+func (s *ShoppingListService) CheckItem(id string, itemID string, caller Caller, input models.ShoppingListItemInput) (models.ShoppingListItem, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list, err := s.GetShoppingList(id, caller)
+	if err != nil {
+		return models.ShoppingListItem{}, err
+	}
+
+	items := append([]models.ShoppingListItem{}, list.Items...)
+	for i, item := range items {
+		if item.ID != itemID {
+			continue
+		}
+
+		items[i].Checked = input.Checked
+		if _, err := s.repository.SetItems(id, items); err != nil {
+			return models.ShoppingListItem{}, err
+		}
+		return items[i], nil
+	}
+	return models.ShoppingListItem{}, ErrShoppingListItemNotFound
+}
+
+// DeleteShoppingList removes a shopping list the caller owns
+func (s *ShoppingListService) DeleteShoppingList(id string, caller Caller) error {
+	if _, err := s.GetShoppingList(id, caller); err != nil {
+		return err
+	}
+	return s.repository.Delete(id)
+}
+
+// shoppingRecipes collects the recipes of input with the factor each is made by. Listed recipes
+// must be visible to the caller; planned meals whose recipe the caller can no longer see are skipped.
+func (s *ShoppingListService) shoppingRecipes(caller Caller, input models.ShoppingListInput) ([]shoppingRecipe, error) {
+	recipes := make([]shoppingRecipe, 0, len(input.Recipes))
+	for _, item := range input.Recipes {
+		if item.Servings < 0 {
+			return nil, ErrInvalidShoppingList
+		}
+
+		recipe, err := s.recipeService.GetVisibleRecipe(item.RecipeID, caller)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, shoppingRecipe{recipe, servingsFactor(recipe, item.Servings)})
+	}
+
+	if input.MealPlanID != "" {
+		plan, err := s.mealPlanService.GetMealPlan(input.MealPlanID, caller, input.From, input.To)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range plan.Entries {
+			recipe, err := s.recipeService.GetVisibleRecipe(entry.RecipeID, caller)
+			if err != nil {
+				continue
+			}
+			recipes = append(recipes, shoppingRecipe{recipe, servingsFactor(recipe, entry.Servings)})
+		}
+	}
+
+	if len(recipes) == 0 {
+		return nil, ErrInvalidShoppingList
+	}
+	return recipes, nil
+}
+
+// buildItems merges the ingredients of recipes, with the sub-recipes the caller may see expanded, into
+// shopping list items ordered by aisle, then name
+func (s *ShoppingListService) buildItems(recipes []shoppingRecipe, caller Caller) []models.ShoppingListItem {
+	items := make([]models.ShoppingListItem, 0)
+	for _, entry := range recipes {
+		for _, ingredient := range s.recipeService.ExpandIngredients(entry.recipe, caller) {
+			if ingredient.Quantity != nil {
+				scaled := ingredient.Quantity.Mul(entry.factor)
+				ingredient.Quantity = &scaled
+			}
+			items = s.addIngredient(items, ingredient, entry.recipe.ID)
+		}
+	}
+
+	for i, item := range items {
+		if item.Quantity != nil {
+			amount, unit := rebalanceUnit(*item.Quantity, item.Unit)
+			amount = roundQuantity(amount, unit)
+			items[i].Quantity, items[i].Unit = &amount, unit
+		}
+		items[i].ID = uuid.New().String()
+		items[i].Category = aisleCategory(item.Name)
+	}
+
+	Sort(items, func(i, j int) bool {
+		if items[i].Category.Order() != items[j].Category.Order() {
+			return items[i].Category.Order() < items[j].Category.Order()
+		}
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+	return items
+}
+
+// addIngredient merges an ingredient into the first item of the same ingredient whose unit its amount
+// converts to, using densities to cross between volume and weight. Ingredients that cannot be merged,
+// such as 2 onions and 1 cup of onion, get an item of their own.
+func (s *ShoppingListService) addIngredient(items []models.ShoppingListItem, ingredient models.Ingredient, recipeID string) []models.ShoppingListItem {
+	key := ingredientKey(ingredient.Name)
+	for i, item := range items {
+		if ingredientKey(item.Name) != key {
+			continue
+		}
+
+		switch {
+		case ingredient.Quantity == nil:
+		case item.Quantity == nil:
+			items[i].Quantity, items[i].Unit = ingredient.Quantity, ingredient.Unit
+		case item.Unit == ingredient.Unit:
+			total := item.Quantity.Add(*ingredient.Quantity)
+			items[i].Quantity = &total
+		default:
+			converted, err := s.conversionService.ConvertIngredientAmount(ingredient.Quantity.Float64(), ingredient.Unit, item.Unit, item.Name)
+			if err != nil {
+				continue
+			}
+			total := item.Quantity.Add(models.QuantityFromFloat(converted))
+			items[i].Quantity = &total
+		}
+
+		if !Contains(items[i].RecipeIDs, recipeID) {
+			items[i].RecipeIDs = append(items[i].RecipeIDs, recipeID)
+		}
+		return items
+	}
+
+	return append(items, models.ShoppingListItem{
+		Quantity:  ingredient.Quantity,
+		Unit:      ingredient.Unit,
+		Name:      ingredient.Name,
+		RecipeIDs: []string{recipeID},
+	})
+}
+
+// ingredientKey returns the form of an ingredient name that items are merged by, so that
+// "Eggs" and "egg" end up on the same line
+func ingredientKey(name string) string {
+	return singularize(normalizeText(name))
+}
+
+// servingsFactor returns how many times a recipe is made to serve servings; zero servings, or a
+// recipe without servings, means the recipe as written
+func servingsFactor(recipe models.Recipe, servings int) models.Quantity {
+	if servings <= 0 || recipe.Servings <= 0 {
+		return models.WholeQuantity(1)
+	}
+	return models.NewQuantity(int64(servings), int64(recipe.Servings))
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestShoppingLists tests merging, scaling and grouping ingredients, check-off state and exporting shopping lists
+func TestShoppingLists(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	mealPlanRepo := repositories.NewInMemoryMealPlanRepository()
+	shoppingListRepo := repositories.NewInMemoryShoppingListRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	mealPlanService := NewMealPlanService(mealPlanRepo, recipeService)
+	service := NewShoppingListService(shoppingListRepo, recipeService, mealPlanService, NewConversionService())
+
+	alice := Caller{UserID: "alice"}
+	bob := Caller{UserID: "bob"}
+
+	pancakes, _ := recipeService.CreateRecipe(alice.UserID, models.RecipeInput{
+		Title:       "Pancakes",
+		Ingredients: models.ParseIngredients([]string{"1 cup flour", "2 eggs", "1 cup milk", "1 tbsp sugar", "salt"}),
+		Servings:    4,
+	})
+	crepes, _ := recipeService.CreateRecipe(alice.UserID, models.RecipeInput{
+		Title:       "Crepes",
+		Ingredients: models.ParseIngredients([]string{"1/2 cup flour", "1 egg", "200 ml milk", "2 tbsp sugar", "1 pinch salt"}),
+		Servings:    2,
+	})
+
+	if _, err := service.CreateShoppingList(alice, models.ShoppingListInput{}); !errors.Is(err, ErrInvalidShoppingList) {
+		t.Errorf("Expected ErrInvalidShoppingList without recipes, but got %v", err)
+	}
+	if _, err := service.CreateShoppingList(bob, models.ShoppingListInput{Recipes: []models.ShoppingListRecipeInput{{RecipeID: pancakes.ID}}}); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound for someone else's draft, but got %v", err)
+	}
+
+	list, err := service.CreateShoppingList(alice, models.ShoppingListInput{
+		Recipes: []models.ShoppingListRecipeInput{
+			{RecipeID: pancakes.ID, Servings: 8},
+			{RecipeID: crepes.ID},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected to create a shopping list, but got error: %v", err)
+	}
+	if list.Name != "Shopping list" {
+		t.Errorf("Expected the default name, but got '%s'", list.Name)
+	}
+
+	// Items are merged across recipes and units, then ordered by aisle
+	expected := []string{"5 eggs", "2 7/8 cups milk", "2 1/2 cups flour", "1/4 cup sugar", "1 pinch salt"}
+	if got := describeItems(list.Items); strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, but got %v", expected, got)
+	}
+	if list.Items[0].Category != models.AisleDairy || list.Items[4].Category != models.AisleSpices {
+		t.Errorf("Expected eggs in dairy and salt in spices, but got %s and %s", list.Items[0].Category, list.Items[4].Category)
+	}
+	if len(list.Items[2].RecipeIDs) != 2 {
+		t.Errorf("Expected flour to come from both recipes, but got %v", list.Items[2].RecipeIDs)
+	}
+
+	// Meal plans add each planned meal within the range, scaled to its servings
+	plan, _ := mealPlanService.CreateMealPlan(alice, models.MealPlanInput{Name: "Week"})
+	monday := date(t, "2024-03-04")
+	mealPlanService.AddEntry(plan.ID, alice, models.MealPlanEntryInput{Date: monday, Slot: models.SlotBreakfast, RecipeID: pancakes.ID, Servings: 2})
+	mealPlanService.AddEntry(plan.ID, alice, models.MealPlanEntryInput{Date: monday.AddDays(1), Slot: models.SlotBreakfast, RecipeID: pancakes.ID})
+	mealPlanService.AddEntry(plan.ID, alice, models.MealPlanEntryInput{Date: monday.AddDays(7), Slot: models.SlotBreakfast, RecipeID: crepes.ID})
+
+	if _, err := service.CreateShoppingList(bob, models.ShoppingListInput{MealPlanID: plan.ID}); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound for another user's meal plan, but got %v", err)
+	}
+	weekly, err := service.CreateShoppingList(alice, models.ShoppingListInput{Name: "Groceries", MealPlanID: plan.ID, From: monday, To: monday.AddDays(6)})
+	if err != nil {
+		t.Fatalf("Expected to create a shopping list from the meal plan, but got error: %v", err)
+	}
+	if got := describeItems(weekly.Items); got[2] != "1 1/2 cups flour" {
+		t.Errorf("Expected 1 1/2 cups of flour for the week, but got %v", got)
+	}
+
+	// Check-off state is kept per list
+	item, err := service.CheckItem(list.ID, list.Items[2].ID, alice, models.ShoppingListItemInput{Checked: true})
+	if err != nil || !item.Checked {
+		t.Errorf("Expected the flour to be checked off, but got %+v, %v", item, err)
+	}
+	if _, err := service.CheckItem(list.ID, list.Items[2].ID, bob, models.ShoppingListItemInput{Checked: true}); !errors.Is(err, ErrShoppingListNotFound) {
+		t.Errorf("Expected ErrShoppingListNotFound for another user, but got %v", err)
+	}
+	if _, err := service.CheckItem(list.ID, "missing", alice, models.ShoppingListItemInput{Checked: true}); !errors.Is(err, ErrShoppingListItemNotFound) {
+		t.Errorf("Expected ErrShoppingListItemNotFound, but got %v", err)
+	}
+	weekly, _ = service.GetShoppingList(weekly.ID, alice)
+	if weekly.Items[2].Checked {
+		t.Error("Expected checking off an item to leave other lists alone")
+	}
+
+	list, _ = service.GetShoppingList(list.ID, alice)
+	var text bytes.Buffer
+	if err := WriteShoppingListText(&text, list); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !strings.Contains(text.String(), "\nPantry\n[x] 2 1/2 cups flour\n[ ] 1/4 cup sugar\n") {
+		t.Errorf("Expected the pantry items under their heading, but got:\n%s", text.String())
+	}
+
+	var csv bytes.Buffer
+	if err := WriteShoppingListCSV(&csv, list); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 6 || lines[0] != "category,quantity,unit,name,checked" || lines[3] != "pantry,2 1/2,cup,flour,true" {
+		t.Errorf("Expected a header and 5 rows, but got:\n%s", csv.String())
+	}
+
+	if lists := service.GetShoppingLists(alice); len(lists) != 2 {
+		t.Errorf("Expected 2 shopping lists, but got %d", len(lists))
+	}
+	if err := service.DeleteShoppingList(list.ID, bob); !errors.Is(err, ErrShoppingListNotFound) {
+		t.Errorf("Expected ErrShoppingListNotFound when another user deletes, but got %v", err)
+	}
+}
+
+// Helper function to render shopping list items as ingredient lines
+func describeItems(items []models.ShoppingListItem) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.Ingredient().String())
+	}
+	return result
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+
+	"playground/models"
+)
+
+// ErrRecipeCycle is returned when a recipe would end up among its own sub-recipes
+var ErrRecipeCycle = errors.New("recipe cannot use itself as a sub-recipe, directly or through other sub-recipes")
+
+// ErrSubRecipeNotFound is returned when an ingredient refers to a recipe that does not exist or cannot be seen
+var ErrSubRecipeNotFound = errors.New("sub-recipe not found")
+
+// MaxSubRecipeDepth is how many levels deep sub-recipes may nest
+const MaxSubRecipeDepth = 10
+
+// MaxExpandedIngredients is the most ingredient lines a recipe may have with its sub-recipes expanded
+const MaxExpandedIngredients = 1000
+
+// ErrTooManySubRecipes is returned when saving a recipe whose sub-recipes nest too deeply or expand
+// to too many ingredients
+var ErrTooManySubRecipes = errors.New("sub-recipes nest more than 10 levels deep or expand to more than 1000 ingredients")
+
+// subRecipeBatches returns how many batches of sub an ingredient line calls for. Lines without
+// a quantity call for one batch; the "serving" unit counts servings of sub instead of batches.
+func subRecipeBatches(ingredient models.Ingredient, sub models.Recipe) models.Quantity {
+	if ingredient.Quantity == nil {
+		return models.WholeQuantity(1)
+	}
+	if ingredient.Unit == models.UnitServing && sub.Servings > 0 {
+		return ingredient.Quantity.Mul(models.NewQuantity(1, int64(sub.Servings)))
+	}
+	return *ingredient.Quantity
+}
+
+// findSubRecipe returns the recipe an ingredient refers to if the author of the referring recipe may see it
+func (s *RecipeService) findSubRecipe(id string, author Caller) (models.Recipe, bool) {
+	recipe, err := s.repository.FindByID(id)
+	if err != nil || !canView(recipe, author) {
+		return models.Recipe{}, false
+	}
+	return recipe, true
+}
+
+// checkSubRecipes verifies that every sub-recipe in ingredients exists, can be seen by the author and
+// does not lead back to the recipe with the given ID, which is empty for a recipe not saved yet, and
+// that the sub-recipes stay within the expansion limits
+func (s *RecipeService) checkSubRecipes(id string, ingredients []models.Ingredient, author Caller) error {
+	for _, ingredient := range ingredients {
+		if !ingredient.IsSubRecipe() {
+			continue
+		}
+		if ingredient.RecipeID == id {
+			return ErrRecipeCycle
+		}
+
+		sub, ok := s.findSubRecipe(ingredient.RecipeID, author)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrSubRecipeNotFound, ingredient.RecipeID)
+		}
+		if id != "" && s.usesRecipe(sub, id, make(map[string]bool)) {
+			return ErrRecipeCycle
+		}
+	}
+
+	if _, ok := s.expandIngredients(ingredients, author, author, id); !ok {
+		return ErrTooManySubRecipes
+	}
+	return nil
+}
+
+// usesRecipe reports whether the recipe with the given ID is anywhere below recipe. Recipes in the
+// trash are followed too, so restoring one cannot close a cycle.
+func (s *RecipeService) usesRecipe(recipe models.Recipe, id string, seen map[string]bool) bool {
+	seen[recipe.ID] = true
+	for _, ingredient := range recipe.Ingredients {
+		if !ingredient.IsSubRecipe() || seen[ingredient.RecipeID] {
+			continue
+		}
+		if ingredient.RecipeID == id {
+			return true
+		}
+
+		sub, err := s.repository.FindByID(ingredient.RecipeID)
+		if err != nil {
+			sub, err = s.repository.FindDeletedByID(ingredient.RecipeID)
+		}
+		if err == nil && s.usesRecipe(sub, id, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandIngredients returns the ingredients of a recipe as the caller sees them, with every sub-recipe
+// replaced by its own ingredients, recursively, scaled to the amount called for. Sub-recipes that are
+// gone, that their referring author can no longer see or that the caller may not see are kept as they
+// are, like the unavailable sub-recipes of the tree, as are those past the expansion limits.
+func (s *RecipeService) ExpandIngredients(recipe models.Recipe, caller Caller) []models.Ingredient {
+	ingredients, _ := s.expandIngredients(recipe.Ingredients, Caller{UserID: recipe.AuthorID}, caller, recipe.ID)
+	return ingredients
+}
+
+// expandIngredients expands ingredients chosen by author for the caller, for the recipe with the
+// given ID. It reports false when sub-recipes nest deeper than MaxSubRecipeDepth or would expand to
+// more than MaxExpandedIngredients lines; those sub-recipes are kept as they are.
+func (s *RecipeService) expandIngredients(ingredients []models.Ingredient, author Caller, caller Caller, id string) ([]models.Ingredient, bool) {
+	expander := &ingredientExpander{
+		service: s,
+		caller:  caller,
+		path:    map[string]bool{id: true},
+		cache:   make(map[expansionKey][]expandedLine),
+	}
+	lines := expander.expand(ingredients, author, 0)
+
+	result := make([]models.Ingredient, 0, len(lines))
+	for _, line := range lines {
+		ingredient := line.ingredient
+		if line.factor.Float64() != 1 {
+			ingredient = scaleIngredient(ingredient, line.factor)
+		}
+		result = append(result, ingredient)
+	}
+	return result, !expander.exceeded
+}
+
+// expandedLine is an ingredient of an expansion with how many times its amount is called for
+type expandedLine struct {
+	ingredient models.Ingredient
+	factor     models.Quantity
+}
+
+// expansionKey identifies the expansion of a sub-recipe at a depth, which decides how far it may nest
+type expansionKey struct {
+	recipeID string
+	depth    int
+}
+
+// ingredientExpander expands sub-recipes for one caller. Each sub-recipe is expanded once per depth
+// and reused wherever it appears, so recipes sharing sub-recipes cost no more than their expanded
+// size, which the limits keep small.
+type ingredientExpander struct {
+	service *RecipeService
+	caller  Caller
+	// path holds the recipes being expanded, so a cycle is cut off instead of recursing forever
+	path     map[string]bool
+	cache    map[expansionKey][]expandedLine
+	exceeded bool
+}
+
+// expand expands ingredients chosen by author at the given depth of sub-recipes, per batch
+func (e *ingredientExpander) expand(ingredients []models.Ingredient, author Caller, depth int) []expandedLine {
+	result := make([]expandedLine, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		var sub models.Recipe
+		ok := false
+		if ingredient.IsSubRecipe() && !e.path[ingredient.RecipeID] {
+			sub, ok = e.service.findSubRecipe(ingredient.RecipeID, author)
+			ok = ok && canView(sub, e.caller)
+		}
+		if ok && depth >= MaxSubRecipeDepth {
+			e.exceeded, ok = true, false
+		}
+
+		var lines []expandedLine
+		if ok {
+			key := expansionKey{sub.ID, depth + 1}
+			var cached bool
+			if lines, cached = e.cache[key]; !cached {
+				e.path[sub.ID] = true
+				lines = e.expand(sub.Ingredients, Caller{UserID: sub.AuthorID}, depth+1)
+				delete(e.path, sub.ID)
+				e.cache[key] = lines
+			}
+			if len(result)+len(lines) > MaxExpandedIngredients {
+				e.exceeded, ok = true, false
+			}
+		}
+		if !ok {
+			result = append(result, expandedLine{ingredient, models.WholeQuantity(1)})
+			continue
+		}
+
+		batches := subRecipeBatches(ingredient, sub)
+		for _, line := range lines {
+			result = append(result, expandedLine{line.ingredient, batches.Mul(line.factor)})
+		}
+	}
+	return result
+}
+
+// refreshDependentDiets works out the diet again of every recipe that uses recipe as a sub-recipe,
+// directly or through other sub-recipes, since their allergens and labels include its ingredients
+func (s *RecipeService) refreshDependentDiets(recipe models.Recipe) {
+	users := make(map[string][]models.Recipe)
+	for _, candidate := range s.repository.FindAll() {
+		for _, ingredient := range candidate.Ingredients {
+			if ingredient.IsSubRecipe() {
+				users[ingredient.RecipeID] = append(users[ingredient.RecipeID], candidate)
+			}
+		}
+	}
+
+	seen := map[string]bool{recipe.ID: true}
+	queue := []string{recipe.ID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, dependent := range users[id] {
+			if seen[dependent.ID] {
+				continue
+			}
+			seen[dependent.ID] = true
+			queue = append(queue, dependent.ID)
+			s.refreshDiet(dependent)
+		}
+	}
+}
+
+// refreshDiet works out the diet of a saved recipe again as its author sees its sub-recipes now
+func (s *RecipeService) refreshDiet(recipe models.Recipe) {
+	diet := s.classify(recipe.Input(), Caller{UserID: recipe.AuthorID}, recipe.ID)
+	if !reflect.DeepEqual(diet, recipe.Diet) {
+		if _, err := s.repository.SetDiet(recipe.ID, diet); err != nil {
+			log.Printf("Cannot update the diet of recipe %s: %v", recipe.ID, err)
+		}
+	}
+}
+
+// GetRecipeTree returns the sub-recipe tree of a recipe the caller may see, with the recipe
+// made factor times. Sub-recipes the caller may not see are marked unavailable.
+func (s *RecipeService) GetRecipeTree(id string, factor models.Quantity, caller Caller) (models.RecipeTree, error) {
+	if factor.Float64() <= 0 {
+		return models.RecipeTree{}, ErrInvalidScale
+	}
+
+	recipe, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.RecipeTree{}, err
+	}
+	nodes := MaxExpandedIngredients
+	return s.buildTree(recipe, factor, caller, map[string]bool{recipe.ID: true}, &nodes), nil
+}
+
+// GetRecipeTreeForServings returns the sub-recipe tree of a recipe the caller may see, scaled to
+// make the given number of servings
+func (s *RecipeService) GetRecipeTreeForServings(id string, servings int, caller Caller) (models.RecipeTree, error) {
+	recipe, err := s.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.RecipeTree{}, err
+	}
+
+	if servings <= 0 || recipe.Servings <= 0 {
+		return models.RecipeTree{}, ErrInvalidScale
+	}
+	return s.GetRecipeTree(id, models.NewQuantity(int64(servings), int64(recipe.Servings)), caller)
+}
+
+// buildTree builds the tree node of recipe and its sub-recipes, adding up their times. path holds the
+// recipes above, and nodes how many more sub-recipes the tree may show; sub-recipes past
+// MaxSubRecipeDepth or the node budget are marked unavailable, so the tree stays small.
+func (s *RecipeService) buildTree(recipe models.Recipe, batches models.Quantity, caller Caller, path map[string]bool, nodes *int) models.RecipeTree {
+	node := models.RecipeTree{
+		RecipeID:      recipe.ID,
+		Title:         recipe.Title,
+		Batches:       batches.Float64(),
+		PrepTime:      recipe.PrepTime,
+		CookTime:      recipe.CookTime,
+		TotalPrepTime: recipe.PrepTime,
+		TotalCookTime: recipe.CookTime,
+		Children:      make([]models.RecipeTree, 0),
+	}
+
+	for _, ingredient := range recipe.Ingredients {
+		if !ingredient.IsSubRecipe() {
+			continue
+		}
+
+		sub, ok := s.findSubRecipe(ingredient.RecipeID, Caller{UserID: recipe.AuthorID})
+		if !ok || path[sub.ID] || !canView(sub, caller) || len(path) > MaxSubRecipeDepth || *nodes <= 0 {
+			node.Children = append(node.Children, models.RecipeTree{
+				RecipeID:    ingredient.RecipeID,
+				Unavailable: true,
+				Children:    make([]models.RecipeTree, 0),
+			})
+			continue
+		}
+
+		*nodes--
+		path[sub.ID] = true
+		child := s.buildTree(sub, batches.Mul(subRecipeBatches(ingredient, sub)), caller, path, nodes)
+		delete(path, sub.ID)
+
+		node.TotalPrepTime += child.TotalPrepTime
+		node.TotalCookTime += child.TotalCookTime
+		node.Children = append(node.Children, child)
+	}
+
+	node.TotalTime = node.TotalPrepTime + node.TotalCookTime
+	return node
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestSubRecipes tests cycle detection, ingredient expansion, nutrition and the dependency tree of sub-recipes
+func TestSubRecipes(t *testing.T) {
+	foods, err := LoadNutrientTable(strings.NewReader(testNutrientTable))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the services with the repository
+	service := NewRecipeService(repo)
+	nutritionService := NewNutritionService(foods, service, NewConversionService())
+
+	author := Caller{UserID: "author-1"}
+	other := Caller{UserID: "author-2"}
+
+	custard, err := service.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:       "Custard",
+		Ingredients: models.ParseIngredients([]string{"2 eggs", "50 g sugar"}),
+		PrepTime:    5,
+		CookTime:    10,
+		Servings:    4,
+		Visibility:  models.VisibilityPrivate,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	subRecipe := func(id string, quantity int64, unit string) models.Ingredient {
+		amount := models.WholeQuantity(quantity)
+		return models.Ingredient{Quantity: &amount, Unit: unit, Name: "custard", RecipeID: id}
+	}
+
+	tart, err := service.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:        "Custard Tart",
+		Ingredients:  []models.Ingredient{subRecipe(custard.ID, 2, models.UnitBatch), models.ParseIngredient("1 tbsp olive oil")},
+		Instructions: []string{"Fill the pastry and bake"},
+		PrepTime:     15,
+		CookTime:     30,
+		Servings:     8,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !tart.Diet.HasAllergen(models.AllergenEggs) || tart.Diet.HasLabel(models.LabelVegan) {
+		t.Errorf("Expected the tart to inherit the custard's eggs, but got %+v", tart.Diet)
+	}
+
+	// Sub-recipes must exist and be visible to the author
+	missing := models.RecipeInput{Title: "Tart", Ingredients: []models.Ingredient{subRecipe("missing", 1, "")}}
+	if _, err := service.CreateRecipe(author.UserID, missing); !errors.Is(err, ErrSubRecipeNotFound) {
+		t.Errorf("Expected ErrSubRecipeNotFound, but got %v", err)
+	}
+	private := models.RecipeInput{Title: "Tart", Ingredients: []models.Ingredient{subRecipe(custard.ID, 1, "")}}
+	if _, err := service.CreateRecipe(other.UserID, private); !errors.Is(err, ErrSubRecipeNotFound) {
+		t.Errorf("Expected ErrSubRecipeNotFound for another user's private recipe, but got %v", err)
+	}
+
+	// A recipe cannot use itself, directly or through a sub-recipe
+	input := custard.Input()
+	input.Ingredients = append(input.Ingredients, subRecipe(custard.ID, 1, ""))
+	input.IngredientSections = nil
+	if _, err := service.UpdateRecipe(custard.ID, author, input); !errors.Is(err, ErrRecipeCycle) {
+		t.Errorf("Expected ErrRecipeCycle for a direct cycle, but got %v", err)
+	}
+	input.Ingredients[len(input.Ingredients)-1] = subRecipe(tart.ID, 1, "")
+	if _, err := service.UpdateRecipe(custard.ID, author, input); !errors.Is(err, ErrRecipeCycle) {
+		t.Errorf("Expected ErrRecipeCycle for an indirect cycle, but got %v", err)
+	}
+
+	// Expansion multiplies the sub-recipe by the batches called for
+	expanded := service.ExpandIngredients(tart, author)
+	if len(expanded) != 3 || expanded[0].Name != "eggs" || expanded[0].Quantity.Float64() != 4 || expanded[1].Quantity.Float64() != 100 {
+		t.Errorf("Expected 4 eggs, 100 g sugar and the oil, but got %v", expanded)
+	}
+
+	// Servings of a sub-recipe are a fraction of a batch
+	input = tart.Input()
+	input.Ingredients = []models.Ingredient{subRecipe(custard.ID, 2, models.UnitServing)}
+	input.IngredientSections = nil
+	half, err := service.UpdateRecipe(tart.ID, author, input)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expanded = service.ExpandIngredients(half, author)
+	if len(expanded) != 2 || expanded[0].Quantity.Float64() != 1 || expanded[1].Quantity.Float64() != 25 {
+		t.Errorf("Expected 1 egg and 25 g sugar, but got %v", expanded)
+	}
+
+	facts := nutritionService.CalculateNutrition(half, author)
+	if facts.Total.Calories != 168.3 || facts.Coverage != 1 {
+		t.Errorf("Expected 168.3 calories with full coverage, but got %+v", facts)
+	}
+
+	// The tree adds up the times of every sub-recipe and scales their batches
+	tree, err := service.GetRecipeTreeForServings(tart.ID, 16, author)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if tree.Batches != 2 || tree.TotalPrepTime != 20 || tree.TotalCookTime != 40 || tree.TotalTime != 60 {
+		t.Errorf("Expected 2 batches taking 20 + 40 minutes, but got %+v", tree)
+	}
+	if len(tree.Children) != 1 || tree.Children[0].Title != "Custard" || tree.Children[0].Batches != 1 {
+		t.Errorf("Expected one batch of custard below the tart, but got %+v", tree.Children)
+	}
+
+	// Sub-recipes the viewer may not see are hidden in the tree
+	if _, err := service.TransitionRecipe(tart.ID, author, models.StatusPublished); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	tree, err = service.GetRecipeTree(tart.ID, models.WholeQuantity(1), other)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(tree.Children) != 1 || !tree.Children[0].Unavailable || tree.Children[0].Title != "" {
+		t.Errorf("Expected the private custard to be unavailable, but got %+v", tree.Children)
+	}
+
+	// Nor are they expanded into the viewer's ingredients and nutrition
+	expanded = service.ExpandIngredients(half, other)
+	if len(expanded) != 1 || expanded[0].RecipeID != custard.ID {
+		t.Errorf("Expected the private custard to be kept as it is, but got %v", expanded)
+	}
+	facts, err = nutritionService.GetRecipeNutrition(tart.ID, other)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if facts.Total.Calories != 0 || len(facts.Unmatched) != 1 {
+		t.Errorf("Expected no calories from the private custard, but got %+v", facts)
+	}
+
+	// A fork keeps only the sub-recipes its new owner may see, so it can be saved again
+	fork, err := service.ForkRecipe(tart.ID, other)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if fork.Ingredients[0].IsSubRecipe() || fork.Ingredients[0].Name != "custard" {
+		t.Errorf("Expected the private custard to become a plain ingredient, but got %+v", fork.Ingredients[0])
+	}
+	if _, err := service.UpdateRecipe(fork.ID, other, fork.Input()); err != nil {
+		t.Errorf("Expected the fork to save, but got: %v", err)
+	}
+}
+
+// TestSubRecipeLimits tests that sub-recipes cannot nest too deeply or expand to too many ingredients
+func TestSubRecipeLimits(t *testing.T) {
+	service := NewRecipeService(repositories.NewInMemoryRecipeRepository())
+	author := Caller{UserID: "author-1"}
+
+	chain := func(uses int) (int, error) {
+		below, err := service.CreateRecipe(author.UserID, models.RecipeInput{
+			Title:       "Level 0",
+			Ingredients: models.ParseIngredients([]string{"1 egg"}),
+		})
+		if err != nil {
+			return 0, err
+		}
+		for level := 1; level <= 25; level++ {
+			ingredients := make([]models.Ingredient, uses)
+			for i := range ingredients {
+				ingredients[i] = models.Ingredient{Name: below.Title, RecipeID: below.ID}
+			}
+			if below, err = service.CreateRecipe(author.UserID, models.RecipeInput{Title: "Level", Ingredients: ingredients}); err != nil {
+				return level, err
+			}
+		}
+		return 25, nil
+	}
+
+	// Each level doubling the one below would otherwise take exponential time to expand
+	if level, err := chain(2); !errors.Is(err, ErrTooManySubRecipes) || level != 10 {
+		t.Errorf("Expected ErrTooManySubRecipes at level 10 for 1024 ingredients, but got %v at level %d", err, level)
+	}
+	if level, err := chain(1); !errors.Is(err, ErrTooManySubRecipes) || level != MaxSubRecipeDepth+1 {
+		t.Errorf("Expected ErrTooManySubRecipes at level %d, but got %v at level %d", MaxSubRecipeDepth+1, err, level)
+	}
+}
+
+// TestSubRecipeDiet tests that the diet of a recipe follows changes to its sub-recipes
+func TestSubRecipeDiet(t *testing.T) {
+	service := NewRecipeService(repositories.NewInMemoryRecipeRepository())
+	author := Caller{UserID: "author-1"}
+	other := Caller{UserID: "author-2"}
+
+	custard := createPublishedRecipe(t, service, author.UserID, "Custard")
+	input := custard.Input()
+	input.Ingredients = models.ParseIngredients([]string{"2 eggs", "50 g sugar"})
+	input.IngredientSections = nil
+	if _, err := service.UpdateRecipe(custard.ID, author, input); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	tart, err := service.CreateRecipe(author.UserID, models.RecipeInput{
+		Title:        "Tart",
+		Ingredients:  []models.Ingredient{{Name: "filling", RecipeID: custard.ID}},
+		Instructions: []string{"Bake"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := service.TransitionRecipe(tart.ID, author, models.StatusPublished); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	pie, err := service.CreateRecipe(other.UserID, models.RecipeInput{
+		Title:       "Pie",
+		Ingredients: []models.Ingredient{{Name: "tart", RecipeID: tart.ID}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	hasEggs := func(id string) bool {
+		recipe, err := service.GetRecipeByID(id)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		return recipe.Diet.HasAllergen(models.AllergenEggs)
+	}
+	if !hasEggs(tart.ID) || !hasEggs(pie.ID) {
+		t.Fatal("Expected the custard's eggs in the tart and the pie")
+	}
+
+	// The pie only uses the custard through the tart while its author may see the tart
+	if _, err := service.TransitionRecipe(tart.ID, author, models.StatusDraft); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !hasEggs(tart.ID) || hasEggs(pie.ID) {
+		t.Error("Expected the eggs to leave the pie once the tart is a draft again")
+	}
+	if _, err := service.TransitionRecipe(tart.ID, author, models.StatusPublished); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Editing, trashing and restoring the custard reaches every recipe above it
+	input = custard.Input()
+	input.Ingredients = models.ParseIngredients([]string{"400 ml oat milk", "50 g sugar"})
+	input.IngredientSections = nil
+	if _, err := service.UpdateRecipe(custard.ID, author, input); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if hasEggs(tart.ID) || hasEggs(pie.ID) {
+		t.Error("Expected the eggs to leave the tart and the pie with the custard's edit")
+	}
+	if _, err := service.RevertRecipe(custard.ID, 2, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !hasEggs(pie.ID) {
+		t.Error("Expected the eggs back in the pie after reverting the custard")
+	}
+	if err := service.DeleteRecipe(custard.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if hasEggs(tart.ID) || hasEggs(pie.ID) {
+		t.Error("Expected the eggs to leave the tart and the pie with the custard in the trash")
+	}
+	if _, err := service.RestoreRecipe(custard.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !hasEggs(tart.ID) || !hasEggs(pie.ID) {
+		t.Error("Expected the eggs back in the tart and the pie with the custard restored")
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"playground/models"
+)
+
+// SubstitutionService proposes ingredient swaps from a table of curated rules
+type SubstitutionService struct {
+	// rules are ordered from the most specific ingredient name to the least, so the swaps for
+	// "heavy cream" are proposed before those for "cream"
+	rules             []models.SubstitutionRule
+	recipeService     *RecipeService
+	conversionService *ConversionService
+	classifier        *IngredientClassifier
+}
+
+// NewSubstitutionService creates a new substitution service with the given rules
+func NewSubstitutionService(rules []models.SubstitutionRule, recipeService *RecipeService, conversionService *ConversionService) *SubstitutionService {
+	ordered := append([]models.SubstitutionRule{}, rules...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return len(ingredientKey(ordered[i].For.Name)) > len(ingredientKey(ordered[j].For.Name))
+	})
+
+	return &SubstitutionService{
+		rules:             ordered,
+		recipeService:     recipeService,
+		conversionService: conversionService,
+		classifier:        NewIngredientClassifier(),
+	}
+}
+
+// GetSubstitutions proposes swaps for each ingredient of a recipe the caller may see. When label is
+// set, the report also holds a variant of the recipe with swaps applied so that it meets the label.
+func (s *SubstitutionService) GetSubstitutions(id string, caller Caller, label models.DietaryLabel) (models.SubstitutionReport, error) {
+	recipe, err := s.recipeService.GetVisibleRecipe(id, caller)
+	if err != nil {
+		return models.SubstitutionReport{}, err
+	}
+
+	report := models.SubstitutionReport{Substitutions: make([]models.IngredientSubstitutions, 0)}
+	for i, ingredient := range recipe.Ingredients {
+		if ingredient.IsSubRecipe() {
+			continue
+		}
+		if substitutions := s.ProposeSubstitutions(ingredient); len(substitutions) > 0 {
+			report.Substitutions = append(report.Substitutions, models.IngredientSubstitutions{
+				Index:         i,
+				Ingredient:    ingredient,
+				Substitutions: substitutions,
+			})
+		}
+	}
+
+	if label != "" {
+		variant := s.variant(recipe, label, caller)
+		report.Variant = &variant
+	}
+	return report, nil
+}
+
+// ProposeSubstitutions returns the swaps known for an ingredient, most specific first, scaled to its
+// amount where the amount converts to the unit of the rule
+func (s *SubstitutionService) ProposeSubstitutions(ingredient models.Ingredient) []models.Substitution {
+	substitutions := make([]models.Substitution, 0)
+	for _, rule := range s.rules {
+		name := ingredientKey(rule.For.Name)
+		if key := ingredientKey(ingredient.Name); key == name || containsPhrase(key, name) {
+			substitutions = append(substitutions, s.substitute(rule, ingredient))
+		}
+	}
+	return substitutions
+}
+
+// substitute applies a rule to an ingredient. Replacements measured in the same unit as the rule's
+// ingredient keep the recipe's unit, so 200 ml milk becomes 200 ml soy milk rather than 7/8 cup.
+func (s *SubstitutionService) substitute(rule models.SubstitutionRule, ingredient models.Ingredient) models.Substitution {
+	lines := make([]string, 0, len(rule.Use))
+	for _, use := range rule.Use {
+		lines = append(lines, use.String())
+	}
+
+	substitution := models.Substitution{
+		Use:    rule.Use,
+		Ratio:  rule.For.String() + " = " + strings.Join(lines, " + "),
+		Labels: rule.Labels,
+		Note:   rule.Note,
+	}
+
+	factor, ok := s.ruleFactor(rule.For, ingredient)
+	if !ok {
+		return substitution
+	}
+
+	use := make([]models.Ingredient, 0, len(rule.Use))
+	for _, replacement := range rule.Use {
+		if replacement.Quantity != nil && replacement.Unit == rule.For.Unit {
+			amount := ingredient.Quantity.Mul(*replacement.Quantity).Mul(models.NewQuantity(rule.For.Quantity.Den, rule.For.Quantity.Num))
+			amount = roundQuantity(amount, ingredient.Unit)
+			replacement.Quantity, replacement.Unit = &amount, ingredient.Unit
+		} else {
+			replacement = scaleIngredient(replacement, factor)
+		}
+		replacement.Optional = ingredient.Optional
+		use = append(use, replacement)
+	}
+	substitution.Use = use
+	substitution.Scaled = true
+	return substitution
+}
+
+// ruleFactor returns how many times the amount of a rule's ingredient a recipe's ingredient calls for
+func (s *SubstitutionService) ruleFactor(rule models.Ingredient, ingredient models.Ingredient) (models.Quantity, bool) {
+	if rule.Quantity == nil || rule.Quantity.IsZero() || ingredient.Quantity == nil {
+		return models.Quantity{}, false
+	}
+
+	amount := ingredient.Quantity.Float64()
+	if ingredient.Unit != rule.Unit {
+		converted, err := s.conversionService.ConvertIngredientAmount(amount, ingredient.Unit, rule.Unit, ingredient.Name)
+		if err != nil {
+			return models.Quantity{}, false
+		}
+		amount = converted
+	}
+	return models.QuantityFromFloat(amount / rule.Quantity.Float64()), true
+}
+
+// variant swaps every ingredient of recipe that rules out label for the first replacement meeting it.
+// Sub-recipes are kept as they are and listed as unresolved when they do not meet the label, or when
+// the caller may not see them.
+func (s *SubstitutionService) variant(recipe models.Recipe, label models.DietaryLabel, caller Caller) models.RecipeVariant {
+	exclusions := labelExclusions[label]
+	author := Caller{UserID: recipe.AuthorID}
+	variant := models.RecipeVariant{
+		Label:      label,
+		Applied:    make([]models.AppliedSubstitution, 0),
+		Unresolved: make([]models.Ingredient, 0),
+	}
+
+	variant.Recipe = recipe.ReplaceIngredients(func(ingredient models.Ingredient) []models.Ingredient {
+		if ingredient.IsSubRecipe() {
+			if sub, ok := s.recipeService.findSubRecipe(ingredient.RecipeID, author); ok {
+				if !canView(sub, caller) || !sub.Diet.HasLabel(label) {
+					variant.Unresolved = append(variant.Unresolved, ingredient)
+				}
+				return []models.Ingredient{ingredient}
+			}
+		}
+		if !exclusions.excludes(s.classifier.classifyIngredient(ingredient.Name)) {
+			return []models.Ingredient{ingredient}
+		}
+
+		for _, substitution := range s.ProposeSubstitutions(ingredient) {
+			if Contains(substitution.Labels, label) && s.meets(substitution.Use, exclusions) {
+				variant.Applied = append(variant.Applied, models.AppliedSubstitution{Ingredient: ingredient, Substitution: substitution})
+				return substitution.Use
+			}
+		}
+		variant.Unresolved = append(variant.Unresolved, ingredient)
+		return []models.Ingredient{ingredient}
+	})
+
+	variant.Recipe.Diet = s.recipeService.classify(variant.Recipe.Input(), author, recipe.ID)
+	return variant
+}
+
+// meets reports whether none of the ingredients are ruled out by exclusions, guarding against
+// rules whose replacements do not in fact meet the labels they claim
+func (s *SubstitutionService) meets(ingredients []models.Ingredient, exclusions ingredientClass) bool {
+	for _, ingredient := range ingredients {
+		if exclusions.excludes(s.classifier.classifyIngredient(ingredient.Name)) {
+			return false
+		}
+	}
+	return true
+}
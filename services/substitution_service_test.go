@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestLoadSubstitutions tests reading substitution rules from JSON
+func TestLoadSubstitutions(t *testing.T) {
+	if rules := BundledSubstitutions(); len(rules) == 0 {
+		t.Error("Expected the bundled substitution table to have rules")
+	}
+
+	rules, err := LoadSubstitutions(strings.NewReader(`[{"for": "1 cup buttermilk", "use": ["1 cup milk", {"quantity": 1, "unit": "tbsp", "name": "lemon juice"}]}]`))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if rules[0].For.Unit != models.UnitCup || rules[0].Use[1].Unit != models.UnitTablespoon || rules[0].Labels == nil {
+		t.Errorf("Expected parsed ingredients and empty labels, but got %+v", rules[0])
+	}
+
+	invalid := []string{
+		`{"for": "1 cup milk"}`,
+		`[{"for": "1 cup milk", "use": []}]`,
+		`[{"use": ["1 cup soy milk"]}]`,
+		`[{"for": "1 cup milk", "use": ["1 cup soy milk"], "labels": ["keto"]}]`,
+	}
+	for _, table := range invalid {
+		if _, err := LoadSubstitutions(strings.NewReader(table)); !errors.Is(err, ErrInvalidSubstitutions) {
+			t.Errorf("Expected ErrInvalidSubstitutions for %s, but got %v", table, err)
+		}
+	}
+}
+
+// TestSubstitutions tests proposing swaps and building dietary variants of a recipe
+func TestSubstitutions(t *testing.T) {
+	// Create a repository
+	repo := repositories.NewInMemoryRecipeRepository()
+
+	// Create the services with the repository
+	recipeService := NewRecipeService(repo)
+	service := NewSubstitutionService(BundledSubstitutions(), recipeService, NewConversionService())
+
+	author := Caller{UserID: "author-1"}
+	recipe, _ := recipeService.CreateRecipe(author.UserID, models.RecipeInput{
+		Title: "Pancakes",
+		Ingredients: models.ParseIngredients([]string{
+			"2 cups buttermilk", "2 eggs", "200 ml milk", "2 tbsp butter, melted", "1 1/2 cups flour", "1 tbsp honey", "2 anchovies",
+		}),
+		Instructions: []string{"Whisk the buttermilk and eggs", "Fold in the flour"},
+		Servings:     4,
+	})
+
+	if _, err := service.GetSubstitutions(recipe.ID, Caller{UserID: "someone-else"}, ""); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound for someone else's draft, but got %v", err)
+	}
+
+	report, err := service.GetSubstitutions(recipe.ID, author, "")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if report.Variant != nil {
+		t.Error("Expected no variant without a dietary label")
+	}
+
+	proposals := make(map[string][]models.Substitution)
+	for _, item := range report.Substitutions {
+		proposals[item.Ingredient.Name] = item.Substitutions
+	}
+	if _, ok := proposals["anchovies"]; ok {
+		t.Error("Expected no swaps for anchovies")
+	}
+
+	tests := []struct {
+		ingredient string
+		expected   string
+	}{
+		{"buttermilk", "2 cups milk + 2 tbsp lemon juice"},
+		{"milk", "200 ml soy milk"},
+		{"eggs", "2 tbsp ground flaxseed + 3/8 cup water"},
+		{"flour", "1 1/2 cups gluten-free flour"},
+	}
+	for _, test := range tests {
+		substitutions := proposals[test.ingredient]
+		if len(substitutions) == 0 {
+			t.Errorf("Expected swaps for %s", test.ingredient)
+			continue
+		}
+		if got := describeUse(substitutions[0]); got != test.expected || !substitutions[0].Scaled {
+			t.Errorf("Expected %s to become %s, but got %s", test.ingredient, test.expected, got)
+		}
+	}
+	if ratio := proposals["buttermilk"][0].Ratio; ratio != "1 cup buttermilk = 1 cup milk + 1 tbsp lemon juice" {
+		t.Errorf("Expected the rule's ratio, but got '%s'", ratio)
+	}
+
+	report, _ = service.GetSubstitutions(recipe.ID, author, models.LabelVegan)
+	variant := report.Variant
+	if variant == nil {
+		t.Fatal("Expected a vegan variant")
+	}
+	if len(variant.Applied) != 5 || len(variant.Unresolved) != 1 || variant.Unresolved[0].Name != "anchovies" {
+		t.Errorf("Expected 5 swaps and the anchovies unresolved, but got %d swaps and %+v", len(variant.Applied), variant.Unresolved)
+	}
+	if got := variant.Recipe.Ingredients[0].Name + "," + variant.Recipe.Ingredients[1].Name; got != "soy milk,lemon juice" {
+		t.Errorf("Expected the vegan buttermilk swap, but got %s", got)
+	}
+	if got := fmt.Sprint(variant.Recipe.InstructionSections[0].Steps[0].Ingredients); got != "[0 1 2 3]" {
+		t.Errorf("Expected the first step to refer to the swapped buttermilk and eggs, but got %s", got)
+	}
+	if variant.Recipe.Diet.HasLabel(models.LabelVegan) || !variant.Recipe.Diet.HasAllergen(models.AllergenFish) {
+		t.Errorf("Expected the anchovies to keep the variant from being vegan, but got %+v", variant.Recipe.Diet)
+	}
+	if stored, _ := recipeService.GetRecipeByID(recipe.ID); len(stored.Ingredients) != 7 {
+		t.Error("Expected the stored recipe to be left unchanged")
+	}
+
+	report, _ = service.GetSubstitutions(recipe.ID, author, models.LabelGlutenFree)
+	if variant := report.Variant; len(variant.Applied) != 1 || len(variant.Unresolved) != 0 || !variant.Recipe.Diet.HasLabel(models.LabelGlutenFree) {
+		t.Errorf("Expected a gluten-free variant with the flour swapped, but got %+v", variant)
+	}
+}
+
+// Helper function to render the replacement ingredients of a substitution
+func describeUse(substitution models.Substitution) string {
+	lines := make([]string, 0, len(substitution.Use))
+	for _, ingredient := range substitution.Use {
+		lines = append(lines, ingredient.String())
+	}
+	return strings.Join(lines, " + ")
+}
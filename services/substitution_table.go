@@ -0,0 +1,54 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"playground/models"
+)
+
+// bundledSubstitutions is the curated substitution table shipped with the application
+//
+//go:embed data/substitutions.json
+var bundledSubstitutions []byte
+
+// ErrInvalidSubstitutions is returned when a substitution table cannot be read
+var ErrInvalidSubstitutions = errors.New("invalid substitution table")
+
+// BundledSubstitutions returns the rules of the substitution table shipped with the application
+func BundledSubstitutions() []models.SubstitutionRule {
+	rules, err := LoadSubstitutions(bytes.NewReader(bundledSubstitutions))
+	if err != nil {
+		panic(fmt.Sprintf("bundled substitutions: %v", err))
+	}
+	return rules
+}
+
+// LoadSubstitutions reads substitution rules from a JSON array. Each rule needs a "for" ingredient and
+// at least one "use" ingredient, written as free-text lines such as "1 cup buttermilk" or as objects;
+// "labels" must be known dietary labels.
+func LoadSubstitutions(r io.Reader) ([]models.SubstitutionRule, error) {
+	var rules []models.SubstitutionRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSubstitutions, err)
+	}
+
+	for i, rule := range rules {
+		if rule.For.Name == "" || len(rule.Use) == 0 {
+			return nil, fmt.Errorf("%w: rule %d needs a for and a use ingredient", ErrInvalidSubstitutions, i+1)
+		}
+		for _, label := range rule.Labels {
+			if !Contains(models.AllDietaryLabels, label) {
+				return nil, fmt.Errorf("%w: rule %d has unknown dietary label %q", ErrInvalidSubstitutions, i+1, label)
+			}
+		}
+		if rule.Labels == nil {
+			rules[i].Labels = make([]models.DietaryLabel, 0)
+		}
+	}
+	return rules, nil
+}
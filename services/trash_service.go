@@ -0,0 +1,68 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"playground/models"
+)
+
+// DefaultTrashRetention is how long deleted items stay in the trash before they are purged
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashService handles the trash bin of soft-deleted recipes and ratings
+type TrashService struct {
+	recipeService *RecipeService
+	ratingService *RatingService
+	retention     time.Duration
+}
+
+// NewTrashService creates a new trash service that keeps deleted items for the given retention window
+func NewTrashService(recipeService *RecipeService, ratingService *RatingService, retention time.Duration) *TrashService {
+	return &TrashService{
+		recipeService: recipeService,
+		ratingService: ratingService,
+		retention:     retention,
+	}
+}
+
+// GetTrash returns the recipes and ratings a user has in the trash
+func (s *TrashService) GetTrash(userID string) models.Trash {
+	return models.Trash{
+		Recipes: s.recipeService.GetDeletedRecipesByAuthor(userID),
+		Ratings: s.ratingService.GetDeletedRatingsByUserID(userID),
+	}
+}
+
+// Purge permanently removes every item that has been in the trash longer than the
+// retention window and returns how many recipes and ratings were removed
+func (s *TrashService) Purge(now time.Time) (recipes int, ratings int) {
+	cutoff := now.Add(-s.retention)
+	recipes = s.recipeService.PurgeDeletedRecipes(cutoff)
+	ratings = s.ratingService.PurgeDeletedRatings(cutoff)
+	return recipes, ratings
+}
+
+// StartPurger runs Purge in the background at the given interval until the returned
+// stop function is called
+func (s *TrashService) StartPurger(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				recipes, ratings := s.Purge(now)
+				if recipes > 0 || ratings > 0 {
+					log.Printf("Purged %d recipes and %d ratings from the trash", recipes, ratings)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
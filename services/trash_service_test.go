@@ -0,0 +1,123 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"playground/models"
+	"playground/repositories"
+)
+
+// TestTrashLifecycle tests soft delete and restore of recipes with their ratings
+func TestTrashLifecycle(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	ratingRepo := repositories.NewInMemoryRatingRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	ratingService := NewRatingService(ratingRepo, recipeService)
+	service := NewTrashService(recipeService, ratingService, time.Hour)
+
+	author := Caller{UserID: "author-1"}
+	recipe := createPublishedRecipe(t, recipeService, author.UserID, "Lasagna")
+	if _, err := ratingService.CreateRating(recipe.ID, "rater-1", models.RatingInput{Score: 5}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := ratingService.CreateRating(recipe.ID, "rater-2", models.RatingInput{Score: 3}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Deleting the recipe moves it and its ratings to the trash
+	if err := recipeService.DeleteRecipe(recipe.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := recipeService.GetRecipeByID(recipe.ID); err == nil {
+		t.Error("Expected deleted recipe to be hidden, but it was found")
+	}
+	if ratings := ratingService.GetRatingsByRecipeID(recipe.ID); len(ratings) != 0 {
+		t.Errorf("Expected ratings to be hidden with their recipe, but got %d", len(ratings))
+	}
+
+	trash := service.GetTrash(author.UserID)
+	if len(trash.Recipes) != 1 || trash.Recipes[0].ID != recipe.ID {
+		t.Fatalf("Expected the deleted recipe in the trash, but got %+v", trash.Recipes)
+	}
+
+	// Only the author can restore, and to others the trash is empty
+	if _, err := recipeService.RestoreRecipe(recipe.ID, Caller{UserID: "rater-1"}); err != ErrRecipeNotFound {
+		t.Errorf("Expected ErrRecipeNotFound, but got %v", err)
+	}
+
+	// Restoring brings the ratings back with the recipe
+	if _, err := recipeService.RestoreRecipe(recipe.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if ratings := ratingService.GetRatingsByRecipeID(recipe.ID); len(ratings) != 2 {
+		t.Errorf("Expected 2 restored ratings, but got %d", len(ratings))
+	}
+	if _, err := recipeService.RestoreRecipe(recipe.ID, author); err == nil {
+		t.Error("Expected restoring a live recipe to fail, but it succeeded")
+	}
+}
+
+// TestTrashRestoreAndPurge tests that cascaded ratings are restored and purged with their recipe
+func TestTrashRestoreAndPurge(t *testing.T) {
+	// Create the repositories
+	recipeRepo := repositories.NewInMemoryRecipeRepository()
+	ratingRepo := repositories.NewInMemoryRatingRepository()
+
+	// Create the services with the repositories
+	recipeService := NewRecipeService(recipeRepo)
+	ratingService := NewRatingService(ratingRepo, recipeService)
+	service := NewTrashService(recipeService, ratingService, time.Hour)
+
+	author := Caller{UserID: "author-1"}
+	recipe := createPublishedRecipe(t, recipeService, author.UserID, "Curry")
+	kept, _ := ratingService.CreateRating(recipe.ID, "rater-1", models.RatingInput{Score: 4})
+	removed, _ := ratingService.CreateRating(recipe.ID, "rater-2", models.RatingInput{Score: 2})
+
+	// A rating deleted on its own stays in the trash when the recipe comes back
+	if err := ratingService.DeleteRating(removed.ID, "rater-2"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	if err := recipeService.DeleteRecipe(recipe.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := recipeService.RestoreRecipe(recipe.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	ratings := ratingService.GetRatingsByRecipeID(recipe.ID)
+	if len(ratings) != 1 || ratings[0].ID != kept.ID {
+		t.Errorf("Expected only the cascaded rating to be restored, but got %+v", ratings)
+	}
+	if trash := service.GetTrash("rater-2"); len(trash.Ratings) != 1 {
+		t.Errorf("Expected the separately deleted rating to stay in the trash, but got %d", len(trash.Ratings))
+	}
+
+	// Nothing is purged inside the retention window
+	if err := recipeService.DeleteRecipe(recipe.ID, author); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if recipes, ratings := service.Purge(time.Now()); recipes != 0 || ratings != 0 {
+		t.Errorf("Expected nothing to be purged, but purged %d recipes and %d ratings", recipes, ratings)
+	}
+
+	// Everything is purged once the retention window has passed
+	recipes, _ := service.Purge(time.Now().Add(2 * time.Hour))
+	if recipes != 1 {
+		t.Errorf("Expected 1 recipe to be purged, but got %d", recipes)
+	}
+	if trash := service.GetTrash(author.UserID); len(trash.Recipes) != 0 {
+		t.Errorf("Expected the trash to be empty, but got %d recipes", len(trash.Recipes))
+	}
+	if remaining := ratingRepo.FindDeletedByUserID("rater-1"); len(remaining) != 0 {
+		t.Errorf("Expected the recipe's ratings to be purged, but got %d", len(remaining))
+	}
+	if _, err := recipeService.RestoreRecipe(recipe.ID, author); err == nil {
+		t.Error("Expected restoring a purged recipe to fail, but it succeeded")
+	}
+}